@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// gRPC-контракт лежит в proto/, сгенерированный код — в pkg/pb
+//go:generate protoc -I proto --go_out=../pkg/pb --go_opt=paths=source_relative --go-grpc_out=../pkg/pb --go-grpc_opt=paths=source_relative gophermart/v1/gophermart.proto
+
+// Spec — OpenAPI-описание всех маршрутов server.Mux. Меняется вместе с хендлерами.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// Load разбирает и проверяет встроенную спецификацию
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(Spec)
+	if err != nil {
+		return nil, err
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
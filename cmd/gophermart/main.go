@@ -1,33 +1,118 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/api"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/clients/accrual"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/config"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/grpcapi"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/accrualcallback"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminapproveadjustment"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admincreateadjustment"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admincreatecampaign"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admindeletecampaign"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetbalance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetcampaign"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetorders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetuser"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetwithdrawals"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminlistadjustments"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminlistaudit"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminlistcampaigns"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminlistusers"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminrejectadjustment"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminrepollorder"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminreversewithdrawal"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminsetrole"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminupdatecampaign"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminverifyaudit"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/canceldeletion"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/capturehold"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/confirmtotp"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createhold"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createorder"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createtransfer"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createwebhook"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createwithdraw"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/deleteaccount"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/deletewebhook"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/disabletotp"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/enrolltotp"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/exportdata"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/exportorders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/exportwithdrawals"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getadjustments"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getbalance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getmonthlystatement"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getopenapi"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getorders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getreferrals"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getstatement"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/gettierhistory"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/gettransfers"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getwebhookdeliveries"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getwebhooks"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getwithdrawals"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/healthz"
 	loginHandle "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/login"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/orderevents"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/readyz"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/redeliverwebhook"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/registration"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/voidhold"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/health"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
 	accrualPrc "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/processors/accrual"
+	auditPrc "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/processors/audit"
+	deletionsPrc "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/processors/deletions"
+	expiryPrc "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/processors/expiry"
+	holdsPrc "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/processors/holds"
+	tiersPrc "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/processors/tiers"
+	webhooksPrc "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/processors/webhooks"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/server"
+	adjustmentsSrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/adjustments"
+	auditSrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/audit"
 	balanceSrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	campaignsSrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/campaigns"
+	idempotencySrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/idempotency"
 	ordersSrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
+	privacySrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/privacy"
+	statementSrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/statement"
+	tiersSrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/tiers"
 	usersSrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	webhooksSrv "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/webhooks"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/adjustments"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/campaigns"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/idempotency"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/migrator"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/tiers"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/webhooks"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 )
 
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	cfg := config.NewConfig()
 	cfg.ParseFlag()
@@ -39,6 +124,20 @@ func main() {
 		return
 	}
 
+	//Tracing
+	shutdownTracing, err := tracing.Initialize(context.Background(), tracing.Config{
+		Exporter:     cfg.FlagTraceExporter,
+		OTLPEndpoint: cfg.FlagOTLPEndpoint,
+		File:         cfg.FlagTraceFile,
+	})
+	if err != nil {
+		logger.Log().Sugar().Errorw("Tracing can not be initialized: ", zap.Error(err))
+		panic(err)
+	}
+	defer func() {
+		_ = shutdownTracing(context.Background())
+	}()
+
 	//Migrator
 	db, err := sql.Open("pgx", cfg.FlagDB)
 	if err != nil {
@@ -64,43 +163,257 @@ func main() {
 		logger.Log().Sugar().Errorw("Cannot ping DB: ", zap.Error(err))
 		panic(err)
 	}
+	err = metrics.RegisterDB(db)
+	if err != nil {
+		logger.Log().Sugar().Errorw("Cannot register DB metrics: ", zap.Error(err))
+		panic(err)
+	}
 	//Storages
-	usersStore := users.New(db)
+	auditStore := audit.New(db)
+	usersStore := users.New(db, auditStore)
 	orderStore := orders.New(db)
-	balanceStore := balance.New(db)
+	webhooksStore := webhooks.New(db)
+	balanceStore := balance.New(db, auditStore, webhooksStore, models.ExpiryPolicy{
+		Months:      cfg.FlagPointsExpiryMonths,
+		Consumption: cfg.FlagPointsConsumption,
+	}, models.ReferralPolicy{
+		ReferrerBonus: cfg.FlagReferrerBonus,
+		RefereeBonus:  cfg.FlagRefereeBonus,
+		Cap:           cfg.FlagReferralCap,
+	})
+	adjustmentsStore := adjustments.New(db)
+	idempotencyStore := idempotency.New(db)
+	tiersStore := tiers.New(db)
+	campaignsStore := campaigns.New(db)
+	//Events
+	eventHub := events.NewHub(cfg.FlagEventHistory, cfg.FlagEventResumeWindow)
 	//Services
-	usersService := usersSrv.New(logger.Log(), usersStore)
+	auditService := auditSrv.New(logger.Log(), auditStore)
+	usersService := usersSrv.New(logger.Log(), usersStore, auditStore, cfg.FlagDeletionGrace, cfg.FlagAuditLoginKey)
 	ordersService := ordersSrv.New(logger.Log(), orderStore)
-	balanceService := balanceSrv.New(logger.Log(), balanceStore)
+	balanceService := balanceSrv.New(logger.Log(), balanceStore, eventHub, cfg.FlagTransferDailyLimit, cfg.FlagHoldTTL)
+	webhooksService := webhooksSrv.New(logger.Log(), webhooksStore)
+	campaignsService := campaignsSrv.New(logger.Log(), campaignsStore, orderStore)
+	tiersService := tiersSrv.New(logger.Log(), tiersStore, cfg.FlagTierWindowDays)
+	idempotencyService := idempotencySrv.New(logger.Log(), idempotencyStore, cfg.FlagIdempotencyTTL)
+	statementService := statementSrv.New(logger.Log(), balanceStore)
+	privacyService := privacySrv.New(logger.Log(), usersStore, orderStore, balanceStore)
+	adjustmentsService := adjustmentsSrv.New(logger.Log(), adjustmentsStore, balanceStore, eventHub, cfg.FlagAdjustmentThreshold)
 	//Clients
 	accrualClient := accrual.New(logger.Log(), cfg.FlagAccAddr)
 	//Processors
-	accrualProc := accrualPrc.New(logger.Log(), accrualClient, orderStore, balanceStore)
+	accrualProc := accrualPrc.New(logger.Log(), accrualClient, orderStore, balanceStore, campaignsService, eventHub)
+	expiryProc := expiryPrc.New(logger.Log(), balanceStore, eventHub)
+	holdsProc := holdsPrc.New(logger.Log(), balanceStore)
+	deletionsProc := deletionsPrc.New(logger.Log(), usersStore)
+	auditProc := auditPrc.New(logger.Log(), auditStore)
+	tiersProc := tiersPrc.New(logger.Log(), tiersStore, cfg.FlagTierWindowDays)
+	webhooksProc := webhooksPrc.New(logger.Log(), webhooksStore, cfg.FlagWebhookTimeout, cfg.FlagWebhookMaxAttempts)
+	//Health
+	healthChecker := health.NewChecker(db, migrator.New(db), cfg.FlagReadyMaxTickAge)
 	//Handlers
 	registrationHandler := registration.New(usersService)
 	loginHandler := loginHandle.New(usersService)
 	createOrderHandler := createorder.New(ordersService)
 	getOrdersHandler := getorders.New(ordersService)
-	getBalanceHandler := getbalance.New(balanceService)
-	createWithdrawHandler := createwithdraw.New(ordersService, balanceService)
+	getBalanceHandler := getbalance.New(balanceService, tiersService)
+	createWithdrawHandler := createwithdraw.New(ordersService, balanceService, usersService, cfg.FlagWithdrawTOTPThreshold)
 	getWithdrawalsHandler := getwithdrawals.New(balanceService)
+	enrollTOTPHandler := enrolltotp.New(usersService)
+	confirmTOTPHandler := confirmtotp.New(usersService)
+	disableTOTPHandler := disabletotp.New(usersService)
+	getAdjustmentsHandler := getadjustments.New(adjustmentsService)
+	adminListUsersHandler := adminlistusers.New(usersService)
+	adminGetUserHandler := admingetuser.New(usersService)
+	adminGetOrdersHandler := admingetorders.New(ordersService)
+	adminGetWithdrawalsHandler := admingetwithdrawals.New(balanceService)
+	adminGetBalanceHandler := admingetbalance.New(balanceService)
+	adminRepollOrderHandler := adminrepollorder.New(ordersService)
+	adminSetRoleHandler := adminsetrole.New(usersService)
+	adminCreateAdjustmentHandler := admincreateadjustment.New(adjustmentsService, usersService)
+	adminListAdjustmentsHandler := adminlistadjustments.New(adjustmentsService)
+	adminApproveAdjustmentHandler := adminapproveadjustment.New(adjustmentsService)
+	adminRejectAdjustmentHandler := adminrejectadjustment.New(adjustmentsService)
+	adminReverseWithdrawalHandler := adminreversewithdrawal.New(balanceService)
+	getStatementHandler := getstatement.New(statementService)
+	getMonthlyStatementHandler := getmonthlystatement.New(statementService)
+	exportOrdersHandler := exportorders.New(ordersService)
+	exportWithdrawalsHandler := exportwithdrawals.New(balanceService)
+	deleteAccountHandler := deleteaccount.New(usersService)
+	cancelDeletionHandler := canceldeletion.New(usersService)
+	exportDataHandler := exportdata.New(privacyService)
+	adminListAuditHandler := adminlistaudit.New(auditService)
+	adminVerifyAuditHandler := adminverifyaudit.New(auditService)
+	healthzHandler := healthz.New(healthChecker)
+	readyzHandler := readyz.New(healthChecker)
+	getOpenAPIHandler := getopenapi.New()
+	orderEventsHandler := orderevents.New(eventHub, cfg.FlagSSEHeartbeat)
+	createWebhookHandler := createwebhook.New(webhooksService)
+	getWebhooksHandler := getwebhooks.New(webhooksService)
+	deleteWebhookHandler := deletewebhook.New(webhooksService)
+	getWebhookDeliveriesHandler := getwebhookdeliveries.New(webhooksService)
+	redeliverWebhookHandler := redeliverwebhook.New(webhooksService)
+	getTierHistoryHandler := gettierhistory.New(tiersService)
+	getReferralsHandler := getreferrals.New(usersService)
+	createTransferHandler := createtransfer.New(balanceService, usersService, cfg.FlagTransferTOTPThreshold)
+	getTransfersHandler := gettransfers.New(balanceService)
+	createHoldHandler := createhold.New(balanceService, usersService, cfg.FlagWithdrawTOTPThreshold)
+	captureHoldHandler := capturehold.New(balanceService)
+	voidHoldHandler := voidhold.New(balanceService)
+	adminCreateCampaignHandler := admincreatecampaign.New(campaignsService)
+	adminListCampaignsHandler := adminlistcampaigns.New(campaignsService)
+	adminGetCampaignHandler := admingetcampaign.New(campaignsService)
+	adminUpdateCampaignHandler := adminupdatecampaign.New(campaignsService)
+	adminDeleteCampaignHandler := admindeletecampaign.New(campaignsService)
+	accrualCallbackHandler := accrualcallback.New(accrualProc)
+	//OpenAPI
+	spec, err := api.Load()
+	if err != nil {
+		logger.Log().Sugar().Errorw("Cannot load OpenAPI spec: ", zap.Error(err))
+		panic(err)
+	}
+	openAPIValidator, err := middlewares.OpenAPIMiddleware(spec)
+	if err != nil {
+		logger.Log().Sugar().Errorw("Cannot build OpenAPI validator: ", zap.Error(err))
+		panic(err)
+	}
 	//Server
-	srv := server.New(registrationHandler, loginHandler, createOrderHandler, getOrdersHandler, getBalanceHandler, createWithdrawHandler, getWithdrawalsHandler)
+	srv := server.New(auditService, idempotencyService, usersService, openAPIValidator, cfg.FlagAccrualSecret,
+		registrationHandler, loginHandler, createOrderHandler, getOrdersHandler, getBalanceHandler, createWithdrawHandler, getWithdrawalsHandler,
+		enrollTOTPHandler, confirmTOTPHandler, disableTOTPHandler, getAdjustmentsHandler,
+		adminListUsersHandler, adminGetUserHandler, adminGetOrdersHandler, adminGetWithdrawalsHandler, adminGetBalanceHandler, adminRepollOrderHandler, adminSetRoleHandler,
+		adminCreateAdjustmentHandler, adminListAdjustmentsHandler, adminApproveAdjustmentHandler, adminRejectAdjustmentHandler,
+		adminListAuditHandler, adminVerifyAuditHandler,
+		healthzHandler, readyzHandler, getOpenAPIHandler, orderEventsHandler,
+		createWebhookHandler, getWebhooksHandler, deleteWebhookHandler, getWebhookDeliveriesHandler, redeliverWebhookHandler,
+		accrualCallbackHandler, getTierHistoryHandler,
+		adminCreateCampaignHandler, adminListCampaignsHandler, adminGetCampaignHandler, adminUpdateCampaignHandler, adminDeleteCampaignHandler,
+		getReferralsHandler, createTransferHandler, getTransfersHandler,
+		createHoldHandler, captureHoldHandler, voidHoldHandler, adminReverseWithdrawalHandler,
+		getStatementHandler, getMonthlyStatementHandler, exportOrdersHandler, exportWithdrawalsHandler,
+		deleteAccountHandler, cancelDeletionHandler, exportDataHandler)
+
+	// в push-режиме опрос остаётся сверкой на случай потерянных колбэков и идёт реже
+	pollInterval := cfg.FlagAccrualPoll
+	if cfg.FlagAccrualSecret != "" {
+		pollInterval = cfg.FlagAccrualReconcile
+	}
+	// фоновые циклы останавливаются по сигналу; main дожидается их завершения перед выходом
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var background sync.WaitGroup
+	every := func(interval time.Duration, fn func()) {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			runEvery(ctx, interval, fn)
+		}()
+	}
 
+	every(pollInterval, accrualProc.Do)
+	every(time.Second, webhooksProc.Do)
+	every(time.Second, auditProc.Do)
+	every(time.Minute, expiryProc.Do)
+	every(time.Minute, holdsProc.Do)
+	every(time.Minute, deletionsProc.Do)
+	every(time.Minute, eventHub.Prune)
+	every(time.Minute, func() {
+		err := idempotencyService.DeleteExpired(context.Background())
+		if err != nil {
+			logger.Log().Sugar().Errorw("Cannot delete expired idempotency keys", zap.Error(err))
+		}
+	})
+
+	background.Add(1)
 	go func() {
-		ticker := time.NewTicker(500 * time.Millisecond)
-		for range ticker.C {
-			accrualProc.Do()
+		defer background.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Until(tiersPrc.NextRun(time.Now(), cfg.FlagTierRecalcHour))):
+				tiersProc.Do()
+			}
 		}
 	}()
 
-	logger.Log().Sugar().Debugw("Starting server", "address", cfg.FlagRunAddr)
+	adminServer := &http.Server{Addr: cfg.FlagAdminAddr, Handler: srv.AdminMux()}
+	go func() {
+		logger.Log().Sugar().Debugw("Starting admin server", "address", cfg.FlagAdminAddr)
+		err := adminServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Log().Sugar().Errorw("Admin server crashed with error: ", zap.Error(err))
+		}
+	}()
+
+	//gRPC
+	var grpcServer *grpc.Server
+	if cfg.FlagGRPCAddr != "" {
+		listener, err := net.Listen("tcp", cfg.FlagGRPCAddr)
+		if err != nil {
+			logger.Log().Sugar().Errorw("Cannot listen gRPC address: ", zap.Error(err))
+			panic(err)
+		}
+		grpcServer = grpcapi.NewGRPCServer(grpcapi.New(usersService, ordersService, balanceService, cfg.FlagWithdrawTOTPThreshold))
+		go func() {
+			logger.Log().Sugar().Debugw("Starting gRPC server", "address", cfg.FlagGRPCAddr)
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Log().Sugar().Errorw("gRPC server crashed with error: ", zap.Error(err))
+			}
+		}()
+	}
 
 	//Server
-	err = http.ListenAndServe(cfg.FlagRunAddr, srv.Mux())
-	if err != nil {
+	httpServer := &http.Server{Addr: cfg.FlagRunAddr, Handler: srv.Mux()}
+	httpServer.RegisterOnShutdown(eventHub.Close)
+	// done закрывается, когда все серверы остановлены: до этого main не выходит,
+	// иначе процесс завершится, пока Shutdown ещё дожидается текущих запросов
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+
+		// сначала гасим готовность и даём балансировщику время это заметить, потом дожидаемся текущих запросов
+		logger.Log().Sugar().Infow("Shutting down", "delay", cfg.FlagShutdownDelay)
+		health.SetShuttingDown()
+		time.Sleep(cfg.FlagShutdownDelay)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Log().Sugar().Errorw("Server shutdown error: ", zap.Error(err))
+		}
+		if err := adminServer.Shutdown(shutdownCtx); err != nil {
+			logger.Log().Sugar().Errorw("Admin server shutdown error: ", zap.Error(err))
+		}
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
+	}()
+
+	logger.Log().Sugar().Debugw("Starting server", "address", cfg.FlagRunAddr)
+
+	err = httpServer.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Log().Sugar().Errorw("Server crashed with error: ", zap.Error(err))
 		panic(err)
 	}
 
+	<-done
+	background.Wait()
+	logger.Log().Sugar().Infow("Server stopped")
+}
+
+// runEvery вызывает fn раз в interval, пока ctx не отменён. Начатый вызов не прерывается
+func runEvery(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
 }
@@ -0,0 +1,12 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_secret    VARCHAR,
+    ADD COLUMN IF NOT EXISTS totp_enabled   BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+
+CREATE TABLE IF NOT EXISTS recovery_codes
+(
+    id        BIGSERIAL PRIMARY KEY,
+    user_id   bigint references users (id),
+    code_hash VARCHAR NOT NULL,
+    used_at   TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS role;
+DROP TYPE IF EXISTS role_type;
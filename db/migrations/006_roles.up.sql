@@ -0,0 +1,5 @@
+DROP TYPE IF EXISTS role_type;
+CREATE TYPE role_type AS ENUM ('user', 'support', 'admin');
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role role_type NOT NULL DEFAULT 'user';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS adjustments;
+DROP TYPE IF EXISTS adjustment_type;
+DROP TYPE IF EXISTS adjustment_status;
@@ -0,0 +1,19 @@
+DROP TYPE IF EXISTS adjustment_type;
+DROP TYPE IF EXISTS adjustment_status;
+CREATE TYPE adjustment_type AS ENUM ('CREDIT', 'DEBIT');
+CREATE TYPE adjustment_status AS ENUM ('PENDING', 'APPLIED', 'REJECTED');
+
+CREATE TABLE IF NOT EXISTS adjustments
+(
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     bigint references users (id),
+    type        adjustment_type   NOT NULL,
+    amount      NUMERIC           NOT NULL,
+    reason      VARCHAR           NOT NULL,
+    note        TEXT              NOT NULL DEFAULT '',
+    status      adjustment_status NOT NULL DEFAULT 'PENDING',
+    created_by  bigint references users (id),
+    decided_by  bigint references users (id),
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    decided_at  TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    id         BIGSERIAL PRIMARY KEY,
+    type       VARCHAR                  NOT NULL,
+    actor_id   bigint,
+    user_id    bigint,
+    payload    JSON                     NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    prev_hash  VARCHAR                  NOT NULL,
+    hash       VARCHAR                  NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_user_id_idx ON audit_events (user_id);
+CREATE INDEX IF NOT EXISTS audit_events_type_created_at_idx ON audit_events (type, created_at);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE
+    ON audit_events
+    FOR EACH ROW
+EXECUTE FUNCTION audit_events_append_only();
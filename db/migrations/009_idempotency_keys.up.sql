@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    user_id      bigint references users (id),
+    key          VARCHAR(255)             NOT NULL,
+    fingerprint  VARCHAR                  NOT NULL,
+    status_code  INTEGER,
+    content_type VARCHAR,
+    body         BYTEA,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, key)
+);
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    bigint references users (id),
+    url        VARCHAR                  NOT NULL,
+    secret     VARCHAR                  NOT NULL,
+    events     VARCHAR                  NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_user_id_idx ON webhooks (user_id);
+
+DROP TYPE IF EXISTS delivery_status;
+CREATE TYPE delivery_status AS ENUM ('PENDING', 'DELIVERED', 'FAILED');
+
+-- outbox: строка пишется в транзакции бизнес-изменения, отправляет её фоновый диспетчер
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id               BIGSERIAL PRIMARY KEY,
+    webhook_id       bigint references webhooks (id) ON DELETE CASCADE,
+    event_id         VARCHAR                  NOT NULL,
+    event_type       VARCHAR                  NOT NULL,
+    payload          BYTEA                    NOT NULL,
+    status           delivery_status          NOT NULL DEFAULT 'PENDING',
+    attempts         INTEGER                  NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER,
+    last_error       TEXT,
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at     TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS point_lots;
+DROP TYPE IF EXISTS lot_source;
@@ -0,0 +1,26 @@
+DROP TYPE IF EXISTS lot_source;
+CREATE TYPE lot_source AS ENUM ('ACCRUAL', 'ADJUSTMENT', 'LEGACY');
+
+CREATE TABLE IF NOT EXISTS point_lots
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    bigint references users (id),
+    source     lot_source               NOT NULL,
+    source_id  VARCHAR                  NOT NULL DEFAULT '',
+    amount     NUMERIC                  NOT NULL,
+    remaining  NUMERIC                  NOT NULL,
+    expired    NUMERIC                  NOT NULL DEFAULT 0,
+    accrued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    expired_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS point_lots_user_id_idx ON point_lots (user_id, accrued_at) WHERE remaining > 0;
+CREATE INDEX IF NOT EXISTS point_lots_expires_at_idx ON point_lots (expires_at) WHERE remaining > 0;
+
+-- остатки, накопленные до появления партий, переносим одной бессрочной партией на пользователя
+INSERT INTO point_lots (user_id, source, amount, remaining)
+SELECT user_id, 'LEGACY', sum, sum
+FROM balances
+WHERE sum > 0
+  AND NOT EXISTS (SELECT 1 FROM point_lots);
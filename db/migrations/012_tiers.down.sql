@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tier_history;
+DROP TABLE IF EXISTS user_tiers;
+DROP TABLE IF EXISTS tiers;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS tiers
+(
+    name       VARCHAR PRIMARY KEY,
+    threshold  NUMERIC NOT NULL UNIQUE,
+    multiplier NUMERIC NOT NULL
+);
+
+-- уровень с нулевым порогом обязателен: он достаётся всем, кто ещё не набрал на Silver
+INSERT INTO tiers (name, threshold, multiplier)
+VALUES ('BASE', 0, 1),
+       ('SILVER', 1000, 1.05),
+       ('GOLD', 5000, 1.1),
+       ('PLATINUM', 20000, 1.2)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS user_tiers
+(
+    user_id    bigint references users (id) PRIMARY KEY,
+    tier       VARCHAR references tiers (name) NOT NULL,
+    spend      NUMERIC                         NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE        NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS tier_history
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    bigint references users (id),
+    from_tier  VARCHAR                  NOT NULL,
+    to_tier    VARCHAR                  NOT NULL,
+    spend      NUMERIC                  NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS tier_history_user_id_idx ON tier_history (user_id, changed_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS campaign_bonuses;
+DROP TABLE IF EXISTS campaigns;
+DROP TYPE IF EXISTS campaign_kind;
@@ -0,0 +1,35 @@
+DROP TYPE IF EXISTS campaign_kind;
+CREATE TYPE campaign_kind AS ENUM ('MULTIPLIER', 'FIXED', 'FIRST_ORDER');
+
+CREATE TABLE IF NOT EXISTS campaigns
+(
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR                  NOT NULL,
+    kind       campaign_kind            NOT NULL,
+    value      NUMERIC                  NOT NULL,
+    starts_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at    TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by bigint references users (id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS campaigns_active_idx ON campaigns (starts_at, ends_at) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS campaign_bonuses
+(
+    id           BIGSERIAL PRIMARY KEY,
+    campaign_id  bigint references campaigns (id),
+    kind         campaign_kind            NOT NULL,
+    user_id      bigint references users (id),
+    order_number VARCHAR references orders (number),
+    amount       NUMERIC                  NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (campaign_id, order_number)
+);
+
+-- бонус за первый заказ — один на пользователя, даже если два заказа начислились одновременно
+CREATE UNIQUE INDEX IF NOT EXISTS campaign_bonuses_first_order_idx ON campaign_bonuses (campaign_id, user_id) WHERE kind = 'FIRST_ORDER';
+CREATE INDEX IF NOT EXISTS campaign_bonuses_user_id_idx ON campaign_bonuses (user_id);
+
+ALTER TYPE lot_source ADD VALUE IF NOT EXISTS 'CAMPAIGN';
@@ -0,0 +1,26 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS referral_code VARCHAR UNIQUE;
+UPDATE users SET referral_code = upper(substr(md5(random()::text || id::text), 1, 8)) WHERE referral_code IS NULL;
+ALTER TABLE users ALTER COLUMN referral_code SET NOT NULL;
+
+DROP TYPE IF EXISTS referral_status;
+CREATE TYPE referral_status AS ENUM ('PENDING', 'REWARDED', 'REJECTED');
+
+CREATE TABLE IF NOT EXISTS referrals
+(
+    id              BIGSERIAL PRIMARY KEY,
+    referrer_id     bigint references users (id) NOT NULL,
+    -- пригласить пользователя можно только один раз
+    referee_id      bigint references users (id) NOT NULL UNIQUE,
+    status          referral_status          NOT NULL DEFAULT 'PENDING',
+    reason          VARCHAR,
+    order_number    VARCHAR references orders (number),
+    referrer_reward NUMERIC                  NOT NULL DEFAULT 0,
+    referee_reward  NUMERIC                  NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    rewarded_at     TIMESTAMP WITH TIME ZONE,
+    CHECK (referrer_id <> referee_id)
+);
+
+CREATE INDEX IF NOT EXISTS referrals_referrer_id_idx ON referrals (referrer_id, created_at);
+
+ALTER TYPE lot_source ADD VALUE IF NOT EXISTS 'REFERRAL';
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS transfers
+(
+    id           BIGSERIAL PRIMARY KEY,
+    from_user_id bigint references users (id) NOT NULL,
+    to_user_id   bigint references users (id) NOT NULL,
+    sum          NUMERIC                  NOT NULL CHECK (sum > 0),
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_user_id <> to_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS transfers_from_user_id_idx ON transfers (from_user_id, created_at);
+CREATE INDEX IF NOT EXISTS transfers_to_user_id_idx ON transfers (to_user_id, created_at);
+
+ALTER TYPE lot_source ADD VALUE IF NOT EXISTS 'TRANSFER';
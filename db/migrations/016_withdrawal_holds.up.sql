@@ -0,0 +1,19 @@
+DROP TYPE IF EXISTS hold_status;
+CREATE TYPE hold_status AS ENUM ('HELD', 'CAPTURED', 'VOIDED', 'EXPIRED');
+
+CREATE TABLE IF NOT EXISTS withdrawal_holds
+(
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       bigint references users (id) NOT NULL,
+    number        VARCHAR                  NOT NULL,
+    sum           NUMERIC                  NOT NULL CHECK (sum > 0),
+    status        hold_status              NOT NULL DEFAULT 'HELD',
+    withdrawal_id bigint references withdrawals (id),
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at    TIMESTAMP WITH TIME ZONE NOT NULL,
+    resolved_at   TIMESTAMP WITH TIME ZONE
+);
+
+-- по одному заказу у пользователя может висеть только одна активная блокировка
+CREATE UNIQUE INDEX IF NOT EXISTS withdrawal_holds_active_idx ON withdrawal_holds (user_id, number) WHERE status = 'HELD';
+CREATE INDEX IF NOT EXISTS withdrawal_holds_expires_at_idx ON withdrawal_holds (expires_at) WHERE status = 'HELD';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS withdrawal_reversals;
+ALTER TABLE withdrawals DROP CONSTRAINT IF EXISTS withdrawals_reversed_check;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS reversed;
@@ -0,0 +1,18 @@
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS reversed NUMERIC NOT NULL DEFAULT 0;
+-- вернуть больше, чем списано, нельзя даже при ошибке в коде
+ALTER TABLE withdrawals ADD CONSTRAINT withdrawals_reversed_check CHECK (reversed >= 0 AND reversed <= sum);
+
+CREATE TABLE IF NOT EXISTS withdrawal_reversals
+(
+    id            BIGSERIAL PRIMARY KEY,
+    withdrawal_id bigint references withdrawals (id) NOT NULL,
+    user_id       bigint references users (id)       NOT NULL,
+    sum           NUMERIC                  NOT NULL CHECK (sum > 0),
+    reason        VARCHAR                  NOT NULL,
+    created_by    bigint references users (id),
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS withdrawal_reversals_withdrawal_id_idx ON withdrawal_reversals (withdrawal_id);
+
+ALTER TYPE lot_source ADD VALUE IF NOT EXISTS 'REVERSAL';
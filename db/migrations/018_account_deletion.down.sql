@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS users_deletion_due_idx;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS sessions_revoked_at,
+    DROP COLUMN IF EXISTS deletion_requested_at,
+    DROP COLUMN IF EXISTS deletion_scheduled_at,
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS sessions_revoked_at   TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS deleted_at            TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS users_deletion_due_idx ON users (deletion_scheduled_at) WHERE deleted_at IS NULL;
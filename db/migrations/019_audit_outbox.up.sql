@@ -0,0 +1,10 @@
+-- события пишутся сюда без блокировок в транзакциях бизнес-операций, в цепочку audit_events их переносит один воркер
+CREATE TABLE IF NOT EXISTS audit_outbox
+(
+    id         BIGSERIAL PRIMARY KEY,
+    type       VARCHAR                  NOT NULL,
+    actor_id   bigint,
+    user_id    bigint,
+    payload    JSON                     NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS second_factor_failures,
+    DROP COLUMN IF EXISTS second_factor_locked_until;
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS second_factor_failures     INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS second_factor_locked_until TIMESTAMP WITH TIME ZONE;
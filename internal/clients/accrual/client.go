@@ -1,15 +1,27 @@
 package accrual
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/health"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
+	"strconv"
 )
 
+const requestIDHeader = "X-Request-ID"
+
 type accrual struct {
 	log  *zap.Logger
 	addr string
@@ -19,48 +31,67 @@ func New(log *zap.Logger, addr string) Client {
 	return &accrual{log, addr}
 }
 
-func (a accrual) SendOrder(orderID string) (*dto.AccrualOrderResponse, error) {
+func (a accrual) SendOrder(ctx context.Context, orderID string) (*dto.AccrualOrderResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "accrual.SendOrder",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("order.number", orderID)))
+	defer span.End()
+
 	order := &dto.AccrualOrderResponse{}
 	requestURL := fmt.Sprintf("%s/api/orders/%s", a.addr, orderID)
-	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
 	if err != nil {
-		logger.Log().Sugar().Errorw("New request error", zap.Error(err))
+		logger.FromContext(ctx).Sugar().Errorw("New request error", zap.Error(err))
 		return order, err
 	}
+	// пробрасываем W3C traceparent в accrual
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if requestID := logger.RequestID(ctx); requestID != "" {
+		req.Header.Set(requestIDHeader, requestID)
+	}
 
 	response, err := http.DefaultClient.Do(req)
+	health.MarkAccrual(err)
 	if err != nil {
-		logger.Log().Sugar().Errorw("Do request error", zap.Error(err))
+		metrics.AccrualRequests.WithLabelValues("error").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.FromContext(ctx).Sugar().Errorw("Do request error", zap.Error(err))
 		return order, err
 	}
+	metrics.AccrualRequests.WithLabelValues(strconv.Itoa(response.StatusCode)).Inc()
+	span.SetAttributes(attribute.Int("http.status_code", response.StatusCode))
+	if response.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, response.Status)
+	}
 
 	if response.StatusCode == http.StatusOK {
 		resBody, err := io.ReadAll(response.Body)
 		defer response.Body.Close()
 		if err != nil {
-			logger.Log().Sugar().Errorw("Read body error", zap.Error(err))
+			logger.FromContext(ctx).Sugar().Errorw("Read body error", zap.Error(err))
 			return order, err
 		}
 		err = json.Unmarshal(resBody, &order)
 		if err != nil {
-			logger.Log().Sugar().Errorw("Unmarshal body error", zap.Error(err))
+			logger.FromContext(ctx).Sugar().Errorw("Unmarshal body error", zap.Error(err))
 			return order, err
 		}
 		return order, nil
 	}
 
 	if response.StatusCode == http.StatusInternalServerError {
-		logger.Log().Sugar().Errorw("Internal Server Error", zap.Error(ErrAccrualServerError))
+		logger.FromContext(ctx).Sugar().Errorw("Internal Server Error", zap.Error(ErrAccrualServerError))
 		return order, ErrAccrualServerError
 	}
 
 	if response.StatusCode == http.StatusTooManyRequests {
-		logger.Log().Sugar().Errorw("Too Many Requests", zap.Error(ErrAccrualTooManyRequests))
+		logger.FromContext(ctx).Sugar().Errorw("Too Many Requests", zap.Error(ErrAccrualTooManyRequests))
 		return order, ErrAccrualTooManyRequests
 	}
 
 	if response.StatusCode == http.StatusNoContent {
-		logger.Log().Sugar().Errorw("No contend", zap.Error(ErrAccrualNoData))
+		logger.FromContext(ctx).Sugar().Errorw("No contend", zap.Error(ErrAccrualNoData))
 		return order, ErrAccrualNoData
 	}
 
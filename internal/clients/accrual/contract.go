@@ -1,6 +1,7 @@
 package accrual
 
 import (
+	"context"
 	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
 )
@@ -14,5 +15,5 @@ var (
 )
 
 type Client interface {
-	SendOrder(orderID string) (*dto.AccrualOrderResponse, error)
+	SendOrder(ctx context.Context, orderID string) (*dto.AccrualOrderResponse, error)
 }
@@ -5,6 +5,7 @@
 package accrual
 
 import (
+	context "context"
 	reflect "reflect"
 
 	dto "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
@@ -35,16 +36,16 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 }
 
 // SendOrder mocks base method.
-func (m *MockClient) SendOrder(orderID string) (*dto.AccrualOrderResponse, error) {
+func (m *MockClient) SendOrder(ctx context.Context, orderID string) (*dto.AccrualOrderResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendOrder", orderID)
+	ret := m.ctrl.Call(m, "SendOrder", ctx, orderID)
 	ret0, _ := ret[0].(*dto.AccrualOrderResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SendOrder indicates an expected call of SendOrder.
-func (mr *MockClientMockRecorder) SendOrder(orderID interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) SendOrder(ctx, orderID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOrder", reflect.TypeOf((*MockClient)(nil).SendOrder), orderID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOrder", reflect.TypeOf((*MockClient)(nil).SendOrder), ctx, orderID)
 }
@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+type AccountDeletionResponse struct {
+	RequestedAt time.Time `json:"requested_at"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+type ProfileResponse struct {
+	ID           string                   `json:"id"`
+	Login        string                   `json:"login"`
+	Role         string                   `json:"role"`
+	TOTPEnabled  bool                     `json:"totp_enabled"`
+	ReferralCode string                   `json:"referral_code"`
+	Deletion     *AccountDeletionResponse `json:"deletion,omitempty"`
+}
+
+// DataExportResponse — выгрузка персональных данных; в ZIP она лежит как export.json рядом с CSV
+type DataExportResponse struct {
+	GeneratedAt time.Time                `json:"generated_at"`
+	Profile     ProfileResponse          `json:"profile"`
+	Orders      []GetOrdersResponse      `json:"orders"`
+	Withdrawals []WithdrawalsResponse    `json:"withdrawals"`
+	Statement   []StatementEntryResponse `json:"statement"`
+}
+
+func NewAccountDeletionResponse(deletion models.AccountDeletion) AccountDeletionResponse {
+	return AccountDeletionResponse{
+		RequestedAt: deletion.RequestedAt.Truncate(time.Second),
+		ScheduledAt: deletion.ScheduledAt.Truncate(time.Second),
+	}
+}
+
+func NewDataExportResponse(data models.DataExport) DataExportResponse {
+	resp := DataExportResponse{
+		GeneratedAt: data.GeneratedAt.Truncate(time.Second),
+		Profile: ProfileResponse{
+			ID:           data.Profile.UserID,
+			Login:        data.Profile.Login,
+			Role:         data.Profile.Role,
+			TOTPEnabled:  data.Profile.TOTPEnabled,
+			ReferralCode: data.Profile.ReferralCode,
+		},
+		Orders:      make([]GetOrdersResponse, 0, len(data.Orders)),
+		Withdrawals: make([]WithdrawalsResponse, 0, len(data.Withdrawals)),
+		Statement:   make([]StatementEntryResponse, 0, len(data.Statement)),
+	}
+	if data.Profile.Deletion != nil {
+		deletion := NewAccountDeletionResponse(*data.Profile.Deletion)
+		resp.Profile.Deletion = &deletion
+	}
+	for _, order := range data.Orders {
+		resp.Orders = append(resp.Orders, GetOrdersResponse{
+			Number:     order.Number,
+			Status:     order.Status,
+			Accrual:    order.Accrual,
+			UploadedAt: order.UploadedAt.Truncate(time.Second),
+		})
+	}
+	for _, withdrawal := range data.Withdrawals {
+		resp.Withdrawals = append(resp.Withdrawals, WithdrawalsResponse{
+			ID:          withdrawal.ID,
+			OrderNumber: withdrawal.OrderNumber,
+			Sum:         withdrawal.Sum,
+			Reversed:    withdrawal.Reversed,
+			Status:      withdrawal.Status(),
+			ProcessedAt: withdrawal.ProcessedAt.Truncate(time.Second),
+		})
+	}
+	for _, entry := range data.Statement {
+		resp.Statement = append(resp.Statement, NewStatementEntryResponse(entry))
+	}
+	return resp
+}
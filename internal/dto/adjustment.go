@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+type CreateAdjustmentRequest struct {
+	Type   string  `json:"type"`
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+	Note   string  `json:"note"`
+}
+
+type AdjustmentResponse struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Type      string     `json:"type"`
+	Amount    float64    `json:"amount"`
+	Reason    string     `json:"reason"`
+	Note      string     `json:"note"`
+	Status    string     `json:"status"`
+	CreatedBy string     `json:"created_by"`
+	DecidedBy string     `json:"decided_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+type UserAdjustmentResponse struct {
+	Type        string    `json:"type"`
+	Amount      float64   `json:"amount"`
+	Reason      string    `json:"reason"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+func NewAdjustmentResponse(adjustment models.Adjustment) AdjustmentResponse {
+	resp := AdjustmentResponse{
+		ID:        adjustment.ID,
+		UserID:    adjustment.UserID,
+		Type:      adjustment.Type,
+		Amount:    adjustment.Amount,
+		Reason:    adjustment.Reason,
+		Note:      adjustment.Note,
+		Status:    adjustment.Status,
+		CreatedBy: adjustment.CreatedBy,
+		DecidedBy: adjustment.DecidedBy,
+		CreatedAt: adjustment.CreatedAt,
+	}
+	if !adjustment.DecidedAt.IsZero() {
+		decidedAt := adjustment.DecidedAt
+		resp.DecidedAt = &decidedAt
+	}
+	return resp
+}
@@ -0,0 +1,12 @@
+package dto
+
+type AdminUserResponse struct {
+	ID          string `json:"id"`
+	Login       string `json:"login"`
+	Role        string `json:"role"`
+	TOTPEnabled bool   `json:"totp_enabled"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type AuditEventResponse struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	ActorID   string            `json:"actor_id,omitempty"`
+	UserID    string            `json:"user_id,omitempty"`
+	Payload   map[string]string `json:"payload"`
+	CreatedAt time.Time         `json:"created_at"`
+	PrevHash  string            `json:"prev_hash"`
+	Hash      string            `json:"hash"`
+}
+
+type AuditVerificationResponse struct {
+	Checked  int    `json:"checked"`
+	Valid    bool   `json:"valid"`
+	BrokenAt string `json:"broken_at,omitempty"`
+}
@@ -1,6 +1,56 @@
 package dto
 
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"math"
+	"time"
+)
+
+// GetBalanceResponse — Held: баллы под активными блокировками, Available: сколько из Current можно потратить
 type GetBalanceResponse struct {
-	Current   float64 `json:"current"`
-	Withdrawn float64 `json:"withdrawn"`
+	Current   float64           `json:"current"`
+	Held      float64           `json:"held"`
+	Available float64           `json:"available"`
+	Withdrawn float64           `json:"withdrawn"`
+	Expiring  *ExpiringResponse `json:"expiring,omitempty"`
+	Tier      *TierResponse     `json:"tier,omitempty"`
+}
+
+// ExpiringResponse — ближайшее сгорание баллов
+type ExpiringResponse struct {
+	Sum       float64   `json:"sum"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TierResponse — уровень лояльности и прогресс до следующего
+type TierResponse struct {
+	Name       string            `json:"name"`
+	Multiplier float64           `json:"multiplier"`
+	Spend      float64           `json:"spend"`
+	Next       *NextTierResponse `json:"next,omitempty"`
+}
+
+type NextTierResponse struct {
+	Name      string  `json:"name"`
+	Threshold float64 `json:"threshold"`
+	Remaining float64 `json:"remaining"`
+}
+
+type TierChangeResponse struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Spend     float64   `json:"spend"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func NewTierResponse(status models.TierStatus) *TierResponse {
+	resp := &TierResponse{Name: status.Tier.Name, Multiplier: status.Tier.Multiplier, Spend: status.Spend}
+	if status.Next != nil {
+		resp.Next = &NextTierResponse{
+			Name:      status.Next.Name,
+			Threshold: status.Next.Threshold,
+			Remaining: math.Max(status.Next.Threshold-status.Spend, 0),
+		}
+	}
+	return resp
 }
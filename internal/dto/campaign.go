@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+type CampaignRequest struct {
+	Name     string    `json:"name"`
+	Kind     string    `json:"kind"`
+	Value    float64   `json:"value"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type CampaignResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Value     float64   `json:"value"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Spent     float64   `json:"spent"`
+	Bonuses   int       `json:"bonuses"`
+}
+
+func (r CampaignRequest) ToModel() models.Campaign {
+	return models.Campaign{Name: r.Name, Kind: r.Kind, Value: r.Value, StartsAt: r.StartsAt, EndsAt: r.EndsAt}
+}
+
+func NewCampaignResponse(campaign models.Campaign) CampaignResponse {
+	return CampaignResponse{
+		ID:        campaign.ID,
+		Name:      campaign.Name,
+		Kind:      campaign.Kind,
+		Value:     campaign.Value,
+		StartsAt:  campaign.StartsAt,
+		EndsAt:    campaign.EndsAt,
+		CreatedBy: campaign.CreatedBy,
+		CreatedAt: campaign.CreatedAt,
+		Spent:     campaign.Spent,
+		Bonuses:   campaign.Bonuses,
+	}
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+
+type HealthCheckResponse struct {
+	Status  string         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type HealthResponse struct {
+	Status string                         `json:"status"`
+	Checks map[string]HealthCheckResponse `json:"checks"`
+}
+
+func NewHealthResponse(report models.HealthReport) HealthResponse {
+	resp := HealthResponse{Status: report.Status, Checks: make(map[string]HealthCheckResponse, len(report.Checks))}
+	for name, check := range report.Checks {
+		resp.Checks[name] = HealthCheckResponse{Status: check.Status, Error: check.Error, Details: check.Details}
+	}
+	return resp
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+type HoldRequest struct {
+	Number string  `json:"order"`
+	Sum    float64 `json:"sum"`
+	Code   string  `json:"code,omitempty"`
+}
+
+type HoldResponse struct {
+	ID         string     `json:"id"`
+	Number     string     `json:"order"`
+	Sum        float64    `json:"sum"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+func NewHoldResponse(hold models.Hold) HoldResponse {
+	return HoldResponse{
+		ID:         hold.ID,
+		Number:     hold.Number,
+		Sum:        hold.Sum,
+		Status:     hold.Status,
+		CreatedAt:  hold.CreatedAt,
+		ExpiresAt:  hold.ExpiresAt,
+		ResolvedAt: hold.ResolvedAt,
+	}
+}
@@ -3,4 +3,5 @@ package dto
 type LoginUserRequest struct {
 	Login    string `json:"login" `
 	Password string `json:"password"`
+	Code     string `json:"code,omitempty"`
 }
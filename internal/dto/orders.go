@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"strconv"
 	"time"
 )
 
@@ -10,3 +12,15 @@ type GetOrdersResponse struct {
 	Accrual    float64   `json:"accrual,omitempty"`
 	UploadedAt time.Time `json:"uploaded_at"`
 }
+
+// OrderCSVHeader и OrderCSVRecord — строки CSV-выгрузки заказов; колонки повторяют поля GetOrdersResponse
+var OrderCSVHeader = []string{"number", "status", "accrual", "uploaded_at"}
+
+func OrderCSVRecord(order models.Order) []string {
+	return []string{
+		order.Number,
+		order.Status,
+		strconv.FormatFloat(order.Accrual, 'f', -1, 64),
+		order.UploadedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+type ReferralsResponse struct {
+	Code      string             `json:"code"`
+	Invited   int                `json:"invited"`
+	Earned    float64            `json:"earned"`
+	Referrals []ReferralResponse `json:"referrals"`
+}
+
+type ReferralResponse struct {
+	Login      string     `json:"login"`
+	Status     string     `json:"status"`
+	Reason     string     `json:"reason,omitempty"`
+	Reward     float64    `json:"reward"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RewardedAt *time.Time `json:"rewarded_at,omitempty"`
+}
+
+func NewReferralsResponse(program models.ReferralProgram) ReferralsResponse {
+	resp := ReferralsResponse{
+		Code:      program.Code,
+		Invited:   len(program.Referrals),
+		Earned:    program.Earned(),
+		Referrals: []ReferralResponse{},
+	}
+	for _, referral := range program.Referrals {
+		resp.Referrals = append(resp.Referrals, ReferralResponse{
+			Login:      maskLogin(referral.RefereeLogin),
+			Status:     referral.Status,
+			Reason:     referral.Reason,
+			Reward:     referral.ReferrerReward,
+			CreatedAt:  referral.CreatedAt,
+			RewardedAt: referral.RewardedAt,
+		})
+	}
+	return resp
+}
+
+// maskLogin оставляет от логина приглашённого первые два символа — чужой логин целиком не показываем
+func maskLogin(login string) string {
+	runes := []rune(login)
+	if len(runes) > 2 {
+		runes = runes[:2]
+	}
+	return string(runes) + "***"
+}
@@ -3,4 +3,6 @@ package dto
 type RegisterUserRequest struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
+	// ReferralCode — необязательный код пригласившего
+	ReferralCode string `json:"referral_code,omitempty"`
 }
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"strconv"
+	"time"
+)
+
+// MonthLayout — формат месяца в пути выписки и в ответе
+const MonthLayout = "2006-01"
+
+type StatementEntryResponse struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Reference string    `json:"reference,omitempty"`
+	Amount    float64   `json:"amount"`
+	Balance   float64   `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MonthlyStatementResponse struct {
+	Month    string                   `json:"month"`
+	Opening  float64                  `json:"opening_balance"`
+	Closing  float64                  `json:"closing_balance"`
+	Credited float64                  `json:"credited"`
+	Debited  float64                  `json:"debited"`
+	Entries  []StatementEntryResponse `json:"entries"`
+}
+
+func NewStatementEntryResponse(entry models.StatementEntry) StatementEntryResponse {
+	return StatementEntryResponse{
+		ID:        entry.ID,
+		Type:      entry.Type,
+		Reference: entry.Reference,
+		Amount:    entry.Amount,
+		Balance:   entry.Balance,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+func NewMonthlyStatementResponse(statement models.MonthlyStatement) MonthlyStatementResponse {
+	resp := MonthlyStatementResponse{
+		Month:    statement.Month.Format(MonthLayout),
+		Opening:  statement.Opening,
+		Closing:  statement.Closing,
+		Credited: statement.Credited,
+		Debited:  statement.Debited,
+		Entries:  make([]StatementEntryResponse, 0, len(statement.Entries)),
+	}
+	for _, entry := range statement.Entries {
+		resp.Entries = append(resp.Entries, NewStatementEntryResponse(entry))
+	}
+	return resp
+}
+
+// StatementCSVHeader и StatementCSVRecord — строки CSV-выписки
+var StatementCSVHeader = []string{"created_at", "type", "reference", "amount", "balance"}
+
+func StatementCSVRecord(entry models.StatementEntry) []string {
+	return []string{
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		entry.Type,
+		entry.Reference,
+		strconv.FormatFloat(entry.Amount, 'f', -1, 64),
+		strconv.FormatFloat(entry.Balance, 'f', -1, 64),
+	}
+}
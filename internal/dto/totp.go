@@ -0,0 +1,14 @@
+package dto
+
+type TOTPEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TOTPRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+type TransferRequest struct {
+	To   string  `json:"to"`
+	Sum  float64 `json:"sum"`
+	Code string  `json:"code,omitempty"`
+}
+
+type TransferResponse struct {
+	ID        string    `json:"id"`
+	Direction string    `json:"direction"`
+	Login     string    `json:"login"`
+	Sum       float64   `json:"sum"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewTransferResponse — перевод глазами userID: направление и логин второй стороны
+func NewTransferResponse(transfer models.Transfer, userID string) TransferResponse {
+	return TransferResponse{
+		ID:        transfer.ID,
+		Direction: transfer.Direction(userID),
+		Login:     transfer.Counterparty(userID),
+		Sum:       transfer.Sum,
+		CreatedAt: transfer.CreatedAt,
+	}
+}
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+type WebhookResponse struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// секрет отдаём только при создании
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func NewWebhookResponse(webhook models.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func NewWebhookDeliveryResponse(delivery models.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		Payload:        delivery.Payload,
+	}
+	if delivery.Status == models.DeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+	if !delivery.DeliveredAt.IsZero() {
+		deliveredAt := delivery.DeliveredAt
+		resp.DeliveredAt = &deliveredAt
+	}
+	return resp
+}
@@ -1,14 +1,64 @@
 package dto
 
-import "time"
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"strconv"
+	"time"
+)
 
 type WithdrawalRequest struct {
 	Number string  `json:"order"`
 	Sum    float64 `json:"sum"`
+	Code   string  `json:"code,omitempty"`
 }
 
 type WithdrawalsResponse struct {
+	ID          string    `json:"id"`
 	OrderNumber string    `json:"order"`
 	Sum         float64   `json:"sum"`
+	Reversed    float64   `json:"reversed"`
+	Status      string    `json:"status"`
 	ProcessedAt time.Time `json:"processed_at"`
 }
+
+// WithdrawalCSVHeader и WithdrawalCSVRecord — строки CSV-выгрузки списаний; колонки повторяют поля WithdrawalsResponse
+var WithdrawalCSVHeader = []string{"id", "order", "sum", "reversed", "status", "processed_at"}
+
+func WithdrawalCSVRecord(withdrawal models.Withdrawal) []string {
+	return []string{
+		withdrawal.ID,
+		withdrawal.OrderNumber,
+		strconv.FormatFloat(withdrawal.Sum, 'f', -1, 64),
+		strconv.FormatFloat(withdrawal.Reversed, 'f', -1, 64),
+		withdrawal.Status(),
+		withdrawal.ProcessedAt.Format(time.RFC3339),
+	}
+}
+
+// ReversalRequest — sum не указан или 0: вернуть всё, что ещё не возвращено
+type ReversalRequest struct {
+	Sum    float64 `json:"sum"`
+	Reason string  `json:"reason"`
+}
+
+type ReversalResponse struct {
+	ID           string    `json:"id"`
+	WithdrawalID string    `json:"withdrawal_id"`
+	UserID       string    `json:"user_id"`
+	Sum          float64   `json:"sum"`
+	Reason       string    `json:"reason"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func NewReversalResponse(reversal models.Reversal) ReversalResponse {
+	return ReversalResponse{
+		ID:           reversal.ID,
+		WithdrawalID: reversal.WithdrawalID,
+		UserID:       reversal.UserID,
+		Sum:          reversal.Sum,
+		Reason:       reversal.Reason,
+		CreatedBy:    reversal.CreatedBy,
+		CreatedAt:    reversal.CreatedAt,
+	}
+}
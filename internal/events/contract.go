@@ -0,0 +1,24 @@
+package events
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=events
+
+// Publisher — то, что нужно источникам событий: процессору начислений и сервисам, меняющим баланс
+type Publisher interface {
+	Publish(ctx context.Context, userID string, eventType string, data any)
+}
+
+// Subscriber — то, что нужно SSE-хендлеру
+type Subscriber interface {
+	Subscribe(userID string, lastEventID uint64) ([]models.Event, *Subscription)
+}
+
+// BalanceReader — часть balance.Storage, по которой собирается событие баланса
+type BalanceReader interface {
+	GetBalance(ctx context.Context, userID string) (float64, error)
+	GetSumWithdrawal(ctx context.Context, userID string) (float64, error)
+}
@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package events is a generated GoMock package.
+package events
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPublisher is a mock of Publisher interface.
+type MockPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublisherMockRecorder
+}
+
+// MockPublisherMockRecorder is the mock recorder for MockPublisher.
+type MockPublisherMockRecorder struct {
+	mock *MockPublisher
+}
+
+// NewMockPublisher creates a new mock instance.
+func NewMockPublisher(ctrl *gomock.Controller) *MockPublisher {
+	mock := &MockPublisher{ctrl: ctrl}
+	mock.recorder = &MockPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublisher) EXPECT() *MockPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockPublisher) Publish(ctx context.Context, userID, eventType string, data any) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", ctx, userID, eventType, data)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockPublisherMockRecorder) Publish(ctx, userID, eventType, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPublisher)(nil).Publish), ctx, userID, eventType, data)
+}
+
+// MockSubscriber is a mock of Subscriber interface.
+type MockSubscriber struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriberMockRecorder
+}
+
+// MockSubscriberMockRecorder is the mock recorder for MockSubscriber.
+type MockSubscriberMockRecorder struct {
+	mock *MockSubscriber
+}
+
+// NewMockSubscriber creates a new mock instance.
+func NewMockSubscriber(ctrl *gomock.Controller) *MockSubscriber {
+	mock := &MockSubscriber{ctrl: ctrl}
+	mock.recorder = &MockSubscriberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscriber) EXPECT() *MockSubscriberMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockSubscriber) Subscribe(userID string, lastEventID uint64) ([]models.Event, *Subscription) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", userID, lastEventID)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(*Subscription)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockSubscriberMockRecorder) Subscribe(userID, lastEventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockSubscriber)(nil).Subscribe), userID, lastEventID)
+}
+
+// MockBalanceReader is a mock of BalanceReader interface.
+type MockBalanceReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockBalanceReaderMockRecorder
+}
+
+// MockBalanceReaderMockRecorder is the mock recorder for MockBalanceReader.
+type MockBalanceReaderMockRecorder struct {
+	mock *MockBalanceReader
+}
+
+// NewMockBalanceReader creates a new mock instance.
+func NewMockBalanceReader(ctrl *gomock.Controller) *MockBalanceReader {
+	mock := &MockBalanceReader{ctrl: ctrl}
+	mock.recorder = &MockBalanceReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBalanceReader) EXPECT() *MockBalanceReaderMockRecorder {
+	return m.recorder
+}
+
+// GetBalance mocks base method.
+func (m *MockBalanceReader) GetBalance(ctx context.Context, userID string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", ctx, userID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockBalanceReaderMockRecorder) GetBalance(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockBalanceReader)(nil).GetBalance), ctx, userID)
+}
+
+// GetSumWithdrawal mocks base method.
+func (m *MockBalanceReader) GetSumWithdrawal(ctx context.Context, userID string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSumWithdrawal", ctx, userID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSumWithdrawal indicates an expected call of GetSumWithdrawal.
+func (mr *MockBalanceReaderMockRecorder) GetSumWithdrawal(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSumWithdrawal", reflect.TypeOf((*MockBalanceReader)(nil).GetSumWithdrawal), ctx, userID)
+}
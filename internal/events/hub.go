@@ -0,0 +1,186 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// subscriptionBuffer — сколько событий может ждать медленного клиента, дальше подписка закрывается
+// и клиент переподключается с Last-Event-ID
+const subscriptionBuffer = 64
+
+// Hub — внутренняя pub/sub шина событий пользователя. Живёт в памяти процесса и хранит
+// последние historySize событий каждого пользователя, чтобы переподключившийся клиент их дополучил.
+// История пользователя без подписок живёт resumeWindow с последнего события, потом её убирает Prune.
+type Hub struct {
+	mu           sync.Mutex
+	lastID       uint64
+	historySize  int
+	resumeWindow time.Duration
+	history      map[string][]models.Event
+	// evicted — id последнего вытесненного из истории события пользователя
+	evicted map[string]uint64
+	// published — когда пользователю последний раз публиковали событие
+	published map[string]time.Time
+	// pruned — наибольший id среди событий, удалённых Prune вместе с историей пользователя
+	pruned      uint64
+	subscribers map[string]map[*Subscription]struct{}
+}
+
+func NewHub(historySize int, resumeWindow time.Duration) *Hub {
+	return &Hub{
+		// id растут и между перезапусками, иначе Last-Event-ID от прошлого процесса отрезал бы новые события
+		lastID:       uint64(time.Now().UnixMicro()),
+		historySize:  historySize,
+		resumeWindow: resumeWindow,
+		history:      make(map[string][]models.Event),
+		evicted:      make(map[string]uint64),
+		published:    make(map[string]time.Time),
+		subscribers:  make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+func (h *Hub) Publish(ctx context.Context, userID string, eventType string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logger.FromContext(ctx).Error("Cannot marshal event", zap.String("type", eventType), zap.Error(err))
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	event := models.Event{ID: h.lastID, UserID: userID, Type: eventType, Data: payload}
+
+	history := append(h.history[userID], event)
+	if len(history) > h.historySize {
+		h.evicted[userID] = history[0].ID
+		history = history[1:]
+	}
+	h.history[userID] = history
+	h.published[userID] = time.Now()
+
+	for sub := range h.subscribers[userID] {
+		select {
+		case sub.events <- event:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribe возвращает события после lastEventID из истории и подписку на новые.
+// Если часть нужных событий уже вытеснена, первым в истории идёт resync.
+func (h *Hub) Subscribe(userID string, lastEventID uint64) ([]models.Event, *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []models.Event
+	if lastEventID > 0 {
+		// после Prune не известно, были ли у пользователя события новее lastEventID, поэтому лучше лишний resync
+		_, known := h.history[userID]
+		if lastEventID < h.evicted[userID] || (!known && lastEventID < h.pruned) {
+			backlog = append(backlog, models.Event{ID: lastEventID, UserID: userID, Type: models.EventResync, Data: json.RawMessage("{}")})
+		}
+		for _, event := range h.history[userID] {
+			if event.ID > lastEventID {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	sub := &Subscription{hub: h, userID: userID, events: make(chan models.Event, subscriptionBuffer)}
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+	return backlog, sub
+}
+
+// Prune забывает историю пользователей, у которых нет подписок и не было событий дольше resumeWindow.
+// Клиент, вернувшийся позже, получит resync.
+func (h *Hub) Prune() {
+	h.prune(time.Now())
+}
+
+func (h *Hub) prune(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for userID, history := range h.history {
+		if len(h.subscribers[userID]) > 0 || now.Sub(h.published[userID]) < h.resumeWindow {
+			continue
+		}
+		if len(history) > 0 && history[len(history)-1].ID > h.pruned {
+			h.pruned = history[len(history)-1].ID
+		}
+		delete(h.history, userID)
+		delete(h.evicted, userID)
+		delete(h.published, userID)
+	}
+}
+
+// Close снимает все подписки, чтобы SSE-соединения закрылись и не держали graceful shutdown
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove вызывается под мьютексом
+func (h *Hub) remove(sub *Subscription) {
+	subs, ok := h.subscribers[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok = subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.userID)
+	}
+	close(sub.events)
+}
+
+type Subscription struct {
+	hub    *Hub
+	userID string
+	events chan models.Event
+}
+
+// Events закрывается, когда подписка снята: самим клиентом или хабом из-за переполнения
+func (s *Subscription) Events() <-chan models.Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// PublishBalance публикует текущий баланс пользователя. Ошибки чтения только логируются:
+// событие — подсказка клиенту, деньги уже посчитаны.
+func PublishBalance(ctx context.Context, publisher Publisher, reader BalanceReader, userID string) {
+	current, err := reader.GetBalance(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Cannot read balance for event", zap.Error(err))
+		return
+	}
+	withdrawn, err := reader.GetSumWithdrawal(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Cannot read withdrawals for event", zap.Error(err))
+		return
+	}
+	publisher.Publish(ctx, userID, models.EventBalance, models.BalanceEvent{Current: current, Withdrawn: withdrawn})
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestHub_PublishSubscribe(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub(10, time.Hour)
+
+	_, sub := hub.Subscribe("1", 0)
+	defer sub.Close()
+
+	hub.Publish(ctx, "2", models.EventOrder, models.OrderEvent{Number: "1"})
+	hub.Publish(ctx, "1", models.EventOrder, models.OrderEvent{Number: "79927398713", Status: "PROCESSED", Accrual: 500})
+
+	event := <-sub.Events()
+	assert.Equal(t, "1", event.UserID)
+	assert.Equal(t, models.EventOrder, event.Type)
+	assert.JSONEq(t, `{"number":"79927398713","status":"PROCESSED","accrual":500}`, string(event.Data))
+	assert.Empty(t, sub.Events(), "событие другого пользователя не должно приходить")
+}
+
+func TestHub_Resume(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub(2, time.Hour)
+
+	var ids []uint64
+	for i := 0; i < 4; i++ {
+		hub.Publish(ctx, "1", models.EventBalance, models.BalanceEvent{Current: float64(i)})
+		backlog, sub := hub.Subscribe("1", 1)
+		sub.Close()
+		ids = append(ids, backlog[len(backlog)-1].ID)
+	}
+
+	// без Last-Event-ID история не отдаётся
+	backlog, sub := hub.Subscribe("1", 0)
+	sub.Close()
+	assert.Empty(t, backlog)
+
+	// последние два события ещё в истории
+	backlog, sub = hub.Subscribe("1", ids[1])
+	sub.Close()
+	require.Len(t, backlog, 2)
+	assert.Equal(t, ids[2], backlog[0].ID)
+	assert.Equal(t, ids[3], backlog[1].ID)
+
+	// первое вытеснено — клиенту нужен resync
+	backlog, sub = hub.Subscribe("1", ids[0])
+	sub.Close()
+	require.Len(t, backlog, 3)
+	assert.Equal(t, models.EventResync, backlog[0].Type)
+}
+
+func TestHub_SlowSubscriberDropped(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub(1, time.Hour)
+
+	_, sub := hub.Subscribe("1", 0)
+	for i := 0; i <= subscriptionBuffer; i++ {
+		hub.Publish(ctx, "1", models.EventBalance, models.BalanceEvent{})
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Equal(t, subscriptionBuffer, received)
+	// повторное закрытие уже снятой подписки безопасно
+	sub.Close()
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(1, time.Hour)
+	_, first := hub.Subscribe("1", 0)
+	_, second := hub.Subscribe("2", 0)
+
+	hub.Close()
+
+	_, ok := <-first.Events()
+	assert.False(t, ok)
+	_, ok = <-second.Events()
+	assert.False(t, ok)
+}
+
+func TestHub_Prune(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub(10, time.Hour)
+
+	hub.Publish(ctx, "1", models.EventBalance, models.BalanceEvent{Current: 1})
+	hub.Publish(ctx, "2", models.EventBalance, models.BalanceEvent{Current: 2})
+	backlog, sub := hub.Subscribe("1", 1)
+	sub.Close()
+	firstID := backlog[0].ID
+	_, sub = hub.Subscribe("2", 0)
+	defer sub.Close()
+
+	// окно ещё не прошло — история остаётся
+	hub.prune(time.Now())
+	assert.Len(t, hub.history, 2)
+
+	// у второго пользователя открыт поток, его история нужна для переподключения
+	hub.prune(time.Now().Add(2 * time.Hour))
+	assert.NotContains(t, hub.history, "1")
+	assert.NotContains(t, hub.published, "1")
+	assert.Contains(t, hub.history, "2")
+
+	// вернувшийся после очистки клиент не знает, что пропустил, и получает resync
+	backlog, resumed := hub.Subscribe("1", firstID-1)
+	resumed.Close()
+	require.Len(t, backlog, 1)
+	assert.Equal(t, models.EventResync, backlog[0].Type)
+}
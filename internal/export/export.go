@@ -0,0 +1,146 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Форматы выгрузки
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	contentTypeCSV    = "text/csv"
+	contentTypeNDJSON = "application/x-ndjson"
+	// flushEvery — через сколько строк отправлять накопленное клиенту
+	flushEvery = 100
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Negotiate выбирает формат выгрузки: параметр format важнее Accept.
+// Из Accept берётся первый поддерживаемый тип; без подходящего — CSV.
+func Negotiate(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case FormatCSV, FormatNDJSON:
+		return format, nil
+	case "":
+	default:
+		return "", ErrUnknownFormat
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		switch strings.TrimSpace(mediaType) {
+		case contentTypeCSV:
+			return FormatCSV, nil
+		case contentTypeNDJSON, "application/jsonl":
+			return FormatNDJSON, nil
+		}
+	}
+	return FormatCSV, nil
+}
+
+// Writer пишет выгрузку построчно. Заголовки ответа уходят с первой строкой (или на Close),
+// поэтому ошибку до первой строки ещё можно отдать обычным ответом — см. Started.
+type Writer struct {
+	w        http.ResponseWriter
+	rc       *http.ResponseController
+	format   string
+	filename string
+	header   []string
+	csv      *csv.Writer
+	enc      *json.Encoder
+	rows     int
+	started  bool
+}
+
+// NewWriter — filename без расширения, header — названия колонок CSV
+func NewWriter(w http.ResponseWriter, format string, filename string, header []string) *Writer {
+	return &Writer{
+		w:        w,
+		rc:       http.NewResponseController(w),
+		format:   format,
+		filename: filename,
+		header:   header,
+	}
+}
+
+// Write добавляет строку: record — для CSV, item — для NDJSON
+func (e *Writer) Write(record []string, item any) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	var err error
+	if e.format == FormatNDJSON {
+		err = e.enc.Encode(item)
+	} else {
+		err = e.csv.Write(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%flushEvery == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// Close дописывает остаток. Пустая выгрузка — это 200 с одной строкой заголовка CSV или пустым NDJSON.
+func (e *Writer) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+// Started — ушли ли клиенту заголовки ответа
+func (e *Writer) Started() bool {
+	return e.started
+}
+
+func (e *Writer) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	contentType, extension := contentTypeCSV, ".csv"
+	if e.format == FormatNDJSON {
+		contentType, extension = contentTypeNDJSON, ".ndjson"
+	}
+	e.w.Header().Set("Content-Type", contentType)
+	e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.filename+extension+`"`)
+	// nginx иначе буферизует ответ целиком
+	e.w.Header().Set("X-Accel-Buffering", "no")
+	e.w.WriteHeader(http.StatusOK)
+
+	if e.format == FormatNDJSON {
+		e.enc = json.NewEncoder(e.w)
+		return nil
+	}
+	e.csv = csv.NewWriter(e.w)
+	return e.csv.Write(e.header)
+}
+
+func (e *Writer) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	err := e.rc.Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
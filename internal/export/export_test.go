@@ -0,0 +1,66 @@
+package export
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		accept  string
+		want    string
+		wantErr error
+	}{
+		{name: "default", url: "/export", want: FormatCSV},
+		{name: "format wins over accept", url: "/export?format=csv", accept: "application/x-ndjson", want: FormatCSV},
+		{name: "accept ndjson", url: "/export", accept: "application/x-ndjson", want: FormatNDJSON},
+		{name: "first supported accept", url: "/export", accept: "application/xml, application/jsonl;q=0.9, text/csv", want: FormatNDJSON},
+		{name: "unsupported accept", url: "/export", accept: "*/*", want: FormatCSV},
+		{name: "unknown format", url: "/export?format=xlsx", wantErr: ErrUnknownFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			r.Header.Set("Accept", tt.accept)
+			got, err := Negotiate(r)
+			assert.True(t, errors.Is(err, tt.wantErr))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	type row struct {
+		Number string  `json:"number"`
+		Sum    float64 `json:"sum"`
+	}
+
+	rec := httptest.NewRecorder()
+	writer := NewWriter(rec, FormatCSV, "orders", []string{"number", "sum"})
+	assert.False(t, writer.Started())
+	assert.NoError(t, writer.Write([]string{"123", "1.5"}, row{"123", 1.5}))
+	assert.True(t, writer.Started())
+	assert.NoError(t, writer.Close())
+	assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="orders.csv"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "number,sum\n123,1.5\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	writer = NewWriter(rec, FormatNDJSON, "orders", []string{"number", "sum"})
+	assert.NoError(t, writer.Write([]string{"123", "1.5"}, row{"123", 1.5}))
+	assert.NoError(t, writer.Write([]string{"456", "2"}, row{"456", 2}))
+	assert.NoError(t, writer.Close())
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"number\":\"123\",\"sum\":1.5}\n{\"number\":\"456\",\"sum\":2}\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	writer = NewWriter(rec, FormatCSV, "orders", []string{"number", "sum"})
+	assert.NoError(t, writer.Close())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "number,sum\n", rec.Body.String())
+}
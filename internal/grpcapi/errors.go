@@ -0,0 +1,87 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	balanceStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	ordersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	usersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain — домен в ErrorInfo; reason в нём совпадает с кодами problem+json из HTTP API
+const errorDomain = "gophermart"
+
+type mapping struct {
+	err     error
+	code    codes.Code
+	reason  string
+	message string
+}
+
+// domainErrors — соответствие доменных ошибок gRPC-статусам. Порядок важен: проверяется первое совпадение.
+var domainErrors = []mapping{
+	{orders.ErrLuhn, codes.InvalidArgument, problem.CodeInvalidOrderNumber, "Invalid order number"},
+	{orders.ErrOrderAnotherUser, codes.AlreadyExists, problem.CodeOrderAnotherUser, "Order created by another user"},
+	{orders.ErrAlreadyProcessed, codes.FailedPrecondition, problem.CodeOrderProcessed, "Order already processed"},
+	{users.ErrIncorrectData, codes.Unauthenticated, problem.CodeInvalidCredentials, "Incorrect login/password"},
+	{users.ErrInvalidCode, codes.PermissionDenied, problem.CodeInvalidSecondFactor, "Invalid two-factor code"},
+	{users.ErrSecondFactorLocked, codes.ResourceExhausted, problem.CodeSecondFactorLocked, "Too many invalid two-factor codes, try again later"},
+	{balance.ErrInsufficientFunds, codes.FailedPrecondition, problem.CodeInsufficientFunds, "Not enough money"},
+	{balanceStore.ErrInsufficientFunds, codes.FailedPrecondition, problem.CodeInsufficientFunds, "Not enough money"},
+	{usersStore.ErrConflict, codes.AlreadyExists, problem.CodeLoginTaken, "User login already exists"},
+	{usersStore.ErrUnknownReferral, codes.InvalidArgument, problem.CodeUnknownReferral, "Unknown referral code"},
+	{ordersStore.ErrConflict, codes.Aborted, problem.CodeConflict, "Data conflict"},
+	{usersStore.ErrNotFound, codes.NotFound, problem.CodeNotFound, "User not found"},
+	{ordersStore.ErrNotFound, codes.NotFound, problem.CodeNotFound, "Order not found"},
+}
+
+// toStatus переводит ошибку сервиса в gRPC-статус; неизвестные ошибки логируются и отдаются как Internal
+func toStatus(ctx context.Context, err error, message string) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, message)
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, message)
+	}
+	for _, m := range domainErrors {
+		if errors.Is(err, m.err) {
+			return newStatus(m.code, m.reason, m.message)
+		}
+	}
+	logger.FromContext(ctx).Error(message, zap.Error(err))
+	return newStatus(codes.Internal, problem.CodeInternal, message)
+}
+
+func newStatus(code codes.Code, reason string, message string) error {
+	st := status.New(code, message)
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+func validationStatus(fields ...problem.FieldError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+	for _, f := range fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Message})
+	}
+	st := status.New(codes.InvalidArgument, "Request validation failed")
+	withDetails, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: problem.CodeValidationFailed, Domain: errorDomain},
+		&errdetails.BadRequest{FieldViolations: violations},
+	)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
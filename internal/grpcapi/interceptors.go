@@ -0,0 +1,158 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	pb "github.com/egor-zakharov/go-musthave-diploma-tpl/pkg/pb/gophermart/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	grpcCodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+const (
+	authorizationKey = "authorization"
+	bearerPrefix     = "bearer "
+	requestIDKey     = "x-request-id"
+)
+
+// publicMethods не требуют токена — ими его и получают
+var publicMethods = map[string]bool{
+	pb.Gophermart_Register_FullMethodName: true,
+	pb.Gophermart_Login_FullMethodName:    true,
+}
+
+// TracingInterceptor продолжает трейс из метаданных (W3C traceparent) и открывает серверный спан на вызов
+func TracingInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	ctx, span := tracing.Tracer().Start(ctx, info.FullMethod,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.system", "grpc"), attribute.String("rpc.method", info.FullMethod)))
+	defer span.End()
+
+	resp, err := handler(ctx, req)
+	code := status.Code(err)
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+	if err != nil {
+		span.SetStatus(codes.Error, code.String())
+	}
+	return resp, err
+}
+
+// RequestIDInterceptor — аналог RequestIDMiddleware: берёт x-request-id из метаданных или генерирует свой,
+// отдаёт его в заголовке ответа и кладёт в контекст логгер запроса
+func RequestIDInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	requestID := firstValue(ctx, requestIDKey)
+	if !middlewares.IsValidRequestID(requestID) {
+		requestID = logger.NewRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+	fields := []zap.Field{zap.String("request_id", requestID), zap.String("method", info.FullMethod)}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+	}
+
+	ctx = logger.WithRequestID(ctx, requestID)
+	ctx = logger.WithLogger(ctx, logger.Log().With(fields...))
+	return handler(ctx, req)
+}
+
+// AuthInterceptor — аналог AuthorizedMiddleware: токен тот же JWT, что и в cookie, но передаётся
+// в метаданных "authorization: Bearer <token>"
+func AuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	token := firstValue(ctx, authorizationKey)
+	if len(token) < len(bearerPrefix) || !strings.EqualFold(token[:len(bearerPrefix)], bearerPrefix) {
+		return nil, newStatus(grpcCodes.Unauthenticated, problem.CodeUnauthorized, "Unauthorized requests forbidden")
+	}
+
+	claims := middlewares.GetClaims(token[len(bearerPrefix):])
+	if claims == nil || claims.UserID == "" {
+		return nil, newStatus(grpcCodes.Unauthenticated, problem.CodeUnauthorized, "Unauthorized requests forbidden")
+	}
+
+	// Токены, выпущенные до появления ролей, считаем пользовательскими
+	role := claims.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	ctx = context.WithValue(ctx, middlewares.ContextUserIDKey, claims.UserID)
+	ctx = context.WithValue(ctx, middlewares.ContextUserRoleKey, role)
+	ctx = context.WithValue(ctx, middlewares.ContextIssuedAtKey, middlewares.IssuedAt(claims))
+	ctx = logger.WithLogger(ctx, logger.FromContext(ctx).With(zap.String("user_id", claims.UserID)))
+	return handler(ctx, req)
+}
+
+// SessionInterceptor отклоняет токены, выпущенные до отзыва сессий. Должен стоять после AuthInterceptor
+func SessionInterceptor(usersService users.Service) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		userID := ctx.Value(middlewares.ContextUserIDKey).(string)
+		issuedAt, _ := ctx.Value(middlewares.ContextIssuedAtKey).(time.Time)
+		err := usersService.CheckSession(ctx, userID, issuedAt)
+		if errors.Is(err, users.ErrSessionRevoked) {
+			return nil, newStatus(grpcCodes.Unauthenticated, problem.CodeUnauthorized, "Session revoked")
+		}
+		if err != nil {
+			return nil, toStatus(ctx, err, "Cannot check session")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func firstValue(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// metadataCarrier позволяет пропагатору OpenTelemetry читать gRPC-метаданные
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+var _ propagation.TextMapCarrier = metadataCarrier{}
@@ -0,0 +1,220 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	balanceStorage "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	ordersStorage "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	pb "github.com/egor-zakharov/go-musthave-diploma-tpl/pkg/pb/gophermart/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// Server — gRPC-обёртка над теми же сервисами, что и HTTP-хендлеры /api/user/*
+type Server struct {
+	pb.UnimplementedGophermartServer
+
+	users         users.Service
+	orders        orders.Service
+	balance       balance.Service
+	totpThreshold float64
+}
+
+func New(users users.Service, orders orders.Service, balance balance.Service, totpThreshold float64) *Server {
+	return &Server{users: users, orders: orders, balance: balance, totpThreshold: totpThreshold}
+}
+
+// NewGRPCServer собирает grpc.Server с интерсепторами и зарегистрированным сервисом
+func NewGRPCServer(s *Server, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(TracingInterceptor, RequestIDInterceptor, AuthInterceptor, SessionInterceptor(s.users)))
+	srv := grpc.NewServer(opts...)
+	pb.RegisterGophermartServer(srv, s)
+	return srv
+}
+
+func (s *Server) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.AuthResponse, error) {
+	user := models.User{Login: req.GetLogin(), Password: req.GetPassword(), InviteCode: req.GetReferralCode()}
+	if err := validateCredentials(user); err != nil {
+		return nil, err
+	}
+
+	registered, err := s.users.Register(ctx, user)
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot register user")
+	}
+	return authResponse(ctx, registered)
+}
+
+func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.AuthResponse, error) {
+	user := models.User{Login: req.GetLogin(), Password: req.GetPassword()}
+	if err := validateCredentials(user); err != nil {
+		return nil, err
+	}
+
+	login, err := s.users.Login(ctx, user, req.GetCode())
+	if errors.Is(err, users.ErrSecondFactorRequired) {
+		return nil, newStatus(codes.Unauthenticated, problem.CodeSecondFactorRequired, "Two-factor code required")
+	}
+	if errors.Is(err, users.ErrInvalidCode) {
+		return nil, newStatus(codes.Unauthenticated, problem.CodeInvalidSecondFactor, "Invalid two-factor code")
+	}
+	if errors.Is(err, users.ErrSecondFactorLocked) {
+		return nil, toStatus(ctx, err, "Cannot verify two-factor code")
+	}
+	if err != nil {
+		return nil, newStatus(codes.Unauthenticated, problem.CodeInvalidCredentials, "Incorrect login/password")
+	}
+	return authResponse(ctx, login)
+}
+
+func (s *Server) UploadOrder(ctx context.Context, req *pb.UploadOrderRequest) (*pb.UploadOrderResponse, error) {
+	userID := ctx.Value(middlewares.ContextUserIDKey).(string)
+	if req.GetNumber() == "" {
+		return nil, validationStatus(problem.FieldError{Field: "number", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
+	}
+
+	err := s.orders.Add(ctx, req.GetNumber(), userID)
+	if errors.Is(err, orders.ErrDuplicate) {
+		// заказ уже загружен этим пользователем — это не ошибка
+		return &pb.UploadOrderResponse{Accepted: false}, nil
+	}
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot add order")
+	}
+	return &pb.UploadOrderResponse{Accepted: true}, nil
+}
+
+func (s *Server) ListOrders(ctx context.Context, _ *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	userID := ctx.Value(middlewares.ContextUserIDKey).(string)
+	ords, err := s.orders.GetAllByUser(ctx, userID)
+	if errors.Is(err, ordersStorage.ErrNotFound) {
+		return &pb.ListOrdersResponse{}, nil
+	}
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot get orders")
+	}
+
+	resp := &pb.ListOrdersResponse{Orders: make([]*pb.Order, 0, len(*ords))}
+	for _, order := range *ords {
+		resp.Orders = append(resp.Orders, &pb.Order{
+			Number:     order.Number,
+			Status:     order.Status,
+			Accrual:    order.Accrual,
+			UploadedAt: timestamppb.New(order.UploadedAt),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) GetBalance(ctx context.Context, _ *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	userID := ctx.Value(middlewares.ContextUserIDKey).(string)
+	bal, err := s.balance.GetBalance(ctx, userID)
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot get balance")
+	}
+
+	withdrawn, err := s.balance.GetSumWithdraw(ctx, userID)
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot get withdraw")
+	}
+	return &pb.GetBalanceResponse{Current: bal, Withdrawn: withdrawn}, nil
+}
+
+// Withdraw повторяет логику createwithdraw.Handler, включая второй фактор для крупных сумм
+func (s *Server) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	userID := ctx.Value(middlewares.ContextUserIDKey).(string)
+	if req.GetOrder() == "" {
+		return nil, validationStatus(problem.FieldError{Field: "order", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
+	}
+	if req.GetSum() <= 0 {
+		return nil, validationStatus(problem.FieldError{Field: "sum", Code: problem.FieldInvalid, Message: "must be greater than 0"})
+	}
+
+	err := s.users.RequireSecondFactor(ctx, userID, req.GetSum(), s.totpThreshold, req.GetCode())
+	if errors.Is(err, users.ErrSecondFactorRequired) {
+		return nil, newStatus(codes.PermissionDenied, problem.CodeSecondFactorRequired, "Two-factor code required")
+	}
+	if errors.Is(err, users.ErrInvalidCode) {
+		return nil, newStatus(codes.PermissionDenied, problem.CodeInvalidSecondFactor, "Invalid two-factor code")
+	}
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot verify two-factor code")
+	}
+
+	err = s.orders.Add(ctx, req.GetOrder(), userID)
+	if errors.Is(err, orders.ErrDuplicate) {
+		// как и в HTTP: повтор уже загруженного номера ничего не списывает
+		return &pb.WithdrawResponse{}, nil
+	}
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot add order")
+	}
+
+	ok, err := s.balance.CanWithdraw(ctx, req.GetSum(), userID)
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot can withdraw")
+	}
+	if !ok {
+		return nil, newStatus(codes.FailedPrecondition, problem.CodeInsufficientFunds, "Not enough money")
+	}
+
+	err = s.balance.AddWithdraw(ctx, models.Withdrawal{OrderNumber: req.GetOrder(), Sum: req.GetSum()}, userID)
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot add withdraw")
+	}
+	return &pb.WithdrawResponse{}, nil
+}
+
+func (s *Server) ListWithdrawals(ctx context.Context, _ *pb.ListWithdrawalsRequest) (*pb.ListWithdrawalsResponse, error) {
+	userID := ctx.Value(middlewares.ContextUserIDKey).(string)
+	withdrawals, err := s.balance.GetAllWithdrawByUser(ctx, userID)
+	if errors.Is(err, balanceStorage.ErrNotFound) {
+		return &pb.ListWithdrawalsResponse{}, nil
+	}
+	if err != nil {
+		return nil, toStatus(ctx, err, "Cannot get withdrawals")
+	}
+
+	resp := &pb.ListWithdrawalsResponse{Withdrawals: make([]*pb.Withdrawal, 0, len(*withdrawals))}
+	for _, withdrawal := range *withdrawals {
+		resp.Withdrawals = append(resp.Withdrawals, &pb.Withdrawal{
+			Id:          withdrawal.ID,
+			Order:       withdrawal.OrderNumber,
+			Sum:         withdrawal.Sum,
+			Reversed:    withdrawal.Reversed,
+			Status:      withdrawal.Status(),
+			ProcessedAt: timestamppb.New(withdrawal.ProcessedAt),
+		})
+	}
+	return resp, nil
+}
+
+func validateCredentials(user models.User) error {
+	var fields []problem.FieldError
+	if !user.IsValidLogin() {
+		fields = append(fields, problem.FieldError{Field: "login", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
+	}
+	if !user.IsValidPass() {
+		fields = append(fields, problem.FieldError{Field: "password", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
+	}
+	if len(fields) > 0 {
+		return validationStatus(fields...)
+	}
+	return nil
+}
+
+func authResponse(ctx context.Context, user *models.User) (*pb.AuthResponse, error) {
+	token, err := middlewares.BuildJWTString(user.UserID, user.Role)
+	if err != nil {
+		return nil, toStatus(ctx, err, "Can not build auth token")
+	}
+	return &pb.AuthResponse{Token: token, ExpiresAt: timestamppb.New(time.Now().Add(middlewares.TokenExp))}, nil
+}
@@ -0,0 +1,173 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	ordersStorage "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	pb "github.com/egor-zakharov/go-musthave-diploma-tpl/pkg/pb/gophermart/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+type mocks struct {
+	users   *users.MockService
+	orders  *orders.MockService
+	balance *balance.MockService
+}
+
+func newClient(t *testing.T, setup func(m mocks)) pb.GophermartClient {
+	ctrl := gomock.NewController(t)
+	m := mocks{
+		users:   users.NewMockService(ctrl),
+		orders:  orders.NewMockService(ctrl),
+		balance: balance.NewMockService(ctrl),
+	}
+	if setup != nil {
+		setup(m)
+	}
+	// сессии не отозваны, если тест не задал иное
+	m.users.EXPECT().CheckSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	listener := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer(New(m.users, m.orders, m.balance, 100))
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewGophermartClient(conn)
+}
+
+func authorized(t *testing.T, userID string) context.Context {
+	token, err := middlewares.BuildJWTString(userID, models.RoleUser)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer "+token)
+}
+
+func reason(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	client := newClient(t, nil)
+
+	_, err := client.GetBalance(context.Background(), &pb.GetBalanceRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, problem.CodeUnauthorized, reason(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer broken")
+	_, err = client.GetBalance(ctx, &pb.GetBalanceRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestSessionInterceptor(t *testing.T) {
+	client := newClient(t, func(m mocks) {
+		m.users.EXPECT().CheckSession(gomock.Any(), "1", gomock.Any()).Return(users.ErrSessionRevoked)
+	})
+
+	_, err := client.GetBalance(authorized(t, "1"), &pb.GetBalanceRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, problem.CodeUnauthorized, reason(err))
+}
+
+func TestLogin(t *testing.T) {
+	client := newClient(t, func(m mocks) {
+		m.users.EXPECT().Login(gomock.Any(), models.User{Login: "login", Password: "pass"}, "").
+			Return(&models.User{UserID: "1", Role: models.RoleUser}, nil)
+		m.balance.EXPECT().GetBalance(gomock.Any(), "1").Return(10.5, nil)
+		m.balance.EXPECT().GetSumWithdraw(gomock.Any(), "1").Return(2.0, nil)
+	})
+
+	_, err := client.Login(context.Background(), &pb.LoginRequest{Login: "login"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := client.Login(context.Background(), &pb.LoginRequest{Login: "login", Password: "pass"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetToken())
+
+	// выданный токен подходит для остальных методов
+	ctx := metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer "+resp.GetToken())
+	bal, err := client.GetBalance(ctx, &pb.GetBalanceRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 10.5, bal.GetCurrent())
+	assert.Equal(t, 2.0, bal.GetWithdrawn())
+}
+
+func TestUploadOrder(t *testing.T) {
+	client := newClient(t, func(m mocks) {
+		m.orders.EXPECT().Add(gomock.Any(), "79927398713", "1").Return(nil)
+		m.orders.EXPECT().Add(gomock.Any(), "79927398713", "1").Return(orders.ErrDuplicate)
+		m.orders.EXPECT().Add(gomock.Any(), "123", "1").Return(orders.ErrLuhn)
+	})
+	ctx := authorized(t, "1")
+
+	resp, err := client.UploadOrder(ctx, &pb.UploadOrderRequest{Number: "79927398713"})
+	require.NoError(t, err)
+	assert.True(t, resp.GetAccepted())
+
+	resp, err = client.UploadOrder(ctx, &pb.UploadOrderRequest{Number: "79927398713"})
+	require.NoError(t, err)
+	assert.False(t, resp.GetAccepted())
+
+	_, err = client.UploadOrder(ctx, &pb.UploadOrderRequest{Number: "123"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, problem.CodeInvalidOrderNumber, reason(err))
+}
+
+func TestListOrdersEmpty(t *testing.T) {
+	client := newClient(t, func(m mocks) {
+		m.orders.EXPECT().GetAllByUser(gomock.Any(), "1").Return(nil, ordersStorage.ErrNotFound)
+	})
+
+	resp, err := client.ListOrders(authorized(t, "1"), &pb.ListOrdersRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetOrders())
+}
+
+func TestWithdraw(t *testing.T) {
+	client := newClient(t, func(m mocks) {
+		m.orders.EXPECT().Add(gomock.Any(), "2377225624", "1").Return(nil).Times(2)
+		m.balance.EXPECT().CanWithdraw(gomock.Any(), 50.0, "1").Return(false, nil)
+		m.balance.EXPECT().CanWithdraw(gomock.Any(), 40.0, "1").Return(true, nil)
+		m.balance.EXPECT().AddWithdraw(gomock.Any(), models.Withdrawal{OrderNumber: "2377225624", Sum: 40}, "1").Return(nil)
+		m.users.EXPECT().RequireSecondFactor(gomock.Any(), "1", 50.0, 100.0, "").Return(nil)
+		m.users.EXPECT().RequireSecondFactor(gomock.Any(), "1", 40.0, 100.0, "").Return(nil)
+		m.users.EXPECT().RequireSecondFactor(gomock.Any(), "1", 500.0, 100.0, "").Return(users.ErrSecondFactorRequired)
+	})
+	ctx := authorized(t, "1")
+
+	_, err := client.Withdraw(ctx, &pb.WithdrawRequest{Order: "2377225624", Sum: 50})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, problem.CodeInsufficientFunds, reason(err))
+
+	_, err = client.Withdraw(ctx, &pb.WithdrawRequest{Order: "2377225624", Sum: 40})
+	require.NoError(t, err)
+
+	// сумма выше порога при включённой 2FA требует код
+	_, err = client.Withdraw(ctx, &pb.WithdrawRequest{Order: "2377225624", Sum: 500})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, problem.CodeSecondFactorRequired, reason(err))
+}
@@ -0,0 +1,58 @@
+package accrualcallback
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/processors/accrual"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"net/http"
+)
+
+type Handler struct {
+	processor accrual.Processor
+}
+
+func New(processor accrual.Processor) *Handler {
+	return &Handler{processor: processor}
+}
+
+// Handle принимает результат расчёта от accrual. Повтор одного и того же результата безопасен и тоже отвечает 204.
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.AccrualOrderResponse{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+	if requestData.Order == "" {
+		problem.Validation(w, r, problem.FieldError{Field: "order", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
+		return
+	}
+	if requestData.Accrual < 0 {
+		problem.Validation(w, r, problem.FieldError{Field: "accrual", Code: problem.FieldInvalid, Message: "must not be negative"})
+		return
+	}
+
+	err := h.processor.Apply(r.Context(), *requestData)
+	if errors.Is(err, accrual.ErrUnknownStatus) {
+		problem.Validation(w, r, problem.FieldError{Field: "status", Code: problem.FieldInvalid, Message: "must be one of REGISTERED, PROCESSING, INVALID, PROCESSED"})
+		return
+	}
+	if errors.Is(err, orders.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "Order not found")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot apply accrual result")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,96 @@
+package admincreateadjustment
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/adjustments"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	usersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	adjustments adjustments.Service
+	users       users.Service
+}
+
+func New(adjustments adjustments.Service, users users.Service) *Handler {
+	return &Handler{adjustments: adjustments, users: users}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	actorID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.CreateAdjustmentRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+	_, err := h.users.Get(r.Context(), userID)
+	if errors.Is(err, usersStore.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get user")
+		return
+	}
+
+	adjustment, err := h.adjustments.Create(r.Context(), models.Adjustment{
+		UserID:    userID,
+		Type:      requestData.Type,
+		Amount:    requestData.Amount,
+		Reason:    requestData.Reason,
+		Note:      requestData.Note,
+		CreatedBy: actorID,
+	})
+	if errors.Is(err, adjustments.ErrInvalidType) {
+		problem.Validation(w, r, problem.FieldError{Field: "type", Code: problem.FieldInvalid, Message: "must be CREDIT or DEBIT"})
+		return
+	}
+	if errors.Is(err, adjustments.ErrInvalidReason) {
+		problem.Validation(w, r, problem.FieldError{Field: "reason", Code: problem.FieldInvalid, Message: "unknown reason code"})
+		return
+	}
+	if errors.Is(err, adjustments.ErrInvalidAmount) {
+		problem.Validation(w, r, problem.FieldError{Field: "amount", Code: problem.FieldInvalid, Message: "must be positive"})
+		return
+	}
+	if errors.Is(err, adjustments.ErrSelfAdjustment) {
+		problem.Write(w, r, http.StatusForbidden, problem.CodeSelfAdjustment, "Admin cannot adjust or approve their own balance")
+		return
+	}
+	if errors.Is(err, adjustments.ErrInsufficientFunds) {
+		problem.Write(w, r, http.StatusPaymentRequired, problem.CodeInsufficientFunds, "Not enough money")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot create adjustment")
+		return
+	}
+
+	status := http.StatusCreated
+	if adjustment.Status == models.AdjustmentPending {
+		status = http.StatusAccepted
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(dto.NewAdjustmentResponse(*adjustment)); err != nil {
+		return
+	}
+}
@@ -0,0 +1,68 @@
+package admincreatecampaign
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/campaigns"
+	"net/http"
+)
+
+type Handler struct {
+	campaigns campaigns.Service
+}
+
+func New(campaigns campaigns.Service) *Handler {
+	return &Handler{campaigns: campaigns}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	actorID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.CampaignRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	campaign := requestData.ToModel()
+	campaign.CreatedBy = actorID
+	created, err := h.campaigns.Create(r.Context(), campaign)
+	if fieldErr, ok := campaignFieldError(err); ok {
+		problem.Validation(w, r, fieldErr)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot create campaign")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(dto.NewCampaignResponse(*created)); err != nil {
+		return
+	}
+}
+
+func campaignFieldError(err error) (problem.FieldError, bool) {
+	switch {
+	case errors.Is(err, campaigns.ErrInvalidName):
+		return problem.FieldError{Field: "name", Code: problem.FieldRequired, Message: "must be presented and must be not empty"}, true
+	case errors.Is(err, campaigns.ErrInvalidKind):
+		return problem.FieldError{Field: "kind", Code: problem.FieldInvalid, Message: "must be MULTIPLIER, FIXED or FIRST_ORDER"}, true
+	case errors.Is(err, campaigns.ErrInvalidValue):
+		return problem.FieldError{Field: "value", Code: problem.FieldInvalid, Message: "must be positive, and greater than 1 for MULTIPLIER"}, true
+	case errors.Is(err, campaigns.ErrInvalidPeriod):
+		return problem.FieldError{Field: "ends_at", Code: problem.FieldInvalid, Message: "must be after starts_at"}, true
+	}
+	return problem.FieldError{}, false
+}
@@ -0,0 +1,32 @@
+package admindeletecampaign
+
+import (
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/campaigns"
+	campaignsStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/campaigns"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	campaigns campaigns.Service
+}
+
+func New(campaigns campaigns.Service) *Handler {
+	return &Handler{campaigns: campaigns}
+}
+
+// Handle останавливает кампанию; начисленные по ней бонусы не откатываются
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	err := h.campaigns.Delete(r.Context(), chi.URLParam(r, "campaignID"))
+	if errors.Is(err, campaignsStore.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "Campaign not found")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot delete campaign")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
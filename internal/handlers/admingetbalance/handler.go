@@ -0,0 +1,46 @@
+package admingetbalance
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	balance balance.Service
+}
+
+func New(balance balance.Service) *Handler {
+	return &Handler{balance: balance}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	bal, err := h.balance.GetBalance(r.Context(), userID)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get balance")
+		return
+	}
+
+	withdrawal, err := h.balance.GetSumWithdraw(r.Context(), userID)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get withdraw")
+		return
+	}
+
+	// заполняем модель ответа
+	resp := dto.GetBalanceResponse{
+		Current:   bal,
+		Withdrawn: withdrawal,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
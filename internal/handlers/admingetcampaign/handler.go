@@ -0,0 +1,39 @@
+package admingetcampaign
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/campaigns"
+	campaignsStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/campaigns"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	campaigns campaigns.Service
+}
+
+func New(campaigns campaigns.Service) *Handler {
+	return &Handler{campaigns: campaigns}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	campaign, err := h.campaigns.Get(r.Context(), chi.URLParam(r, "campaignID"))
+	if errors.Is(err, campaignsStore.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "Campaign not found")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get campaign")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(dto.NewCampaignResponse(*campaign)); err != nil {
+		return
+	}
+}
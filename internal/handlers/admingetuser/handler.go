@@ -0,0 +1,47 @@
+package admingetuser
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	usersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	users users.Service
+}
+
+func New(users users.Service) *Handler {
+	return &Handler{users: users}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	user, err := h.users.Get(r.Context(), userID)
+	if errors.Is(err, usersStore.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get user")
+		return
+	}
+
+	resp := dto.AdminUserResponse{
+		ID:          user.UserID,
+		Login:       user.Login,
+		Role:        user.Role,
+		TOTPEnabled: user.TOTPEnabled,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -0,0 +1,57 @@
+package admingetwithdrawals
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	balanceStorage "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"time"
+)
+
+type Handler struct {
+	balance balance.Service
+}
+
+func New(balance balance.Service) *Handler {
+	return &Handler{balance: balance}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	withdrawals, err := h.balance.GetAllWithdrawByUser(r.Context(), userID)
+	if errors.Is(err, balanceStorage.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get withdrawals")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.WithdrawalsResponse
+
+	for _, withdrawal := range *withdrawals {
+		stringDate := withdrawal.ProcessedAt.Format(time.RFC3339)
+		date, _ := time.Parse(time.RFC3339, stringDate)
+		resp = append(resp, dto.WithdrawalsResponse{
+			ID:          withdrawal.ID,
+			OrderNumber: withdrawal.OrderNumber,
+			Sum:         withdrawal.Sum,
+			Reversed:    withdrawal.Reversed,
+			Status:      withdrawal.Status(),
+			ProcessedAt: date,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
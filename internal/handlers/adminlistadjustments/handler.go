@@ -0,0 +1,55 @@
+package adminlistadjustments
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/adjustments"
+	adjustmentsStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/adjustments"
+	"net/http"
+)
+
+type Handler struct {
+	adjustments adjustments.Service
+}
+
+func New(adjustments adjustments.Service) *Handler {
+	return &Handler{adjustments: adjustments}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.AdjustmentPending
+	}
+	if status != models.AdjustmentPending && status != models.AdjustmentApplied && status != models.AdjustmentRejected {
+		problem.Validation(w, r, problem.FieldError{Field: "status", Code: problem.FieldInvalid, Message: "must be PENDING, APPLIED or REJECTED"})
+		return
+	}
+
+	list, err := h.adjustments.GetAllByStatus(r.Context(), status)
+	if errors.Is(err, adjustmentsStore.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get adjustments")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.AdjustmentResponse
+
+	for _, adjustment := range *list {
+		resp = append(resp, dto.NewAdjustmentResponse(adjustment))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
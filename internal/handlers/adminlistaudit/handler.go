@@ -0,0 +1,110 @@
+package adminlistaudit
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/audit"
+	auditStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type Handler struct {
+	audit audit.Service
+}
+
+func New(audit audit.Service) *Handler {
+	return &Handler{audit: audit}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		Type:  query.Get("type"),
+		Limit: defaultLimit,
+	}
+
+	if rawUserID := query.Get("user_id"); rawUserID != "" {
+		userID, err := strconv.ParseInt(rawUserID, 10, 64)
+		if err != nil || userID <= 0 {
+			problem.Validation(w, r, problem.FieldError{Field: "user_id", Code: problem.FieldInvalid, Message: "must be a positive integer"})
+			return
+		}
+		filter.UserID = strconv.FormatInt(userID, 10)
+	}
+
+	if rawFrom := query.Get("from"); rawFrom != "" {
+		from, err := time.Parse(time.RFC3339, rawFrom)
+		if err != nil {
+			problem.Validation(w, r, problem.FieldError{Field: "from", Code: problem.FieldInvalid, Message: "must be RFC3339"})
+			return
+		}
+		filter.From = from
+	}
+	if rawTo := query.Get("to"); rawTo != "" {
+		to, err := time.Parse(time.RFC3339, rawTo)
+		if err != nil {
+			problem.Validation(w, r, problem.FieldError{Field: "to", Code: problem.FieldInvalid, Message: "must be RFC3339"})
+			return
+		}
+		filter.To = to
+	}
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			problem.Validation(w, r, problem.FieldError{Field: "limit", Code: problem.FieldInvalid, Message: "must be a positive integer not greater than the maximum"})
+			return
+		}
+		filter.Limit = limit
+	}
+	if rawOffset := query.Get("offset"); rawOffset != "" {
+		offset, err := strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			problem.Validation(w, r, problem.FieldError{Field: "offset", Code: problem.FieldInvalid, Message: "must be a non-negative integer"})
+			return
+		}
+		filter.Offset = offset
+	}
+
+	events, err := h.audit.Find(r.Context(), filter)
+	if errors.Is(err, auditStore.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get audit events")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.AuditEventResponse
+
+	for _, event := range *events {
+		resp = append(resp, dto.AuditEventResponse{
+			ID:        event.ID,
+			Type:      event.Type,
+			ActorID:   event.ActorID,
+			UserID:    event.UserID,
+			Payload:   event.Payload,
+			CreatedAt: event.CreatedAt,
+			PrevHash:  event.PrevHash,
+			Hash:      event.Hash,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -0,0 +1,45 @@
+package adminlistcampaigns
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/campaigns"
+	campaignsStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/campaigns"
+	"net/http"
+)
+
+type Handler struct {
+	campaigns campaigns.Service
+}
+
+func New(campaigns campaigns.Service) *Handler {
+	return &Handler{campaigns: campaigns}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	list, err := h.campaigns.GetAll(r.Context())
+	if errors.Is(err, campaignsStore.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get campaigns")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.CampaignResponse
+
+	for _, campaign := range *list {
+		resp = append(resp, dto.NewCampaignResponse(campaign))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package adminlistusers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	usersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+type Handler struct {
+	users users.Service
+}
+
+func New(users users.Service) *Handler {
+	return &Handler{users: users}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+
+	limit := defaultLimit
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 || parsed > maxLimit {
+			problem.Validation(w, r, problem.FieldError{Field: "limit", Code: problem.FieldInvalid, Message: "must be a positive integer not greater than the maximum"})
+			return
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if rawOffset := r.URL.Query().Get("offset"); rawOffset != "" {
+		parsed, err := strconv.Atoi(rawOffset)
+		if err != nil || parsed < 0 {
+			problem.Validation(w, r, problem.FieldError{Field: "offset", Code: problem.FieldInvalid, Message: "must be a non-negative integer"})
+			return
+		}
+		offset = parsed
+	}
+
+	list, err := h.users.List(r.Context(), query, limit, offset)
+	if errors.Is(err, usersStore.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get users")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.AdminUserResponse
+
+	for _, user := range *list {
+		resp = append(resp, dto.AdminUserResponse{
+			ID:          user.UserID,
+			Login:       user.Login,
+			Role:        user.Role,
+			TOTPEnabled: user.TOTPEnabled,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
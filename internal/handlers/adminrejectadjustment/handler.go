@@ -0,0 +1,55 @@
+package adminrejectadjustment
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/adjustments"
+	adjustmentsStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/adjustments"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	adjustments adjustments.Service
+}
+
+func New(adjustments adjustments.Service) *Handler {
+	return &Handler{adjustments: adjustments}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	actorID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	adjustmentID := chi.URLParam(r, "adjustmentID")
+
+	adjustment, err := h.adjustments.Reject(r.Context(), adjustmentID, actorID)
+	if errors.Is(err, adjustmentsStore.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "Adjustment not found")
+		return
+	}
+	if errors.Is(err, adjustments.ErrNotPending) {
+		problem.Write(w, r, http.StatusConflict, problem.CodeNotPending, "Adjustment is not pending")
+		return
+	}
+	if errors.Is(err, adjustments.ErrSameApprover) {
+		problem.Write(w, r, http.StatusForbidden, problem.CodeSameApprover, "Adjustment must be approved by another admin")
+		return
+	}
+	if errors.Is(err, adjustments.ErrSelfAdjustment) {
+		problem.Write(w, r, http.StatusForbidden, problem.CodeSelfAdjustment, "Admin cannot adjust or approve their own balance")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot reject adjustment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(dto.NewAdjustmentResponse(*adjustment)); err != nil {
+		return
+	}
+}
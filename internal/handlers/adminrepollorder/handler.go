@@ -0,0 +1,36 @@
+package adminrepollorder
+
+import (
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
+	orders2 "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	order orders.Service
+}
+
+func New(order orders.Service) *Handler {
+	return &Handler{order: order}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "number")
+	err := h.order.Repoll(r.Context(), orderID)
+	if errors.Is(err, orders2.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "Order not found")
+		return
+	}
+	if errors.Is(err, orders.ErrAlreadyProcessed) {
+		problem.Write(w, r, http.StatusConflict, problem.CodeOrderProcessed, "Order already processed")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot re-poll order")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,63 @@
+package adminreversewithdrawal
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	balance balance.Service
+}
+
+func New(balance balance.Service) *Handler {
+	return &Handler{balance: balance}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	actorID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.ReversalRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	reversal, err := h.balance.ReverseWithdrawal(r.Context(), models.Reversal{
+		WithdrawalID: chi.URLParam(r, "withdrawalID"),
+		Sum:          requestData.Sum,
+		Reason:       requestData.Reason,
+		CreatedBy:    actorID,
+	})
+	if errors.Is(err, balance.ErrInvalidSum) {
+		problem.Validation(w, r, problem.FieldError{Field: "sum", Code: problem.FieldInvalid, Message: "must not be negative"})
+		return
+	}
+	if errors.Is(err, balance.ErrInvalidReason) {
+		problem.Validation(w, r, problem.FieldError{Field: "reason", Code: problem.FieldRequired, Message: "reason is required"})
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot reverse withdrawal")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(dto.NewReversalResponse(*reversal)); err != nil {
+		return
+	}
+}
@@ -0,0 +1,51 @@
+package adminsetrole
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	usersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	users users.Service
+}
+
+func New(users users.Service) *Handler {
+	return &Handler{users: users}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.SetRoleRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+	err := h.users.SetRole(r.Context(), userID, requestData.Role)
+	if errors.Is(err, users.ErrInvalidRole) {
+		problem.Validation(w, r, problem.FieldError{Field: "role", Code: problem.FieldInvalid, Message: "must be one of user, support, admin"})
+		return
+	}
+	if errors.Is(err, usersStore.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot set role")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,72 @@
+package adminupdatecampaign
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/campaigns"
+	campaignsStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/campaigns"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	campaigns campaigns.Service
+}
+
+func New(campaigns campaigns.Service) *Handler {
+	return &Handler{campaigns: campaigns}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.CampaignRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	campaign := requestData.ToModel()
+	campaign.ID = chi.URLParam(r, "campaignID")
+	updated, err := h.campaigns.Update(r.Context(), campaign)
+	if fieldErr, ok := campaignFieldError(err); ok {
+		problem.Validation(w, r, fieldErr)
+		return
+	}
+	if errors.Is(err, campaignsStore.ErrNotFound) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "Campaign not found")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot update campaign")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(dto.NewCampaignResponse(*updated)); err != nil {
+		return
+	}
+}
+
+func campaignFieldError(err error) (problem.FieldError, bool) {
+	switch {
+	case errors.Is(err, campaigns.ErrInvalidName):
+		return problem.FieldError{Field: "name", Code: problem.FieldRequired, Message: "must be presented and must be not empty"}, true
+	case errors.Is(err, campaigns.ErrInvalidKind):
+		return problem.FieldError{Field: "kind", Code: problem.FieldInvalid, Message: "must be MULTIPLIER, FIXED or FIRST_ORDER"}, true
+	case errors.Is(err, campaigns.ErrInvalidValue):
+		return problem.FieldError{Field: "value", Code: problem.FieldInvalid, Message: "must be positive, and greater than 1 for MULTIPLIER"}, true
+	case errors.Is(err, campaigns.ErrInvalidPeriod):
+		return problem.FieldError{Field: "ends_at", Code: problem.FieldInvalid, Message: "must be after starts_at"}, true
+	}
+	return problem.FieldError{}, false
+}
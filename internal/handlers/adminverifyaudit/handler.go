@@ -0,0 +1,38 @@
+package adminverifyaudit
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/audit"
+	"net/http"
+)
+
+type Handler struct {
+	audit audit.Service
+}
+
+func New(audit audit.Service) *Handler {
+	return &Handler{audit: audit}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	verification, err := h.audit.Verify(r.Context())
+	if err != nil {
+		problem.Error(w, r, err, "Cannot verify audit log")
+		return
+	}
+
+	resp := dto.AuditVerificationResponse{
+		Checked:  verification.Checked,
+		Valid:    verification.Valid,
+		BrokenAt: verification.BrokenAt,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -0,0 +1,26 @@
+package canceldeletion
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	"net/http"
+)
+
+type Handler struct {
+	users users.Service
+}
+
+func New(users users.Service) *Handler {
+	return &Handler{users: users}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	err := h.users.CancelDeletion(r.Context(), userID)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot cancel account deletion")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,38 @@
+package capturehold
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	balance balance.Service
+}
+
+func New(balance balance.Service) *Handler {
+	return &Handler{balance: balance}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	hold, err := h.balance.CaptureHold(r.Context(), userID, chi.URLParam(r, "holdID"))
+	if err != nil {
+		problem.Error(w, r, err, "Cannot capture hold")
+		return
+	}
+
+	// заполняем модель ответа
+	resp := dto.NewHoldResponse(*hold)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -0,0 +1,62 @@
+package confirmtotp
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	"net/http"
+)
+
+type Handler struct {
+	users users.Service
+}
+
+func New(users users.Service) *Handler {
+	return &Handler{users: users}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.TOTPCodeRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	codes, err := h.users.ConfirmTOTP(r.Context(), userID, requestData.Code)
+	if errors.Is(err, users.ErrTOTPAlreadyEnabled) {
+		problem.Write(w, r, http.StatusConflict, problem.CodeTOTPAlreadyEnabled, "Two-factor authentication already enabled")
+		return
+	}
+	if errors.Is(err, users.ErrTOTPNotEnrolled) {
+		problem.Write(w, r, http.StatusConflict, problem.CodeTOTPNotEnrolled, "Two-factor authentication is not enrolled")
+		return
+	}
+	if errors.Is(err, users.ErrInvalidCode) {
+		problem.Write(w, r, http.StatusUnprocessableEntity, problem.CodeInvalidSecondFactor, "Invalid two-factor code")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot confirm two-factor authentication")
+		return
+	}
+
+	resp := dto.TOTPRecoveryCodesResponse{RecoveryCodes: codes}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
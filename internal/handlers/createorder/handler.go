@@ -3,6 +3,7 @@ package createorder
 import (
 	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
 	"io"
 	"net/http"
@@ -19,13 +20,13 @@ func New(order orders.Service) *Handler {
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	contentType := r.Header.Get("Content-Type")
 	if contentType != "text/plain" {
-		http.Error(w, "Invalid request content type", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
 		return
 	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Incorrect input json", http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
 		return
 	}
 
@@ -33,15 +34,16 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
 	err = h.order.Add(r.Context(), orderID, userID)
 	if errors.Is(err, orders.ErrLuhn) {
-		http.Error(w, "Invalid order number", http.StatusUnprocessableEntity)
+		problem.Write(w, r, http.StatusUnprocessableEntity, problem.CodeInvalidOrderNumber, "Invalid order number")
 		return
 	}
 	if errors.Is(err, orders.ErrOrderAnotherUser) {
-		http.Error(w, "Order created by another user", http.StatusConflict)
+		problem.Write(w, r, http.StatusConflict, problem.CodeOrderAnotherUser, "Order created by another user")
 		return
 	}
 	if errors.Is(err, orders.ErrDuplicate) {
-		http.Error(w, "Duplicate order", http.StatusOK)
+		// заказ уже загружен этим пользователем — это не ошибка
+		w.WriteHeader(http.StatusOK)
 		return
 	}
 	w.WriteHeader(http.StatusAccepted)
@@ -0,0 +1,80 @@
+package createtransfer
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	"net/http"
+)
+
+type Handler struct {
+	balance       balance.Service
+	users         users.Service
+	totpThreshold float64
+}
+
+func New(balance balance.Service, users users.Service, totpThreshold float64) *Handler {
+	return &Handler{balance: balance, users: users, totpThreshold: totpThreshold}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.TransferRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	var fieldErrors []problem.FieldError
+	if requestData.To == "" {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "to", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
+	}
+	if requestData.Sum <= 0 {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "sum", Code: problem.FieldInvalid, Message: "must be positive"})
+	}
+	if len(fieldErrors) > 0 {
+		problem.Validation(w, r, fieldErrors...)
+		return
+	}
+
+	err := h.users.RequireSecondFactor(r.Context(), userID, requestData.Sum, h.totpThreshold, requestData.Code)
+	if errors.Is(err, users.ErrSecondFactorRequired) {
+		problem.Write(w, r, http.StatusForbidden, problem.CodeSecondFactorRequired, "Two-factor code required")
+		return
+	}
+	if errors.Is(err, users.ErrInvalidCode) {
+		problem.Write(w, r, http.StatusForbidden, problem.CodeInvalidSecondFactor, "Invalid two-factor code")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot verify two-factor code")
+		return
+	}
+
+	transfer, err := h.balance.Transfer(r.Context(), userID, requestData.To, requestData.Sum)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot transfer points")
+		return
+	}
+
+	// заполняем модель ответа
+	resp := dto.NewTransferResponse(*transfer, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -0,0 +1,69 @@
+package createwebhook
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/webhooks"
+	"net/http"
+)
+
+type Handler struct {
+	webhooks webhooks.Service
+}
+
+func New(webhooks webhooks.Service) *Handler {
+	return &Handler{webhooks: webhooks}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.CreateWebhookRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	webhook, err := h.webhooks.Create(r.Context(), models.Webhook{
+		UserID: userID,
+		URL:    requestData.URL,
+		Events: requestData.Events,
+		Secret: requestData.Secret,
+	})
+	if errors.Is(err, webhooks.ErrInvalidURL) {
+		problem.Validation(w, r, problem.FieldError{Field: "url", Code: problem.FieldInvalid, Message: "must be an absolute http or https url of a public host"})
+		return
+	}
+	if errors.Is(err, webhooks.ErrInvalidEvents) {
+		problem.Validation(w, r, problem.FieldError{Field: "events", Code: problem.FieldInvalid, Message: "unknown event type"})
+		return
+	}
+	if errors.Is(err, webhooks.ErrInvalidSecret) {
+		problem.Validation(w, r, problem.FieldError{Field: "secret", Code: problem.FieldInvalid, Message: "must be at least 16 characters"})
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot create webhook")
+		return
+	}
+
+	resp := dto.NewWebhookResponse(*webhook)
+	resp.Secret = webhook.Secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -4,68 +4,85 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
-	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
 	"io"
 	"net/http"
 )
 
 type Handler struct {
-	orders  orders.Service
-	balance balance.Service
+	orders        orders.Service
+	balance       balance.Service
+	users         users.Service
+	totpThreshold float64
 }
 
-func New(orders orders.Service, balance balance.Service) *Handler {
-	return &Handler{orders: orders, balance: balance}
+func New(orders orders.Service, balance balance.Service, users users.Service, totpThreshold float64) *Handler {
+	return &Handler{orders: orders, balance: balance, users: users, totpThreshold: totpThreshold}
 }
 
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
 	contentType := r.Header.Get("Content-Type")
 	if contentType != "application/json" {
-		http.Error(w, "Invalid request content type", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
 		return
 	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Incorrect input json", http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
 		return
 	}
 
 	requestData := &dto.WithdrawalRequest{}
 	err = json.Unmarshal(body, requestData)
 	if err != nil {
-		http.Error(w, "Incorrect input json", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	err = h.users.RequireSecondFactor(r.Context(), userID, requestData.Sum, h.totpThreshold, requestData.Code)
+	if errors.Is(err, users.ErrSecondFactorRequired) {
+		problem.Write(w, r, http.StatusForbidden, problem.CodeSecondFactorRequired, "Two-factor code required")
+		return
+	}
+	if errors.Is(err, users.ErrInvalidCode) {
+		problem.Write(w, r, http.StatusForbidden, problem.CodeInvalidSecondFactor, "Invalid two-factor code")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot verify two-factor code")
 		return
 	}
 
 	err = h.orders.Add(r.Context(), requestData.Number, userID)
 	if errors.Is(err, orders.ErrLuhn) {
-		http.Error(w, "Invalid order number", http.StatusUnprocessableEntity)
+		problem.Write(w, r, http.StatusUnprocessableEntity, problem.CodeInvalidOrderNumber, "Invalid order number")
 		return
 	}
 	if errors.Is(err, orders.ErrOrderAnotherUser) {
-		http.Error(w, "Order created by another user", http.StatusConflict)
+		problem.Write(w, r, http.StatusConflict, problem.CodeOrderAnotherUser, "Order created by another user")
 		return
 	}
 	if errors.Is(err, orders.ErrDuplicate) {
-		http.Error(w, "Duplicate order", http.StatusOK)
+		// заказ уже загружен этим пользователем — это не ошибка
+		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	ok, err := h.balance.CanWithdraw(r.Context(), requestData.Sum, userID)
 	if err != nil {
-		logger.Log().Sugar().Infow("Can withdraw", err)
-		http.Error(w, "Cannot can withdraw", http.StatusInternalServerError)
+		problem.Error(w, r, err, "Cannot can withdraw")
 		return
 	}
 
 	if !ok {
-		http.Error(w, "Not enough money", http.StatusPaymentRequired)
+		problem.Write(w, r, http.StatusPaymentRequired, problem.CodeInsufficientFunds, "Not enough money")
 		return
 	}
 
@@ -74,9 +91,12 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		Sum:         requestData.Sum,
 	}
 	err = h.balance.AddWithdraw(r.Context(), withdrawal, userID)
+	if errors.Is(err, balance.ErrInsufficientFunds) {
+		problem.Write(w, r, http.StatusPaymentRequired, problem.CodeInsufficientFunds, "Not enough money")
+		return
+	}
 	if err != nil {
-		logger.Log().Sugar().Infow("add withdraw", err)
-		http.Error(w, "Cannot add withdraw", http.StatusInternalServerError)
+		problem.Error(w, r, err, "Cannot add withdraw")
 		return
 	}
 }
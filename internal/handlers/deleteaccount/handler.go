@@ -0,0 +1,35 @@
+package deleteaccount
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	"net/http"
+)
+
+type Handler struct {
+	users users.Service
+}
+
+func New(users users.Service) *Handler {
+	return &Handler{users: users}
+}
+
+// Handle только назначает удаление: аккаунт обезличится по истечении срока, до тех пор запрос можно отменить
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	deletion, err := h.users.RequestDeletion(r.Context(), userID)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot request account deletion")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(dto.NewAccountDeletionResponse(*deletion)); err != nil {
+		return
+	}
+}
@@ -0,0 +1,27 @@
+package deletewebhook
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/webhooks"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	webhooks webhooks.Service
+}
+
+func New(webhooks webhooks.Service) *Handler {
+	return &Handler{webhooks: webhooks}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	err := h.webhooks.Delete(r.Context(), userID, chi.URLParam(r, "webhookID"))
+	if err != nil {
+		problem.Error(w, r, err, "Cannot delete webhook")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
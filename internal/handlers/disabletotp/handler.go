@@ -0,0 +1,50 @@
+package disabletotp
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	"net/http"
+)
+
+type Handler struct {
+	users users.Service
+}
+
+func New(users users.Service) *Handler {
+	return &Handler{users: users}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+		return
+	}
+
+	requestData := &dto.TOTPCodeRequest{}
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+		return
+	}
+
+	err := h.users.DisableTOTP(r.Context(), userID, requestData.Code)
+	if errors.Is(err, users.ErrTOTPNotEnabled) {
+		problem.Write(w, r, http.StatusConflict, problem.CodeTOTPNotEnabled, "Two-factor authentication is not enabled")
+		return
+	}
+	if errors.Is(err, users.ErrInvalidCode) {
+		problem.Write(w, r, http.StatusUnprocessableEntity, problem.CodeInvalidSecondFactor, "Invalid two-factor code")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot disable two-factor authentication")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
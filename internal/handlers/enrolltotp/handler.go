@@ -0,0 +1,44 @@
+package enrolltotp
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	"net/http"
+)
+
+type Handler struct {
+	users users.Service
+}
+
+func New(users users.Service) *Handler {
+	return &Handler{users: users}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	enrollment, err := h.users.EnrollTOTP(r.Context(), userID)
+	if errors.Is(err, users.ErrTOTPAlreadyEnabled) {
+		problem.Write(w, r, http.StatusConflict, problem.CodeTOTPAlreadyEnabled, "Two-factor authentication already enabled")
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot enroll two-factor authentication")
+		return
+	}
+
+	resp := dto.TOTPEnrollResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -0,0 +1,136 @@
+package exportdata
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/privacy"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+const (
+	formatJSON = "json"
+	formatZIP  = "zip"
+)
+
+type Handler struct {
+	privacy privacy.Service
+}
+
+func New(privacy privacy.Service) *Handler {
+	return &Handler{privacy: privacy}
+}
+
+// Handle отдаёт все данные пользователя: JSON по умолчанию, ZIP при format=zip или Accept: application/zip.
+// В архиве export.json с тем же содержимым и CSV заказов, списаний и истории баланса.
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	format, ok := negotiate(r)
+	if !ok {
+		problem.Validation(w, r, problem.FieldError{Field: "format", Code: problem.FieldInvalid, Message: "must be json or zip"})
+		return
+	}
+
+	data, err := h.privacy.Export(r.Context(), userID)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot export user data")
+		return
+	}
+
+	// заполняем модель ответа
+	resp := dto.NewDataExportResponse(*data)
+
+	if format == formatJSON {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="gophermart-export-`+data.GeneratedAt.UTC().Format("20060102")+`.zip"`)
+	w.WriteHeader(http.StatusOK)
+	if err := writeZIP(w, *data, resp); err != nil {
+		// статус уже ушёл; обрываем соединение, чтобы клиент не принял обрезанный архив за целый
+		logger.FromContext(r.Context()).Error("User data export interrupted", zap.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func negotiate(r *http.Request) (string, bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case formatJSON, formatZIP:
+		return format, true
+	case "":
+	default:
+		return "", false
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		if strings.TrimSpace(mediaType) == "application/zip" {
+			return formatZIP, true
+		}
+	}
+	return formatJSON, true
+}
+
+func writeZIP(w http.ResponseWriter, data models.DataExport, resp dto.DataExportResponse) error {
+	archive := zip.NewWriter(w)
+
+	file, err := archive.Create("export.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(resp); err != nil {
+		return err
+	}
+
+	orders := make([][]string, 0, len(data.Orders))
+	for _, order := range data.Orders {
+		orders = append(orders, dto.OrderCSVRecord(order))
+	}
+	withdrawals := make([][]string, 0, len(data.Withdrawals))
+	for _, withdrawal := range data.Withdrawals {
+		withdrawals = append(withdrawals, dto.WithdrawalCSVRecord(withdrawal))
+	}
+	statement := make([][]string, 0, len(data.Statement))
+	for _, entry := range data.Statement {
+		statement = append(statement, dto.StatementCSVRecord(entry))
+	}
+
+	for _, table := range []struct {
+		name    string
+		header  []string
+		records [][]string
+	}{
+		{"orders.csv", dto.OrderCSVHeader, orders},
+		{"withdrawals.csv", dto.WithdrawalCSVHeader, withdrawals},
+		{"statement.csv", dto.StatementCSVHeader, statement},
+	} {
+		file, err = archive.Create(table.name)
+		if err != nil {
+			return err
+		}
+		writer := csv.NewWriter(file)
+		if err = writer.Write(table.header); err != nil {
+			return err
+		}
+		if err = writer.WriteAll(table.records); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
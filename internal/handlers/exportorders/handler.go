@@ -0,0 +1,55 @@
+package exportorders
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/export"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type Handler struct {
+	order orders.Service
+}
+
+func New(order orders.Service) *Handler {
+	return &Handler{order: order}
+}
+
+// Handle выгружает заказы пользователя в CSV или NDJSON, строка за строкой из курсора базы
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	format, err := export.Negotiate(r)
+	if err != nil {
+		problem.Validation(w, r, problem.FieldError{Field: "format", Code: problem.FieldInvalid, Message: "must be csv or ndjson"})
+		return
+	}
+
+	writer := export.NewWriter(w, format, "orders", dto.OrderCSVHeader)
+	err = h.order.ExportByUser(r.Context(), userID, func(order models.Order) error {
+		return writer.Write(dto.OrderCSVRecord(order), dto.GetOrdersResponse{
+			Number:     order.Number,
+			Status:     order.Status,
+			Accrual:    order.Accrual,
+			UploadedAt: order.UploadedAt.Truncate(time.Second),
+		})
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+	if !writer.Started() {
+		problem.Error(w, r, err, "Cannot export orders")
+		return
+	}
+	// статус уже ушёл; обрываем соединение, чтобы клиент не принял обрезанный файл за целый
+	logger.FromContext(r.Context()).Error("Orders export interrupted", zap.Error(err))
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,57 @@
+package exportwithdrawals
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/export"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type Handler struct {
+	balance balance.Service
+}
+
+func New(balance balance.Service) *Handler {
+	return &Handler{balance: balance}
+}
+
+// Handle выгружает списания пользователя в CSV или NDJSON, строка за строкой из курсора базы
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	format, err := export.Negotiate(r)
+	if err != nil {
+		problem.Validation(w, r, problem.FieldError{Field: "format", Code: problem.FieldInvalid, Message: "must be csv or ndjson"})
+		return
+	}
+
+	writer := export.NewWriter(w, format, "withdrawals", dto.WithdrawalCSVHeader)
+	err = h.balance.ExportWithdrawByUser(r.Context(), userID, func(withdrawal models.Withdrawal) error {
+		return writer.Write(dto.WithdrawalCSVRecord(withdrawal), dto.WithdrawalsResponse{
+			ID:          withdrawal.ID,
+			OrderNumber: withdrawal.OrderNumber,
+			Sum:         withdrawal.Sum,
+			Reversed:    withdrawal.Reversed,
+			Status:      withdrawal.Status(),
+			ProcessedAt: withdrawal.ProcessedAt.Truncate(time.Second),
+		})
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+	if !writer.Started() {
+		problem.Error(w, r, err, "Cannot export withdrawals")
+		return
+	}
+	// статус уже ушёл; обрываем соединение, чтобы клиент не принял обрезанный файл за целый
+	logger.FromContext(r.Context()).Error("Withdrawals export interrupted", zap.Error(err))
+	panic(http.ErrAbortHandler)
+}
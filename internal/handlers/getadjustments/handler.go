@@ -0,0 +1,55 @@
+package getadjustments
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/adjustments"
+	adjustmentsStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/adjustments"
+	"net/http"
+	"time"
+)
+
+type Handler struct {
+	adjustments adjustments.Service
+}
+
+func New(adjustments adjustments.Service) *Handler {
+	return &Handler{adjustments: adjustments}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	list, err := h.adjustments.GetAllAppliedByUser(r.Context(), userID)
+	if errors.Is(err, adjustmentsStore.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get adjustments")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.UserAdjustmentResponse
+
+	for _, adjustment := range *list {
+		stringDate := adjustment.DecidedAt.Format(time.RFC3339)
+		date, _ := time.Parse(time.RFC3339, stringDate)
+		resp = append(resp, dto.UserAdjustmentResponse{
+			Type:        adjustment.Type,
+			Amount:      adjustment.Amount,
+			Reason:      adjustment.Reason,
+			ProcessedAt: date,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
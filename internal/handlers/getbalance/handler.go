@@ -2,38 +2,68 @@ package getbalance
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/tiers"
+	balanceStorage "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"math"
 	"net/http"
 )
 
 type Handler struct {
 	balance balance.Service
+	tiers   tiers.Service
 }
 
-func New(balance balance.Service) *Handler {
-	return &Handler{balance: balance}
+func New(balance balance.Service, tiers tiers.Service) *Handler {
+	return &Handler{balance: balance, tiers: tiers}
 }
 
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
 	bal, err := h.balance.GetBalance(r.Context(), userID)
 	if err != nil {
-		http.Error(w, "Cannot get balance", http.StatusInternalServerError)
+		problem.Error(w, r, err, "Cannot get balance")
+		return
+	}
+
+	held, err := h.balance.GetHeld(r.Context(), userID)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get held points")
 		return
 	}
 
 	withdrawal, err := h.balance.GetSumWithdraw(r.Context(), userID)
 	if err != nil {
-		http.Error(w, "Cannot get withdraw", http.StatusInternalServerError)
+		problem.Error(w, r, err, "Cannot get withdraw")
+		return
+	}
+
+	expiry, err := h.balance.GetNextExpiry(r.Context(), userID)
+	if err != nil && !errors.Is(err, balanceStorage.ErrNotFound) {
+		problem.Error(w, r, err, "Cannot get expiry")
+		return
+	}
+
+	tier, err := h.tiers.GetStatus(r.Context(), userID)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get tier")
 		return
 	}
 
 	// заполняем модель ответа
 	resp := dto.GetBalanceResponse{
 		Current:   bal,
+		Held:      held,
+		Available: math.Max(bal-held, 0),
 		Withdrawn: withdrawal,
+		Tier:      dto.NewTierResponse(*tier),
+	}
+	if expiry != nil {
+		resp.Expiring = &dto.ExpiringResponse{Sum: expiry.Sum, ExpiresAt: expiry.ExpiresAt}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
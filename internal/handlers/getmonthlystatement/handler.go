@@ -0,0 +1,63 @@
+package getmonthlystatement
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/statement"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"time"
+)
+
+type Handler struct {
+	statement statement.Service
+}
+
+func New(statement statement.Service) *Handler {
+	return &Handler{statement: statement}
+}
+
+// Handle отдаёт выписку за месяц: JSON по умолчанию или CSV при format=csv.
+// Выписка собирается при каждом запросе, заранее ничего не готовится.
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	month, err := time.Parse(dto.MonthLayout, chi.URLParam(r, "month"))
+	if err != nil {
+		problem.Validation(w, r, problem.FieldError{Field: "month", Code: problem.FieldInvalid, Message: "must be YYYY-MM"})
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		problem.Validation(w, r, problem.FieldError{Field: "format", Code: problem.FieldInvalid, Message: "must be json or csv"})
+		return
+	}
+
+	monthly, err := h.statement.GetMonthly(r.Context(), userID, month)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get statement")
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="statement-`+monthly.Month.Format(dto.MonthLayout)+`.csv"`)
+		w.WriteHeader(http.StatusOK)
+		writer := csv.NewWriter(w)
+		_ = writer.Write(dto.StatementCSVHeader)
+		for _, entry := range monthly.Entries {
+			_ = writer.Write(dto.StatementCSVRecord(entry))
+		}
+		writer.Flush()
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(dto.NewMonthlyStatementResponse(*monthly)); err != nil {
+		return
+	}
+}
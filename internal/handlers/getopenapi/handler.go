@@ -0,0 +1,18 @@
+package getopenapi
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/api"
+	"net/http"
+)
+
+type Handler struct{}
+
+func New() *Handler {
+	return &Handler{}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(api.Spec)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
 	orders2 "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
 	"net/http"
@@ -27,7 +28,7 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err != nil {
-		http.Error(w, "Cannot get orders", http.StatusInternalServerError)
+		problem.Error(w, r, err, "Cannot get orders")
 		return
 	}
 
@@ -0,0 +1,98 @@
+package getstatement
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/statement"
+	balanceStorage "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type Handler struct {
+	statement statement.Service
+}
+
+func New(statement statement.Service) *Handler {
+	return &Handler{statement: statement}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	query := r.URL.Query()
+	filter := models.StatementFilter{
+		Types: query["type"],
+		Limit: defaultLimit,
+	}
+
+	if rawFrom := query.Get("from"); rawFrom != "" {
+		from, err := time.Parse(time.RFC3339, rawFrom)
+		if err != nil {
+			problem.Validation(w, r, problem.FieldError{Field: "from", Code: problem.FieldInvalid, Message: "must be RFC3339"})
+			return
+		}
+		filter.From = from
+	}
+	if rawTo := query.Get("to"); rawTo != "" {
+		to, err := time.Parse(time.RFC3339, rawTo)
+		if err != nil {
+			problem.Validation(w, r, problem.FieldError{Field: "to", Code: problem.FieldInvalid, Message: "must be RFC3339"})
+			return
+		}
+		filter.To = to
+	}
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			problem.Validation(w, r, problem.FieldError{Field: "limit", Code: problem.FieldInvalid, Message: "must be a positive integer not greater than the maximum"})
+			return
+		}
+		filter.Limit = limit
+	}
+	if rawOffset := query.Get("offset"); rawOffset != "" {
+		offset, err := strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			problem.Validation(w, r, problem.FieldError{Field: "offset", Code: problem.FieldInvalid, Message: "must be a non-negative integer"})
+			return
+		}
+		filter.Offset = offset
+	}
+
+	entries, err := h.statement.Get(r.Context(), userID, filter)
+	if errors.Is(err, statement.ErrInvalidType) {
+		problem.Validation(w, r, problem.FieldError{Field: "type", Code: problem.FieldInvalid, Message: "unknown statement entry type"})
+		return
+	}
+	if errors.Is(err, balanceStorage.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get statement")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.StatementEntryResponse
+
+	for _, entry := range *entries {
+		resp = append(resp, dto.NewStatementEntryResponse(entry))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
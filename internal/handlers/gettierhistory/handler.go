@@ -0,0 +1,52 @@
+package gettierhistory
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/tiers"
+	tiersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/tiers"
+	"net/http"
+)
+
+type Handler struct {
+	tiers tiers.Service
+}
+
+func New(tiers tiers.Service) *Handler {
+	return &Handler{tiers: tiers}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	history, err := h.tiers.GetHistory(r.Context(), userID)
+	if errors.Is(err, tiersStore.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get tier history")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.TierChangeResponse
+
+	for _, change := range *history {
+		resp = append(resp, dto.TierChangeResponse{
+			From:      change.From,
+			To:        change.To,
+			Spend:     change.Spend,
+			ChangedAt: change.ChangedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
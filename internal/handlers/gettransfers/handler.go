@@ -0,0 +1,47 @@
+package gettransfers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	balanceStorage "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"net/http"
+)
+
+type Handler struct {
+	balance balance.Service
+}
+
+func New(balance balance.Service) *Handler {
+	return &Handler{balance: balance}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	transfers, err := h.balance.GetTransfers(r.Context(), userID)
+	if errors.Is(err, balanceStorage.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get transfers")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.TransferResponse
+
+	for _, transfer := range *transfers {
+		resp = append(resp, dto.NewTransferResponse(transfer, userID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -0,0 +1,70 @@
+package getwebhookdeliveries
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/webhooks"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+type Handler struct {
+	webhooks webhooks.Service
+}
+
+func New(webhooks webhooks.Service) *Handler {
+	return &Handler{webhooks: webhooks}
+}
+
+// Handle отдаёт лог доставок вебхука, новые сверху
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+
+	limit := defaultLimit
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 || parsed > maxLimit {
+			problem.Validation(w, r, problem.FieldError{Field: "limit", Code: problem.FieldInvalid, Message: "must be a positive integer not greater than the maximum"})
+			return
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if rawOffset := r.URL.Query().Get("offset"); rawOffset != "" {
+		parsed, err := strconv.Atoi(rawOffset)
+		if err != nil || parsed < 0 {
+			problem.Validation(w, r, problem.FieldError{Field: "offset", Code: problem.FieldInvalid, Message: "must be a non-negative integer"})
+			return
+		}
+		offset = parsed
+	}
+
+	deliveries, err := h.webhooks.GetDeliveries(r.Context(), userID, chi.URLParam(r, "webhookID"), limit, offset)
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get webhook deliveries")
+		return
+	}
+
+	// заполняем модель ответа
+	resp := make([]dto.WebhookDeliveryResponse, 0, len(*deliveries))
+
+	for _, delivery := range *deliveries {
+		resp = append(resp, dto.NewWebhookDeliveryResponse(delivery))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -0,0 +1,47 @@
+package getwebhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/webhooks"
+	webhooksStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/webhooks"
+	"net/http"
+)
+
+type Handler struct {
+	webhooks webhooks.Service
+}
+
+func New(webhooks webhooks.Service) *Handler {
+	return &Handler{webhooks: webhooks}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	list, err := h.webhooks.GetAllByUser(r.Context(), userID)
+	if errors.Is(err, webhooksStore.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot get webhooks")
+		return
+	}
+
+	// заполняем модель ответа
+	var resp []dto.WebhookResponse
+
+	for _, webhook := range *list {
+		resp = append(resp, dto.NewWebhookResponse(webhook))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
 	balanceStorage "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
 	"net/http"
@@ -28,7 +29,7 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err != nil {
-		http.Error(w, "Cannot get withdrawals", http.StatusInternalServerError)
+		problem.Error(w, r, err, "Cannot get withdrawals")
 		return
 	}
 
@@ -39,8 +40,11 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		stringDate := withdrawal.ProcessedAt.Format(time.RFC3339)
 		date, _ := time.Parse(time.RFC3339, stringDate)
 		resp = append(resp, dto.WithdrawalsResponse{
+			ID:          withdrawal.ID,
 			OrderNumber: withdrawal.OrderNumber,
 			Sum:         withdrawal.Sum,
+			Reversed:    withdrawal.Reversed,
+			Status:      withdrawal.Status(),
 			ProcessedAt: date,
 		})
 	}
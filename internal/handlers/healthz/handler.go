@@ -0,0 +1,29 @@
+package healthz
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/health"
+	"net/http"
+)
+
+type Handler struct {
+	checker *health.Checker
+}
+
+func New(checker *health.Checker) *Handler {
+	return &Handler{checker: checker}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	// заполняем модель ответа
+	resp := dto.NewHealthResponse(h.checker.Live())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
@@ -2,9 +2,11 @@ package login
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
 	"net/http"
 )
@@ -20,14 +22,14 @@ func New(users users.Service) *Handler {
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	contentType := r.Header.Get("Content-Type")
 	if contentType != "application/json" {
-		http.Error(w, "Invalid request content type", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
 		return
 	}
 
 	requestData := &dto.LoginUserRequest{}
 	dec := json.NewDecoder(r.Body)
 	if err := dec.Decode(&requestData); err != nil {
-		http.Error(w, "Incorrect input json", http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
 		return
 	}
 	user := models.User{
@@ -35,25 +37,39 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		Password: requestData.Password,
 	}
 
+	var fieldErrors []problem.FieldError
 	if !user.IsValidLogin() {
-		http.Error(w, "Invalid request login: must be presented and must be not empty", http.StatusBadRequest)
-		return
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "login", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
 	}
-
 	if !user.IsValidPass() {
-		http.Error(w, "Invalid request password: must be presented and must be not empty", http.StatusBadRequest)
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "password", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
+	}
+	if len(fieldErrors) > 0 {
+		problem.Validation(w, r, fieldErrors...)
 		return
 	}
 
-	login, err := h.users.Login(r.Context(), user)
+	login, err := h.users.Login(r.Context(), user, requestData.Code)
+	if errors.Is(err, users.ErrSecondFactorRequired) {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeSecondFactorRequired, "Two-factor code required")
+		return
+	}
+	if errors.Is(err, users.ErrInvalidCode) {
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeInvalidSecondFactor, "Invalid two-factor code")
+		return
+	}
+	if errors.Is(err, users.ErrSecondFactorLocked) {
+		problem.Error(w, r, err, "Cannot verify two-factor code")
+		return
+	}
 	if err != nil {
-		http.Error(w, "Incorrect login/password", http.StatusUnauthorized)
+		problem.Write(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Incorrect login/password")
 		return
 	}
 
-	JWTToken, err := middlewares.BuildJWTString(login.UserID)
+	JWTToken, err := middlewares.BuildJWTString(login.UserID, login.Role)
 	if err != nil {
-		http.Error(w, "Can not build auth token", http.StatusInternalServerError)
+		problem.Error(w, r, err, "Can not build auth token")
 		return
 	}
 
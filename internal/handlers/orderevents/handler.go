@@ -0,0 +1,88 @@
+package orderevents
+
+import (
+	"fmt"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	lastEventIDHeader = "Last-Event-ID"
+	// retryMillis — через сколько браузерный EventSource переподключится после обрыва
+	retryMillis = 3000
+)
+
+type Handler struct {
+	events    events.Subscriber
+	heartbeat time.Duration
+}
+
+func New(events events.Subscriber, heartbeat time.Duration) *Handler {
+	return &Handler{events: events, heartbeat: heartbeat}
+}
+
+// Handle отдаёт SSE-поток событий пользователя: смены статусов заказов, начисления и изменения баланса.
+// При переподключении с Last-Event-ID дошлёт пропущенное из истории хаба.
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+
+	var lastEventID uint64
+	if header := r.Header.Get(lastEventIDHeader); header != "" {
+		// кривой id не повод рвать соединение — просто начинаем с текущего момента
+		lastEventID, _ = strconv.ParseUint(header, 10, 64)
+	}
+
+	backlog, sub := h.events.Subscribe(userID, lastEventID)
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// nginx иначе буферизует ответ и события приходят пачками
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryMillis); err != nil {
+		return
+	}
+	for _, event := range backlog {
+		if writeEvent(w, event) != nil {
+			return
+		}
+	}
+	if rc.Flush() != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// хаб снял подписку из-за медленного чтения — клиент переподключится с Last-Event-ID
+				return
+			}
+			if writeEvent(w, event) != nil || rc.Flush() != nil {
+				return
+			}
+		case <-ticker.C:
+			// комментарий не попадает в EventSource, но не даёт прокси закрыть простаивающее соединение
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event models.Event) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+	return err
+}
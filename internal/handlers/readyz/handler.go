@@ -0,0 +1,37 @@
+package readyz
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/health"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"net/http"
+)
+
+type Handler struct {
+	checker *health.Checker
+}
+
+func New(checker *health.Checker) *Handler {
+	return &Handler{checker: checker}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Ready(r.Context())
+
+	status := http.StatusOK
+	if report.Status == models.HealthFail {
+		status = http.StatusServiceUnavailable
+	}
+
+	// заполняем модель ответа
+	resp := dto.NewHealthResponse(report)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		return
+	}
+}
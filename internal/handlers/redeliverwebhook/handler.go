@@ -0,0 +1,28 @@
+package redeliverwebhook
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/webhooks"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Handler struct {
+	webhooks webhooks.Service
+}
+
+func New(webhooks webhooks.Service) *Handler {
+	return &Handler{webhooks: webhooks}
+}
+
+// Handle ставит доставку в очередь заново; отправит её диспетчер на ближайшем тике
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middlewares.ContextUserIDKey).(string)
+	err := h.webhooks.Redeliver(r.Context(), userID, chi.URLParam(r, "webhookID"), chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		problem.Error(w, r, err, "Cannot redeliver webhook")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
 	usersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
 	"net/http"
@@ -22,40 +23,51 @@ func New(users users.Service) *Handler {
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	contentType := r.Header.Get("Content-Type")
 	if contentType != "application/json" {
-		http.Error(w, "Invalid request content type", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
 		return
 	}
 
 	requestData := &dto.RegisterUserRequest{}
 	dec := json.NewDecoder(r.Body)
 	if err := dec.Decode(&requestData); err != nil {
-		http.Error(w, "Incorrect input json", http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
 		return
 	}
 	user := models.User{
-		Login:    requestData.Login,
-		Password: requestData.Password,
+		Login:      requestData.Login,
+		Password:   requestData.Password,
+		InviteCode: requestData.ReferralCode,
 	}
 
+	var fieldErrors []problem.FieldError
 	if !user.IsValidLogin() {
-		http.Error(w, "Invalid request login: must be presented and must be not empty", http.StatusBadRequest)
-		return
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "login", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
 	}
-
 	if !user.IsValidPass() {
-		http.Error(w, "Invalid request password: must be presented and must be not empty", http.StatusBadRequest)
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "password", Code: problem.FieldRequired, Message: "must be presented and must be not empty"})
+	}
+	if len(fieldErrors) > 0 {
+		problem.Validation(w, r, fieldErrors...)
 		return
 	}
 
 	register, err := h.users.Register(r.Context(), user)
 	if errors.Is(err, usersStore.ErrConflict) {
-		http.Error(w, "User login already exists", http.StatusConflict)
+		problem.Write(w, r, http.StatusConflict, problem.CodeLoginTaken, "User login already exists")
+		return
+	}
+	if errors.Is(err, usersStore.ErrUnknownReferral) {
+		problem.Validation(w, r, problem.FieldError{Field: "referral_code", Code: problem.FieldInvalid, Message: "unknown referral code"})
+		return
+	}
+	if err != nil {
+		problem.Error(w, r, err, "Cannot register user")
 		return
 	}
 
-	JWTToken, err := middlewares.BuildJWTString(register.UserID)
+	JWTToken, err := middlewares.BuildJWTString(register.UserID, register.Role)
 	if err != nil {
-		http.Error(w, "Can not build auth token", http.StatusInternalServerError)
+		problem.Error(w, r, err, "Can not build auth token")
 		return
 	}
 
@@ -0,0 +1,169 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/migrator"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const pingTimeout = time.Second
+
+// Состояние процесса, которое обновляют процессор начислений и клиент accrual. Глобальное по тем же причинам,
+// что и метрики: его пишут из мест, куда неудобно тащить лишние зависимости.
+var (
+	lastTick     atomic.Int64
+	shuttingDown atomic.Bool
+
+	accrualMu        sync.RWMutex
+	accrualChecked   time.Time
+	accrualReachable bool
+	accrualError     string
+)
+
+// MarkTick отмечает успешный проход процессора начислений
+func MarkTick(t time.Time) {
+	lastTick.Store(t.UnixNano())
+}
+
+// MarkAccrual запоминает результат последнего обращения к accrual. Любой HTTP-ответ считается доступностью,
+// недоступность — это ошибка транспорта.
+func MarkAccrual(err error) {
+	accrualMu.Lock()
+	defer accrualMu.Unlock()
+	accrualChecked = time.Now()
+	accrualReachable = err == nil
+	accrualError = ""
+	if err != nil {
+		accrualError = err.Error()
+	}
+}
+
+// SetShuttingDown переводит readiness в отказ, чтобы балансировщик перестал слать трафик до остановки сервера
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+type Checker struct {
+	db         *sql.DB
+	migrator   migrator.Migrator
+	maxTickAge time.Duration
+	startedAt  time.Time
+}
+
+func NewChecker(db *sql.DB, migrator migrator.Migrator, maxTickAge time.Duration) *Checker {
+	return &Checker{db: db, migrator: migrator, maxTickAge: maxTickAge, startedAt: time.Now()}
+}
+
+func (c *Checker) Live() models.HealthReport {
+	return models.HealthReport{
+		Status: models.HealthPass,
+		Checks: map[string]models.HealthCheck{
+			"process": {Status: models.HealthPass, Details: map[string]any{"uptime_seconds": int64(time.Since(c.startedAt).Seconds())}},
+		},
+	}
+}
+
+func (c *Checker) Ready(ctx context.Context) models.HealthReport {
+	checks := map[string]models.HealthCheck{
+		"shutdown":          c.checkShutdown(),
+		"database":          c.checkDB(ctx),
+		"migrations":        c.checkMigrations(ctx),
+		"accrual_processor": c.checkProcessor(),
+		"accrual":           c.checkAccrual(),
+	}
+
+	status := models.HealthPass
+	for _, check := range checks {
+		if check.Status == models.HealthFail {
+			status = models.HealthFail
+			break
+		}
+		if check.Status == models.HealthWarn {
+			status = models.HealthWarn
+		}
+	}
+	return models.HealthReport{Status: status, Checks: checks}
+}
+
+func (c *Checker) checkShutdown() models.HealthCheck {
+	if shuttingDown.Load() {
+		return models.HealthCheck{Status: models.HealthFail, Error: "shutting down"}
+	}
+	return models.HealthCheck{Status: models.HealthPass}
+}
+
+func (c *Checker) checkDB(ctx context.Context) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.db.PingContext(ctx)
+	details := map[string]any{
+		"latency_ms":       time.Since(start).Milliseconds(),
+		"open_connections": c.db.Stats().OpenConnections,
+	}
+	if err != nil {
+		return models.HealthCheck{Status: models.HealthFail, Error: err.Error(), Details: details}
+	}
+	return models.HealthCheck{Status: models.HealthPass, Details: details}
+}
+
+func (c *Checker) checkMigrations(ctx context.Context) models.HealthCheck {
+	latest, err := c.migrator.Latest()
+	if err != nil {
+		return models.HealthCheck{Status: models.HealthFail, Error: err.Error()}
+	}
+	version, dirty, err := c.migrator.Version(ctx)
+	if err != nil {
+		return models.HealthCheck{Status: models.HealthFail, Error: err.Error()}
+	}
+
+	details := map[string]any{"current": version, "expected": latest, "dirty": dirty}
+	if dirty {
+		return models.HealthCheck{Status: models.HealthFail, Error: "schema is dirty", Details: details}
+	}
+	if version < latest {
+		return models.HealthCheck{Status: models.HealthFail, Error: "schema is behind the binary", Details: details}
+	}
+	return models.HealthCheck{Status: models.HealthPass, Details: details}
+}
+
+func (c *Checker) checkProcessor() models.HealthCheck {
+	nanos := lastTick.Load()
+	if nanos == 0 {
+		// процессор ещё не успел отработать после старта — не валим готовность, пока не истёк порог
+		if time.Since(c.startedAt) > c.maxTickAge {
+			return models.HealthCheck{Status: models.HealthFail, Error: "no successful tick since start"}
+		}
+		return models.HealthCheck{Status: models.HealthWarn, Error: "waiting for the first tick"}
+	}
+
+	last := time.Unix(0, nanos)
+	age := time.Since(last)
+	details := map[string]any{"last_tick": last.UTC().Format(time.RFC3339Nano), "age_seconds": age.Seconds()}
+	if age > c.maxTickAge {
+		return models.HealthCheck{Status: models.HealthFail, Error: fmt.Sprintf("last tick is older than %s", c.maxTickAge), Details: details}
+	}
+	return models.HealthCheck{Status: models.HealthPass, Details: details}
+}
+
+// checkAccrual не валит готовность: accrual общий для всех реплик, и вывод их из балансировки ничего не даст.
+// Заказы просто подождут в очереди, поэтому только предупреждаем.
+func (c *Checker) checkAccrual() models.HealthCheck {
+	accrualMu.RLock()
+	defer accrualMu.RUnlock()
+
+	if accrualChecked.IsZero() {
+		return models.HealthCheck{Status: models.HealthPass, Details: map[string]any{"checked": false}}
+	}
+	details := map[string]any{"checked_at": accrualChecked.UTC().Format(time.RFC3339Nano)}
+	if !accrualReachable {
+		return models.HealthCheck{Status: models.HealthWarn, Error: accrualError, Details: details}
+	}
+	return models.HealthCheck{Status: models.HealthPass, Details: details}
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go.uber.org/zap"
+)
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// WithLogger кладёт в контекст логгер запроса, его дальше достают сервисы, хранилища и клиенты
+func WithLogger(ctx context.Context, log *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, log)
+}
+
+// FromContext возвращает логгер запроса, а вне запроса (фоновые задачи) — глобальный
+func FromContext(ctx context.Context) *zap.Logger {
+	if log, ok := ctx.Value(loggerKey).(*zap.Logger); ok {
+		return log
+	}
+	return Log()
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const namespace = "gophermart"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	AccrualRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "accrual_client_requests_total",
+		Help:      "Requests to the accrual system by response status code, \"error\" for transport failures.",
+	}, []string{"code"})
+
+	AccrualQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "accrual_processor_queue_depth",
+		Help:      "Orders in non-terminal status seen on the last processor tick.",
+	})
+
+	AccrualOldestPendingAge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "accrual_oldest_pending_order_age_seconds",
+		Help:      "Age of the oldest order in non-terminal status, 0 when the queue is empty.",
+	})
+
+	AccrualCallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "accrual_callbacks_total",
+		Help:      "Pushed accrual results by outcome: applied, duplicate or rejected.",
+	}, []string{"result"})
+
+	PointsAccrued = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "points_accrued_total",
+		Help:      "Points credited to users from processed orders.",
+	})
+
+	PointsExpired = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "points_expired_total",
+		Help:      "Points written off by the expiry job.",
+	})
+
+	TierChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tier_changes_total",
+		Help:      "Loyalty tier changes made by the nightly recalculation, by new tier.",
+	}, []string{"tier"})
+
+	CampaignBonuses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "campaign_bonus_points_total",
+		Help:      "Bonus points evaluated by promotional campaigns at crediting time, by campaign kind.",
+	}, []string{"kind"})
+
+	PointsWithdrawn = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "points_withdrawn_total",
+		Help:      "Points withdrawn by users.",
+	})
+
+	PointsTransferred = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "points_transferred_total",
+		Help:      "Points transferred between users.",
+	})
+
+	PointsReversed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "points_reversed_total",
+		Help:      "Points returned to users by withdrawal reversals.",
+	})
+
+	HoldsExpired = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "withdrawal_holds_expired_total",
+		Help:      "Withdrawal holds released by the sweeper after their TTL.",
+	})
+
+	AccountsAnonymized = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "accounts_anonymized_total",
+		Help:      "User accounts anonymized after the deletion grace period.",
+	})
+
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_delivery_attempts_total",
+		Help:      "Webhook delivery attempts by result: delivered, retry or failed.",
+	}, []string{"result"})
+)
+
+// RegisterDB публикует статистику пула соединений
+func RegisterDB(db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, namespace))
+}
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/audit"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+// AdminAuditMiddleware пишет в журнал аудита каждое действие сотрудника: кто, с какой ролью, что и с каким результатом.
+func AdminAuditMiddleware(auditService audit.Service) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			responseData := &responseData{
+				status: http.StatusOK,
+				size:   0,
+			}
+			lw := loggingResponseWriter{
+				ResponseWriter: w,
+				responseData:   responseData,
+			}
+			h.ServeHTTP(&lw, r)
+
+			actorID, _ := r.Context().Value(ContextUserIDKey).(string)
+			actorRole, _ := r.Context().Value(ContextUserRoleKey).(string)
+
+			// параметры маршрута chi заполняет при роутинге, поэтому читаем их после обработки запроса
+			auditService.Record(r.Context(), models.AuditEvent{
+				Type:    models.AuditAdminAction,
+				ActorID: actorID,
+				UserID:  chi.URLParam(r, "userID"),
+				Payload: map[string]string{
+					"actor_role": actorRole,
+					"method":     r.Method,
+					"route":      chi.RouteContext(r.Context()).RoutePattern(),
+					"uri":        r.RequestURI,
+					"status":     strconv.Itoa(responseData.status),
+				},
+			})
+		})
+	}
+}
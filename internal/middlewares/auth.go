@@ -2,8 +2,12 @@ package middlewares
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
 	"github.com/golang-jwt/jwt/v4"
 	"go.uber.org/zap"
 	"net/http"
@@ -14,6 +18,8 @@ type key int
 
 const (
 	ContextUserIDKey key = iota
+	ContextUserRoleKey
+	ContextIssuedAtKey
 )
 
 const (
@@ -25,14 +31,17 @@ const (
 type Claims struct {
 	jwt.RegisteredClaims
 	UserID string
+	Role   string
 }
 
-func BuildJWTString(userID string) (string, error) {
+func BuildJWTString(userID string, role string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenExp)),
 		},
 		UserID: userID,
+		Role:   role,
 	})
 
 	tokenString, err := token.SignedString([]byte(SecretKey))
@@ -44,6 +53,14 @@ func BuildJWTString(userID string) (string, error) {
 }
 
 func GetUserID(tokenString string) string {
+	claims := GetClaims(tokenString)
+	if claims == nil {
+		return ""
+	}
+	return claims.UserID
+}
+
+func GetClaims(tokenString string) *Claims {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims,
 		func(t *jwt.Token) (interface{}, error) {
@@ -53,15 +70,15 @@ func GetUserID(tokenString string) string {
 			return []byte(SecretKey), nil
 		})
 	if err != nil {
-		return ""
+		return nil
 	}
 
 	if !token.Valid {
 		logger.Log().Error("Token is not valid", zap.String("token", token.Raw))
-		return ""
+		return nil
 	}
 
-	return claims.UserID
+	return claims
 }
 
 func AuthorizedMiddleware(h http.Handler) http.Handler {
@@ -69,18 +86,74 @@ func AuthorizedMiddleware(h http.Handler) http.Handler {
 		authCookie, err := r.Cookie(CookieName)
 
 		if err != nil || authCookie == nil || authCookie.Value == "" {
-			http.Error(w, "Unauthorized requests forbidden", http.StatusUnauthorized)
+			problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized requests forbidden")
 			return
 		}
 
-		userID := GetUserID(authCookie.Value)
+		claims := GetClaims(authCookie.Value)
 
-		if userID == "" {
-			http.Error(w, "Unauthorized requests forbidden", http.StatusUnauthorized)
+		if claims == nil || claims.UserID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Unauthorized requests forbidden")
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), ContextUserIDKey, userID)
+		// Токены, выпущенные до появления ролей, считаем пользовательскими
+		role := claims.Role
+		if role == "" {
+			role = models.RoleUser
+		}
+
+		ctx := context.WithValue(r.Context(), ContextUserIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, ContextUserRoleKey, role)
+		ctx = context.WithValue(ctx, ContextIssuedAtKey, IssuedAt(claims))
+		ctx = logger.WithLogger(ctx, logger.FromContext(ctx).With(zap.String("user_id", claims.UserID)))
 		h.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// IssuedAt — момент выпуска токена. У токенов без iat нулевое время: их отзывает любой отзыв сессий
+func IssuedAt(claims *Claims) time.Time {
+	if claims.IssuedAt == nil {
+		return time.Time{}
+	}
+	return claims.IssuedAt.Time
+}
+
+// SessionMiddleware отклоняет токены, выпущенные до отзыва сессий пользователя (например, при удалении аккаунта).
+// Должен стоять после AuthorizedMiddleware.
+func SessionMiddleware(usersService users.Service) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := r.Context().Value(ContextUserIDKey).(string)
+			issuedAt, _ := r.Context().Value(ContextIssuedAtKey).(time.Time)
+
+			err := usersService.CheckSession(r.Context(), userID, issuedAt)
+			if errors.Is(err, users.ErrSessionRevoked) {
+				problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Session revoked")
+				return
+			}
+			if err != nil {
+				problem.Error(w, r, err, "Cannot check session")
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole пропускает запрос только если роль из токена входит в список разрешённых.
+// Должен стоять после AuthorizedMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(ContextUserRoleKey).(string)
+			for _, allowed := range roles {
+				if role == allowed {
+					h.ServeHTTP(w, r)
+					return
+				}
+			}
+			problem.Write(w, r, http.StatusForbidden, problem.CodeForbidden, "Insufficient role")
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/idempotency"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+const (
+	IdempotencyKeyHeader    = "Idempotency-Key"
+	idempotencyReplayHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+)
+
+// recordingResponseWriter запоминает ответ, чтобы его можно было сохранить и отдать на повтор запроса
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recordingResponseWriter) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recordingResponseWriter) WriteHeader(statusCode int) {
+	r.status = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// IdempotencyMiddleware повторяет сохранённый ответ для изменяющих запросов с заголовком Idempotency-Key.
+// Должен стоять после AuthorizedMiddleware: ключи живут в пространстве пользователя.
+func IdempotencyMiddleware(idempotencyService idempotency.Service) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || !isMutating(r.Method) {
+				h.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				problem.Validation(w, r, problem.FieldError{Field: IdempotencyKeyHeader, Code: problem.FieldInvalid, Message: "must be at most 255 characters"})
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				problem.Error(w, r, err, "Cannot read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			userID := r.Context().Value(ContextUserIDKey).(string)
+			fingerprint := requestFingerprint(r, body)
+
+			record, err := idempotencyService.Begin(r.Context(), userID, key, fingerprint)
+			if errors.Is(err, idempotency.ErrKeyReused) {
+				problem.Write(w, r, http.StatusUnprocessableEntity, problem.CodeIdempotencyReused, "Idempotency key reused with different payload")
+				return
+			}
+			if errors.Is(err, idempotency.ErrInProgress) {
+				problem.Write(w, r, http.StatusConflict, problem.CodeIdempotencyInFlight, "Request with this idempotency key is in progress")
+				return
+			}
+			if err != nil {
+				problem.Error(w, r, err, "Cannot process idempotency key")
+				return
+			}
+
+			if record != nil {
+				if record.ContentType != "" {
+					w.Header().Set("Content-Type", record.ContentType)
+				}
+				w.Header().Set(idempotencyReplayHeader, "true")
+				w.WriteHeader(record.StatusCode)
+				_, _ = w.Write(record.Body)
+				return
+			}
+
+			rw := &recordingResponseWriter{ResponseWriter: w}
+			h.ServeHTTP(rw, r)
+			if rw.status == 0 {
+				rw.status = http.StatusOK
+			}
+
+			// клиент мог уже отвалиться по таймауту, а результат сохранить всё равно нужно
+			ctx := context.Background()
+
+			// ошибку сервера не запоминаем, чтобы клиент мог повторить запрос с тем же ключом
+			if rw.status >= http.StatusInternalServerError {
+				err = idempotencyService.Release(ctx, userID, key)
+				if err != nil {
+					logger.FromContext(r.Context()).Error("Cannot release idempotency key", zap.Error(err))
+				}
+				return
+			}
+
+			err = idempotencyService.Complete(ctx, models.IdempotencyRecord{
+				UserID:      userID,
+				Key:         key,
+				StatusCode:  rw.status,
+				ContentType: rw.Header().Get("Content-Type"),
+				Body:        rw.body.Bytes(),
+			})
+			if err != nil {
+				logger.FromContext(r.Context()).Error("Cannot save idempotent response", zap.Error(err))
+			}
+		})
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(r.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
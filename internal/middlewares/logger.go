@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
 	"go.uber.org/zap"
 	"net/http"
 	"time"
@@ -29,6 +30,11 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode
 }
 
+// Unwrap нужен http.ResponseController, чтобы стриминговые хендлеры (SSE) могли сбрасывать буфер
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // LoggerMiddleware — middlewares-логер для входящих HTTP-запросов.
 func LoggerMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -46,13 +52,20 @@ func LoggerMiddleware(h http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		zap.L().Info("got incoming HTTP request",
+		fields := []zap.Field{
 			zap.String("uri", r.RequestURI),
 			zap.String("method", r.Method),
 			zap.Int("status", responseData.status),
 			zap.String("duration", duration.String()),
 			zap.Int("size", responseData.size),
-		)
+		}
+		// user_id появляется в контексте только внутри авторизованной группы, сюда он не долетает — берём из куки
+		if authCookie, err := r.Cookie(CookieName); err == nil {
+			if userID := GetUserID(authCookie.Value); userID != "" {
+				fields = append(fields, zap.String("user_id", userID))
+			}
+		}
+		logger.FromContext(r.Context()).Info("got incoming HTTP request", fields...)
 
 	})
 }
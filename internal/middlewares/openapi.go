@@ -0,0 +1,164 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// OpenAPIMiddleware проверяет запрос по спецификации в строгом режиме: тело, параметры пути и запроса,
+// Content-Type, плюс отвергает query-параметры, которых нет в описании операции.
+// Маршруты, которых нет в спецификации, пропускаются дальше — ими займётся роутер (404/405).
+// Аутентификацию здесь не проверяем, это дело AuthorizedMiddleware.
+func OpenAPIMiddleware(doc *openapi3.T) (func(http.Handler) http.Handler, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	options := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			unknown := unknownQueryParams(r, route)
+			err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			})
+			if err != nil || len(unknown) > 0 {
+				writeValidationError(w, r, err, unknown)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+func unknownQueryParams(r *http.Request, route *routers.Route) []problem.FieldError {
+	known := map[string]bool{}
+	for _, params := range []openapi3.Parameters{route.PathItem.Parameters, route.Operation.Parameters} {
+		for _, param := range params {
+			if param.Value != nil && param.Value.In == openapi3.ParameterInQuery {
+				known[param.Value.Name] = true
+			}
+		}
+	}
+
+	var fieldErrors []problem.FieldError
+	for name := range r.URL.Query() {
+		if !known[name] {
+			fieldErrors = append(fieldErrors, problem.FieldError{Field: name, Code: problem.FieldUnknown, Message: "unknown query parameter"})
+		}
+	}
+	return fieldErrors
+}
+
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error, fieldErrors []problem.FieldError) {
+	for _, e := range flatten(err) {
+		var requestErr *openapi3filter.RequestError
+		if !errors.As(e, &requestErr) {
+			fieldErrors = append(fieldErrors, problem.FieldError{Field: "", Code: problem.FieldInvalid, Message: e.Error()})
+			continue
+		}
+
+		if requestErr.RequestBody != nil {
+			if strings.HasPrefix(requestErr.Reason, "header Content-Type") {
+				problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidContentType, "Invalid request content type")
+				return
+			}
+			var parseErr *openapi3filter.ParseError
+			if errors.As(requestErr.Err, &parseErr) {
+				problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Incorrect input json")
+				return
+			}
+		}
+
+		field := ""
+		if requestErr.Parameter != nil {
+			field = requestErr.Parameter.Name
+		}
+		for _, cause := range flatten(requestErr.Err) {
+			fieldErrors = append(fieldErrors, toFieldError(field, cause))
+		}
+	}
+	problem.Validation(w, r, firstPerField(fieldErrors)...)
+}
+
+// firstPerField оставляет по одной ошибке на поле: одно нарушение схемы иногда даёт несколько сообщений
+func firstPerField(fieldErrors []problem.FieldError) []problem.FieldError {
+	seen := map[string]bool{}
+	result := make([]problem.FieldError, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		if seen[fieldError.Field] {
+			continue
+		}
+		seen[fieldError.Field] = true
+		result = append(result, fieldError)
+	}
+	return result
+}
+
+const unsupportedSuffix = " is unsupported"
+
+func toFieldError(field string, err error) problem.FieldError {
+	if errors.Is(err, openapi3filter.ErrInvalidRequired) {
+		return problem.FieldError{Field: field, Code: problem.FieldRequired, Message: "is required"}
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return problem.FieldError{Field: field, Code: problem.FieldInvalid, Message: err.Error()}
+	}
+
+	// для тела запроса имя поля берём из пути внутри схемы, для параметров оно уже известно
+	if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+		field = strings.Join(pointer, ".")
+	}
+	switch {
+	case schemaErr.SchemaField == "required":
+		return problem.FieldError{Field: field, Code: problem.FieldRequired, Message: "is required"}
+	case schemaErr.SchemaField == "format":
+		// в стандартном сообщении kin-openapi торчит регулярка, клиенту она ни к чему
+		return problem.FieldError{Field: field, Code: problem.FieldInvalid, Message: "must be a valid " + schemaErr.Schema.Format}
+	case strings.HasSuffix(schemaErr.Reason, unsupportedSuffix):
+		// лишнее поле при additionalProperties: false, имя есть только в тексте ошибки
+		quoted := strings.TrimSuffix(strings.TrimPrefix(schemaErr.Reason, "property "), unsupportedSuffix)
+		if name, err := strconv.Unquote(quoted); err == nil {
+			field = strings.Trim(field+"."+name, ".")
+		}
+		return problem.FieldError{Field: field, Code: problem.FieldUnknown, Message: "unknown field"}
+	}
+	return problem.FieldError{Field: field, Code: problem.FieldInvalid, Message: schemaErr.Reason}
+}
+
+func flatten(err error) []error {
+	if err == nil {
+		return nil
+	}
+	// errors.As тут не подходит: он провалится сквозь RequestError во вложенный MultiError
+	multi, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range multi {
+		errs = append(errs, flatten(e)...)
+	}
+	return errs
+}
@@ -0,0 +1,131 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/api"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIMiddleware(t *testing.T) {
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	mw, err := OpenAPIMiddleware(doc)
+	if err != nil {
+		t.Fatalf("build middleware: %v", err)
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantFields  []string
+	}{
+		{
+			name:        "valid login passes",
+			method:      http.MethodPost,
+			target:      "/api/user/login",
+			contentType: "application/json",
+			body:        `{"login":"gopher","password":"secret"}`,
+			wantStatus:  http.StatusTeapot,
+		},
+		{
+			name:        "missing and unknown fields",
+			method:      http.MethodPost,
+			target:      "/api/user/login",
+			contentType: "application/json",
+			body:        `{"login":"gopher","extra":1}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    problem.CodeValidationFailed,
+			wantFields:  []string{"extra", "password"},
+		},
+		{
+			name:        "broken json",
+			method:      http.MethodPost,
+			target:      "/api/user/login",
+			contentType: "application/json",
+			body:        `{"login":`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    problem.CodeInvalidJSON,
+		},
+		{
+			name:        "wrong content type",
+			method:      http.MethodPost,
+			target:      "/api/user/login",
+			contentType: "text/plain",
+			body:        `gopher`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    problem.CodeInvalidContentType,
+		},
+		{
+			name:        "negative withdrawal",
+			method:      http.MethodPost,
+			target:      "/api/user/balance/withdraw",
+			contentType: "application/json",
+			body:        `{"order":"2377225624","sum":-5}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    problem.CodeValidationFailed,
+			wantFields:  []string{"sum"},
+		},
+		{
+			name:       "bad and unknown query parameters",
+			method:     http.MethodGet,
+			target:     "/api/admin/users?limit=0&foo=1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+			wantFields: []string{"foo", "limit"},
+		},
+		{
+			name:       "route outside the spec goes to the router",
+			method:     http.MethodGet,
+			target:     "/unknown",
+			wantStatus: http.StatusTeapot,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			mw(next).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var got problem.Problem
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Code != tt.wantCode {
+				t.Errorf("code = %v, want %v", got.Code, tt.wantCode)
+			}
+			fields := map[string]bool{}
+			for _, e := range got.Errors {
+				fields[e.Field] = true
+			}
+			for _, field := range tt.wantFields {
+				if !fields[field] {
+					t.Errorf("no error for field %q in %+v", field, got.Errors)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+// RequestIDMiddleware принимает X-Request-ID от клиента или генерирует свой, отдаёт его в ответе
+// и кладёт в контекст логгер, к которому уже привязаны request_id и trace_id.
+func RequestIDMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !IsValidRequestID(requestID) {
+			requestID = logger.NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		fields := []zap.Field{zap.String("request_id", requestID)}
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+			fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+		}
+
+		ctx := logger.WithRequestID(r.Context(), requestID)
+		ctx = logger.WithLogger(ctx, logger.Log().With(fields...))
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// IsValidRequestID отсекает пустые, слишком длинные и непечатные значения, чтобы клиент не мог испортить логи
+func IsValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range requestID {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
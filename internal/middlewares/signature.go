@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"bytes"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/pkg/webhook"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"time"
+)
+
+const maxSignedBodySize = 1 << 20
+
+// SignatureMiddleware пропускает только запросы, подписанные общим секретом по той же схеме,
+// что и наши исходящие вебхуки (pkg/webhook). С пустым секретом маршрут выключен и отвечает 404.
+func SignatureMiddleware(secret string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if secret == "" {
+				problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "Route not found")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodySize))
+			if err != nil {
+				problem.Write(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Cannot read request body")
+				return
+			}
+			err = webhook.Verify(secret, body, r.Header.Get(webhook.SignatureHeader), webhook.DefaultTolerance, time.Now())
+			if err != nil {
+				logger.FromContext(r.Context()).Warn("Rejected signed request", zap.Error(err))
+				problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid request signature")
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
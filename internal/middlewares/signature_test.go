@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/pkg/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	body := `{"order":"12345678903","status":"PROCESSED","accrual":500}`
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// тело после проверки подписи должно дойти до хендлера целиком
+		got, _ := io.ReadAll(r.Body)
+		if string(got) != body {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tests := []struct {
+		name       string
+		secret     string
+		signature  string
+		wantStatus int
+	}{
+		{
+			name:       "valid signature passes",
+			secret:     "secret",
+			signature:  webhook.Sign("secret", []byte(body), time.Now()),
+			wantStatus: http.StatusTeapot,
+		},
+		{
+			name:       "wrong secret",
+			secret:     "secret",
+			signature:  webhook.Sign("other", []byte(body), time.Now()),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "replayed signature",
+			secret:     "secret",
+			signature:  webhook.Sign("secret", []byte(body), time.Now().Add(-time.Hour)),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing signature",
+			secret:     "secret",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "disabled without secret",
+			signature:  webhook.Sign("", []byte(body), time.Now()),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/internal/accrual/callback", strings.NewReader(body))
+			r.Header.Set(webhook.SignatureHeader, tt.signature)
+			w := httptest.NewRecorder()
+			SignatureMiddleware(tt.secret)(next).ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// TracingMiddleware открывает серверный спан на запрос и кладёт его в контекст, откуда его подхватывают
+// сервисы и хранилища. Входящий traceparent учитывается, так что трейс можно продолжить с клиента.
+func TracingMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer().Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.target", r.URL.Path),
+			))
+		defer span.End()
+
+		responseData := &responseData{
+			status: http.StatusOK,
+			size:   0,
+		}
+		lw := loggingResponseWriter{
+			ResponseWriter: w,
+			responseData:   responseData,
+		}
+		h.ServeHTTP(&lw, r.WithContext(ctx))
+
+		// шаблон маршрута известен только после роутинга
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			span.SetName(r.Method + " " + rctx.RoutePattern())
+			span.SetAttributes(attribute.String("http.route", rctx.RoutePattern()))
+		}
+		span.SetAttributes(attribute.Int("http.status_code", responseData.status))
+		if responseData.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(responseData.status))
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+const (
+	AdjustmentCredit = "CREDIT"
+	AdjustmentDebit  = "DEBIT"
+
+	AdjustmentPending  = "PENDING"
+	AdjustmentApplied  = "APPLIED"
+	AdjustmentRejected = "REJECTED"
+
+	ReasonCompensation = "COMPENSATION"
+	ReasonGoodwill     = "GOODWILL"
+	ReasonFraud        = "FRAUD"
+	ReasonCorrection   = "CORRECTION"
+)
+
+type Adjustment struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Type      string    `json:"type"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	Note      string    `json:"note"`
+	Status    string    `json:"status"`
+	CreatedBy string    `json:"created_by"`
+	DecidedBy string    `json:"decided_by"`
+	CreatedAt time.Time `json:"created_at"`
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+// SignedAmount возвращает сумму со знаком, с которым она ляжет на баланс
+func (a *Adjustment) SignedAmount() float64 {
+	if a.Type == AdjustmentDebit {
+		return -a.Amount
+	}
+	return a.Amount
+}
+
+func (a *Adjustment) IsValidType() bool {
+	return a.Type == AdjustmentCredit || a.Type == AdjustmentDebit
+}
+
+func (a *Adjustment) IsValidReason() bool {
+	switch a.Reason {
+	case ReasonCompensation, ReasonGoodwill, ReasonFraud, ReasonCorrection:
+		return true
+	}
+	return false
+}
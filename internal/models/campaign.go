@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Виды правил кампании
+const (
+	// CampaignMultiplier — бонус accrual*(Value-1): Value=2 — «двойные баллы»
+	CampaignMultiplier = "MULTIPLIER"
+	// CampaignFixed — фиксированный бонус Value за каждый заказ
+	CampaignFixed = "FIXED"
+	// CampaignFirstOrder — бонус Value за первый начисленный заказ пользователя
+	CampaignFirstOrder = "FIRST_ORDER"
+)
+
+// Campaign действует на заказы, загруженные в [StartsAt, EndsAt). Spent и Bonuses — сколько уже начислено.
+type Campaign struct {
+	ID        string
+	Name      string
+	Kind      string
+	Value     float64
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedBy string
+	CreatedAt time.Time
+	Spent     float64
+	Bonuses   int
+}
+
+// CampaignBonus — бонус по кампании, который начисляется вместе с заказом отдельной записью
+type CampaignBonus struct {
+	CampaignID string
+	Kind       string
+	Amount     float64
+}
+
+func IsValidCampaignKind(kind string) bool {
+	return kind == CampaignMultiplier || kind == CampaignFixed || kind == CampaignFirstOrder
+}
+
+// Bonus — бонус кампании за заказ с начислением accrual; first — первый ли это начисленный заказ пользователя
+func (c *Campaign) Bonus(accrual float64, first bool) float64 {
+	switch c.Kind {
+	case CampaignMultiplier:
+		if c.Value > 1 {
+			return accrual * (c.Value - 1)
+		}
+	case CampaignFixed:
+		return c.Value
+	case CampaignFirstOrder:
+		if first {
+			return c.Value
+		}
+	}
+	return 0
+}
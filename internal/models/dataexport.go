@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// DataExport — всё, что сервис хранит о пользователе, для выдачи по его запросу
+type DataExport struct {
+	GeneratedAt time.Time
+	Profile     User
+	Orders      []Order
+	Withdrawals []Withdrawal
+	Statement   []StatementEntry
+}
@@ -0,0 +1,36 @@
+package models
+
+import "encoding/json"
+
+const (
+	EventOrder   = "order"
+	EventBalance = "balance"
+	// EventTransfer приходит получателю перевода баллов
+	EventTransfer = "transfer"
+	// EventResync говорит клиенту, что часть событий потеряна и состояние надо перечитать целиком
+	EventResync = "resync"
+)
+
+type Event struct {
+	ID     uint64
+	UserID string
+	Type   string
+	Data   json.RawMessage
+}
+
+type OrderEvent struct {
+	Number  string  `json:"number"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual,omitempty"`
+}
+
+type BalanceEvent struct {
+	Current   float64 `json:"current"`
+	Withdrawn float64 `json:"withdrawn"`
+}
+
+type TransferEvent struct {
+	ID   string  `json:"id"`
+	From string  `json:"from"`
+	Sum  float64 `json:"sum"`
+}
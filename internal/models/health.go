@@ -0,0 +1,18 @@
+package models
+
+const (
+	HealthPass = "pass"
+	HealthWarn = "warn"
+	HealthFail = "fail"
+)
+
+type HealthCheck struct {
+	Status  string
+	Error   string
+	Details map[string]any
+}
+
+type HealthReport struct {
+	Status string
+	Checks map[string]HealthCheck
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Статусы блокировки баллов под списание
+const (
+	HoldHeld     = "HELD"
+	HoldCaptured = "CAPTURED"
+	HoldVoided   = "VOIDED"
+	HoldExpired  = "EXPIRED"
+)
+
+// Hold — баллы, отложенные под заказ до оплаты. Пока блокировка активна, баллы остаются на балансе,
+// но потратить их нельзя; capture превращает её в обычное списание, void и истечение срока — отпускают.
+type Hold struct {
+	ID         string
+	UserID     string
+	Number     string
+	Sum        float64
+	Status     string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	ResolvedAt *time.Time
+}
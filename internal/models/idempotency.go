@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type IdempotencyRecord struct {
+	UserID      string
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// IsCompleted — ответ уже сохранён и его можно отдать повторно
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Источники партий баллов
+const (
+	LotAccrual    = "ACCRUAL"
+	LotAdjustment = "ADJUSTMENT"
+	LotLegacy     = "LEGACY"
+	LotCampaign   = "CAMPAIGN"
+	LotReferral   = "REFERRAL"
+	LotTransfer   = "TRANSFER"
+	LotReversal   = "REVERSAL"
+)
+
+// Порядок списания партий
+const (
+	// ConsumeFIFO — сначала самые старые партии
+	ConsumeFIFO = "fifo"
+	// ConsumeExpiringFirst — сначала те, что сгорят раньше; бессрочные в конце
+	ConsumeExpiringFirst = "expiring_first"
+)
+
+// ExpiryPolicy — правила сгорания баллов. Months == 0 — баллы не сгорают.
+type ExpiryPolicy struct {
+	Months      int
+	Consumption string
+}
+
+// Expiry — ближайшее сгорание: сколько баллов сгорит и когда
+type Expiry struct {
+	Sum       float64
+	ExpiresAt time.Time
+}
@@ -9,3 +9,13 @@ type Order struct {
 	Accrual    float64   `json:"accrual"`
 	UploadedAt time.Time `json:"uploaded_at"`
 }
+
+// AccrualResult — что изменило применение ответа accrual к заказу
+type AccrualResult struct {
+	// Credited — начислено за сам заказ с учётом множителя уровня
+	Credited float64
+	// RefereeRewarded — той же транзакцией владельцу заказа начислена награда за приглашение
+	RefereeRewarded bool
+	// ReferrerID — пригласивший, чей баланс изменила награда за приглашение; пусто, если не изменила
+	ReferrerID string
+}
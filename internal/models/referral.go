@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Статусы приглашения
+const (
+	ReferralPending  = "PENDING"
+	ReferralRewarded = "REWARDED"
+	ReferralRejected = "REJECTED"
+)
+
+// ReferralReasonCap — пригласивший исчерпал лимит вознаграждаемых приглашений.
+// Пригласить самого себя не даёт CHECK в таблице referrals.
+const ReferralReasonCap = "referrer_cap"
+
+// ReferralPolicy — награды за приглашение. Cap — сколько приглашений одного пользователя может быть
+// вознаграждено, 0 — без ограничения.
+type ReferralPolicy struct {
+	ReferrerBonus float64
+	RefereeBonus  float64
+	Cap           int
+}
+
+// Referral — приглашённый пользователь глазами пригласившего
+type Referral struct {
+	RefereeLogin   string
+	Status         string
+	Reason         string
+	ReferrerReward float64
+	CreatedAt      time.Time
+	RewardedAt     *time.Time
+}
+
+// ReferralProgram — код пользователя и его приглашения
+type ReferralProgram struct {
+	Code      string
+	Referrals []Referral
+}
+
+// Earned — сколько баллов пользователь получил за приглашения
+func (p *ReferralProgram) Earned() float64 {
+	var earned float64
+	for _, referral := range p.Referrals {
+		if referral.Status == ReferralRewarded {
+			earned += referral.ReferrerReward
+		}
+	}
+	return earned
+}
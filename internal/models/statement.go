@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Типы записей выписки. Зачисления совпадают с источниками партий, кроме LEGACY — это входящий остаток.
+const (
+	StatementOpening    = "OPENING"
+	StatementAccrual    = "ACCRUAL"
+	StatementCampaign   = "CAMPAIGN"
+	StatementReferral   = "REFERRAL"
+	StatementTransfer   = "TRANSFER"
+	StatementReversal   = "REVERSAL"
+	StatementAdjustment = "ADJUSTMENT"
+	StatementWithdrawal = "WITHDRAWAL"
+	StatementExpiry     = "EXPIRY"
+)
+
+var statementTypes = map[string]bool{
+	StatementOpening:    true,
+	StatementAccrual:    true,
+	StatementCampaign:   true,
+	StatementReferral:   true,
+	StatementTransfer:   true,
+	StatementReversal:   true,
+	StatementAdjustment: true,
+	StatementWithdrawal: true,
+	StatementExpiry:     true,
+}
+
+func IsStatementType(entryType string) bool {
+	return statementTypes[entryType]
+}
+
+// StatementEntry — одно изменение баланса. Amount со знаком, Balance — баланс сразу после записи.
+// Reference — номер заказа, id перевода, корректировки и т.п., в зависимости от типа.
+type StatementEntry struct {
+	ID        string
+	Type      string
+	Reference string
+	Amount    float64
+	Balance   float64
+	CreatedAt time.Time
+}
+
+type StatementFilter struct {
+	Types  []string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// MonthlyStatement — выписка за календарный месяц (UTC) с остатками на начало и конец
+type MonthlyStatement struct {
+	Month    time.Time
+	Opening  float64
+	Closing  float64
+	Credited float64
+	Debited  float64
+	Entries  []StatementEntry
+}
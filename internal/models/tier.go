@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Tier — уровень лояльности: достаётся при накоплении Threshold баллов за окно, начисления умножаются на Multiplier
+type Tier struct {
+	Name       string
+	Threshold  float64
+	Multiplier float64
+}
+
+// TierStatus — текущий уровень пользователя и прогресс до следующего. Next == nil на последнем уровне.
+type TierStatus struct {
+	Tier  Tier
+	Spend float64
+	Next  *Tier
+}
+
+type TierChange struct {
+	UserID    string
+	From      string
+	To        string
+	Spend     float64
+	ChangedAt time.Time
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Направление перевода относительно пользователя, который смотрит историю
+const (
+	TransferIn  = "in"
+	TransferOut = "out"
+)
+
+type Transfer struct {
+	ID         string
+	FromUserID string
+	FromLogin  string
+	ToUserID   string
+	ToLogin    string
+	Sum        float64
+	CreatedAt  time.Time
+}
+
+// Direction — входящий или исходящий перевод для userID
+func (t *Transfer) Direction(userID string) string {
+	if t.ToUserID == userID {
+		return TransferIn
+	}
+	return TransferOut
+}
+
+// Counterparty — логин второй стороны перевода для userID
+func (t *Transfer) Counterparty(userID string) string {
+	if t.ToUserID == userID {
+		return t.FromLogin
+	}
+	return t.ToLogin
+}
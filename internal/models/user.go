@@ -1,9 +1,37 @@
 package models
 
+import "time"
+
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
 type User struct {
-	UserID   string `db:"ID"`
-	Login    string `db:"LOGIN"`
-	Password string `db:"PASSWORD"`
+	UserID      string `db:"ID"`
+	Login       string `db:"LOGIN"`
+	Password    string `db:"PASSWORD"`
+	Role        string `db:"ROLE"`
+	TOTPSecret  string `db:"TOTP_SECRET"`
+	TOTPEnabled bool   `db:"TOTP_ENABLED"`
+	// ReferralCode — собственный код приглашения пользователя
+	ReferralCode string `db:"REFERRAL_CODE"`
+	// InviteCode — код пригласившего, указанный при регистрации
+	InviteCode string
+	// Deletion — запрошенное удаление аккаунта, nil если не запрошено
+	Deletion *AccountDeletion
+}
+
+// AccountDeletion — удаление аккаунта: до ScheduledAt его можно отменить, потом данные обезличиваются
+type AccountDeletion struct {
+	RequestedAt time.Time
+	ScheduledAt time.Time
+}
+
+type TOTPEnrollment struct {
+	Secret          string
+	ProvisioningURI string
 }
 
 func (u *User) IsValidLogin() bool {
@@ -13,3 +41,7 @@ func (u *User) IsValidLogin() bool {
 func (u *User) IsValidPass() bool {
 	return u.Password != ""
 }
+
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleSupport || role == RoleAdmin
+}
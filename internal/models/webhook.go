@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookOrderFinalized      = "order.finalized"
+	WebhookWithdrawalSucceeded = "withdrawal.succeeded"
+
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryFailed    = "FAILED"
+)
+
+// WebhookEventTypes — события, на которые можно подписаться
+var WebhookEventTypes = []string{WebhookOrderFinalized, WebhookWithdrawalSucceeded}
+
+type Webhook struct {
+	ID        string
+	UserID    string
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+}
+
+// WebhookEvent — событие до раскладки по подпискам. ID общий для всех доставок одного события,
+// по нему получатель отсекает повторы.
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type WebhookDelivery struct {
+	ID             string
+	WebhookID      string
+	EventID        string
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    time.Time
+
+	// заполняются только у доставок, взятых диспетчером в работу
+	URL    string
+	Secret string
+}
+
+// OrderFinalizedData — итог заказа; Accrual — сколько фактически зачислено с учётом множителя уровня
+type OrderFinalizedData struct {
+	Number  string  `json:"number"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual"`
+}
+
+type WithdrawalSucceededData struct {
+	Order string  `json:"order"`
+	Sum   float64 `json:"sum"`
+}
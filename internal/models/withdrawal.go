@@ -2,8 +2,38 @@ package models
 
 import "time"
 
+// Статусы списания с учётом возвратов
+const (
+	WithdrawalProcessed         = "PROCESSED"
+	WithdrawalPartiallyReversed = "PARTIALLY_REVERSED"
+	WithdrawalReversed          = "REVERSED"
+)
+
 type Withdrawal struct {
+	ID          string    `json:"id"`
 	OrderNumber string    `json:"order"`
 	Sum         float64   `json:"sum"`
+	Reversed    float64   `json:"reversed"`
 	ProcessedAt time.Time `json:"processed_at"`
 }
+
+func (w *Withdrawal) Status() string {
+	switch {
+	case w.Reversed <= 0:
+		return WithdrawalProcessed
+	case w.Reversed < w.Sum:
+		return WithdrawalPartiallyReversed
+	}
+	return WithdrawalReversed
+}
+
+// Reversal — возврат части или всего списания на баланс, например при отмене заказа в магазине
+type Reversal struct {
+	ID           string
+	WithdrawalID string
+	UserID       string
+	Sum          float64
+	Reason       string
+	CreatedBy    string
+	CreatedAt    time.Time
+}
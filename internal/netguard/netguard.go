@@ -0,0 +1,54 @@
+// Package netguard не пускает исходящие запросы по адресам, которые задают пользователи, во внутреннюю сеть
+package netguard
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+var ErrForbiddenAddress = errors.New("address is not allowed")
+
+// reserved — диапазоны, которые не покрывают методы net.IP: «этот» хост, CGNAT, бенчмаркинг, широковещание
+var reserved = []*net.IPNet{
+	mustCIDR("0.0.0.0/8"),
+	mustCIDR("100.64.0.0/10"),
+	mustCIDR("198.18.0.0/15"),
+	mustCIDR("255.255.255.255/32"),
+}
+
+// IsPublic — адрес не loopback, не link-local, не частный, не нулевой и не multicast
+func IsPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range reserved {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Control — для net.Dialer.Control. Вызывается уже после разрешения имени, поэтому проверяет адрес,
+// к которому реально идёт соединение, и подмена DNS между регистрацией и доставкой не помогает.
+func Control(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublic(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+func mustCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
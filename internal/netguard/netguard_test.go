@@ -0,0 +1,50 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"10.0.0.5", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"ff02::1", false},
+		{"100.64.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := IsPublic(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("IsPublic(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestControl(t *testing.T) {
+	if err := Control("tcp4", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("Control() error = %v, want nil", err)
+	}
+	if err := Control("tcp4", "127.0.0.1:9090", nil); !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("Control() error = %v, want %v", err, ErrForbiddenAddress)
+	}
+	if err := Control("tcp6", "[::1]:9090", nil); !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("Control() error = %v, want %v", err, ErrForbiddenAddress)
+	}
+}
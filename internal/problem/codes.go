@@ -0,0 +1,117 @@
+package problem
+
+import (
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/adjustments"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/idempotency"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	adjustmentsStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/adjustments"
+	balanceStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	ordersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	usersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	webhooksStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/webhooks"
+	"net/http"
+)
+
+// Коды ошибок — контракт с фронтом, менять их нельзя, только добавлять новые
+const (
+	CodeInternal             = "internal_error"
+	CodeInvalidContentType   = "invalid_content_type"
+	CodeInvalidJSON          = "invalid_json"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeConflict             = "conflict"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeLoginTaken           = "login_taken"
+	CodeSecondFactorRequired = "second_factor_required"
+	CodeInvalidSecondFactor  = "invalid_second_factor"
+	CodeSecondFactorLocked   = "second_factor_locked"
+	CodeTOTPAlreadyEnabled   = "totp_already_enabled"
+	CodeTOTPNotEnrolled      = "totp_not_enrolled"
+	CodeTOTPNotEnabled       = "totp_not_enabled"
+	CodeInvalidOrderNumber   = "invalid_order_number"
+	CodeOrderAnotherUser     = "order_owned_by_another_user"
+	CodeOrderProcessed       = "order_already_processed"
+	CodeInsufficientFunds    = "insufficient_funds"
+	CodeSameApprover         = "same_approver"
+	CodeSelfAdjustment       = "self_adjustment"
+	CodeNotPending           = "adjustment_not_pending"
+	CodeIdempotencyReused    = "idempotency_key_reused"
+	CodeIdempotencyInFlight  = "idempotency_key_in_progress"
+	CodeUnknownReferral      = "unknown_referral_code"
+	CodeSelfTransfer         = "self_transfer"
+	CodeTransferLimit        = "transfer_limit_exceeded"
+	CodeHoldNotActive        = "hold_not_active"
+	CodeReversalExceeded     = "reversal_exceeds_withdrawal"
+	CodeDeletionNotScheduled = "deletion_not_scheduled"
+)
+
+// Коды ошибок валидации полей
+const (
+	FieldRequired = "required"
+	FieldInvalid  = "invalid"
+	FieldUnknown  = "unknown"
+)
+
+type mapping struct {
+	err    error
+	status int
+	code   string
+	detail string
+}
+
+// domainErrors — соответствие доменных ошибок статусам. Порядок важен: проверяется первое совпадение.
+var domainErrors = []mapping{
+	{orders.ErrLuhn, http.StatusUnprocessableEntity, CodeInvalidOrderNumber, "Invalid order number"},
+	{orders.ErrOrderAnotherUser, http.StatusConflict, CodeOrderAnotherUser, "Order created by another user"},
+	{orders.ErrAlreadyProcessed, http.StatusConflict, CodeOrderProcessed, "Order already processed"},
+	{users.ErrIncorrectData, http.StatusUnauthorized, CodeInvalidCredentials, "Incorrect login/password"},
+	{users.ErrSecondFactorRequired, http.StatusUnauthorized, CodeSecondFactorRequired, "Two-factor code required"},
+	{users.ErrInvalidCode, http.StatusUnprocessableEntity, CodeInvalidSecondFactor, "Invalid two-factor code"},
+	{users.ErrSecondFactorLocked, http.StatusTooManyRequests, CodeSecondFactorLocked, "Too many invalid two-factor codes, try again later"},
+	{users.ErrTOTPAlreadyEnabled, http.StatusConflict, CodeTOTPAlreadyEnabled, "Two-factor authentication already enabled"},
+	{users.ErrTOTPNotEnrolled, http.StatusConflict, CodeTOTPNotEnrolled, "Two-factor authentication is not enrolled"},
+	{users.ErrTOTPNotEnabled, http.StatusConflict, CodeTOTPNotEnabled, "Two-factor authentication is not enabled"},
+	{users.ErrSessionRevoked, http.StatusUnauthorized, CodeUnauthorized, "Session revoked"},
+	{balance.ErrInsufficientFunds, http.StatusPaymentRequired, CodeInsufficientFunds, "Not enough money"},
+	{balanceStore.ErrInsufficientFunds, http.StatusPaymentRequired, CodeInsufficientFunds, "Not enough money"},
+	{balance.ErrInvalidSum, http.StatusUnprocessableEntity, CodeValidationFailed, "Sum must be positive"},
+	{balance.ErrRecipientNotFound, http.StatusNotFound, CodeNotFound, "Recipient not found"},
+	{balance.ErrSelfTransfer, http.StatusUnprocessableEntity, CodeSelfTransfer, "Cannot transfer points to yourself"},
+	{balance.ErrTransferLimit, http.StatusUnprocessableEntity, CodeTransferLimit, "Daily transfer limit exceeded"},
+	{balance.ErrInvalidOrder, http.StatusUnprocessableEntity, CodeInvalidOrderNumber, "Invalid order number"},
+	{balance.ErrHoldNotFound, http.StatusNotFound, CodeNotFound, "Hold not found"},
+	{balance.ErrHoldNotActive, http.StatusConflict, CodeHoldNotActive, "Hold is already captured, voided or expired"},
+	{balanceStore.ErrWithdrawalNotFound, http.StatusNotFound, CodeNotFound, "Withdrawal not found"},
+	{balanceStore.ErrReversalExceeded, http.StatusConflict, CodeReversalExceeded, "Reversal exceeds the not yet reversed part of the withdrawal"},
+	{adjustments.ErrInsufficientFunds, http.StatusPaymentRequired, CodeInsufficientFunds, "Not enough money"},
+	{adjustments.ErrSameApprover, http.StatusForbidden, CodeSameApprover, "Adjustment must be approved by another admin"},
+	{adjustments.ErrSelfAdjustment, http.StatusForbidden, CodeSelfAdjustment, "Admin cannot adjust or approve their own balance"},
+	{adjustments.ErrNotPending, http.StatusConflict, CodeNotPending, "Adjustment is not pending"},
+	{idempotency.ErrKeyReused, http.StatusUnprocessableEntity, CodeIdempotencyReused, "Idempotency key reused with different payload"},
+	{idempotency.ErrInProgress, http.StatusConflict, CodeIdempotencyInFlight, "Request with this idempotency key is in progress"},
+	{usersStore.ErrConflict, http.StatusConflict, CodeLoginTaken, "User login already exists"},
+	{usersStore.ErrUnknownReferral, http.StatusUnprocessableEntity, CodeUnknownReferral, "Unknown referral code"},
+	{usersStore.ErrDeletionNotScheduled, http.StatusConflict, CodeDeletionNotScheduled, "Account deletion is not scheduled"},
+	{ordersStore.ErrConflict, http.StatusConflict, CodeConflict, "Data conflict"},
+	{balanceStore.ErrConflict, http.StatusConflict, CodeConflict, "Data conflict"},
+	{adjustmentsStore.ErrConflict, http.StatusConflict, CodeConflict, "Data conflict"},
+	{usersStore.ErrNotFound, http.StatusNotFound, CodeNotFound, "User not found"},
+	{ordersStore.ErrNotFound, http.StatusNotFound, CodeNotFound, "Order not found"},
+	{adjustmentsStore.ErrNotFound, http.StatusNotFound, CodeNotFound, "Adjustment not found"},
+	{webhooksStore.ErrNotFound, http.StatusNotFound, CodeNotFound, "Webhook not found"},
+}
+
+func lookup(err error) (mapping, bool) {
+	for _, m := range domainErrors {
+		if errors.Is(err, m.err) {
+			return m, true
+		}
+	}
+	return mapping{}, false
+}
@@ -0,0 +1,77 @@
+package problem
+
+import (
+	"encoding/json"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// ContentType — тип ответа с ошибкой по RFC 7807
+const ContentType = "application/problem+json"
+
+const typePrefix = "urn:gophermart:problem:"
+
+// Problem — тело ответа с ошибкой. Code стабилен и предназначен для фронта, Detail — для человека и может меняться.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError описывает ошибку валидации конкретного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func New(r *http.Request, status int, code string, detail string) Problem {
+	return Problem{
+		Type:      typePrefix + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: logger.RequestID(r.Context()),
+	}
+}
+
+// Write отдаёт ошибку с явным статусом и кодом
+func Write(w http.ResponseWriter, r *http.Request, status int, code string, detail string) {
+	Render(w, New(r, status, code, detail))
+}
+
+// Validation отдаёт 400 со списком ошибок по полям
+func Validation(w http.ResponseWriter, r *http.Request, errs ...FieldError) {
+	p := New(r, http.StatusBadRequest, CodeValidationFailed, "Request validation failed")
+	p.Errors = errs
+	Render(w, p)
+}
+
+// Error подбирает статус и код по доменной ошибке. Неизвестные ошибки логируются и отдаются как 500
+// с переданным текстом, чтобы внутренности не утекали клиенту.
+func Error(w http.ResponseWriter, r *http.Request, err error, detail string) {
+	if m, ok := lookup(err); ok {
+		Write(w, r, m.status, m.code, m.detail)
+		return
+	}
+	logger.FromContext(r.Context()).Error(detail, zap.Error(err))
+	Write(w, r, http.StatusInternalServerError, CodeInternal, detail)
+}
+
+func Render(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(p); err != nil {
+		return
+	}
+}
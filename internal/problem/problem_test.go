@@ -0,0 +1,80 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/orders"
+	usersStore "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "luhn",
+			err:        orders.ErrLuhn,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   CodeInvalidOrderNumber,
+		},
+		{
+			name:       "wrapped storage conflict",
+			err:        fmt.Errorf("register: %w", usersStore.ErrConflict),
+			wantStatus: http.StatusConflict,
+			wantCode:   CodeLoginTaken,
+		},
+		{
+			name:       "unknown error",
+			err:        errors.New("connection reset"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   CodeInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/user/orders", nil)
+
+			Error(w, r, tt.err, "Cannot add order")
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != ContentType {
+				t.Errorf("Content-Type = %v, want %v", ct, ContentType)
+			}
+			var got Problem
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Code != tt.wantCode || got.Status != tt.wantStatus || got.Instance != "/api/user/orders" {
+				t.Errorf("Error() = %+v", got)
+			}
+			if tt.wantStatus == http.StatusInternalServerError && got.Detail != "Cannot add order" {
+				t.Errorf("internal error detail leaked: %v", got.Detail)
+			}
+		})
+	}
+}
+
+func TestValidation(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/user/login", nil)
+
+	Validation(w, r, FieldError{Field: "login", Code: FieldRequired, Message: "must be presented"})
+
+	var got Problem
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if w.Code != http.StatusBadRequest || got.Code != CodeValidationFailed || len(got.Errors) != 1 || got.Errors[0].Field != "login" {
+		t.Errorf("Validation() = %+v", got)
+	}
+}
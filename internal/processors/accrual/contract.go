@@ -1,5 +1,16 @@
 package accrual
 
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+)
+
+var ErrUnknownStatus = errors.New("unknown accrual status")
+
 type Processor interface {
+	// Do — проход опроса accrual по всем незавершённым заказам
 	Do()
+	// Apply применяет результат, который accrual прислал сам (push), тем же путём, что и опрос
+	Apply(ctx context.Context, result dto.AccrualOrderResponse) error
 }
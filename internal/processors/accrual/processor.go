@@ -2,57 +2,168 @@ package accrual
 
 import (
 	"context"
+	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/clients/accrual"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/health"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/campaigns"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
+	"time"
 )
 
+// knownStatuses — статусы, которые отдаёт accrual
+var knownStatuses = map[string]bool{"REGISTERED": true, "PROCESSING": true, "INVALID": true, "PROCESSED": true}
+
 type processor struct {
 	log            *zap.Logger
 	client         accrual.Client
 	orderStorage   orders.Storage
 	balanceStorage balance.Storage
+	campaigns      campaigns.Service
+	events         events.Publisher
 }
 
 func (p processor) Do() {
-	notTerminated, err := p.orderStorage.GetAllNotTerminated(context.Background())
+	// каждый проход опроса — отдельный корневой трейс
+	ctx, span := tracing.Start(context.Background(), "accrual.Poll")
+	defer span.End()
+
+	notTerminated, err := p.orderStorage.GetAllNotTerminated(ctx)
 	if err != nil {
-		logger.Log().Sugar().Errorw("Cannot get orders", zap.Error(err))
+		logger.FromContext(ctx).Sugar().Errorw("Cannot get orders", zap.Error(err))
 		return
 	}
+	health.MarkTick(time.Now())
+	p.observeQueue(*notTerminated)
+	span.SetAttributes(attribute.Int("accrual.queue_depth", len(*notTerminated)))
+
 	for _, order := range *notTerminated {
-		accOrder, err := p.client.SendOrder(order.Number)
-		if err != nil {
-			continue
-		}
-		updateOrder := models.Order{
-			Accrual: accOrder.Accrual,
-			Number:  accOrder.Order,
-			Status:  accOrder.Status,
-		}
-		if order.Status == "REGISTERED" {
-			logger.Log().Sugar().Infow("Order is just registered", updateOrder)
-			updateOrder.Status = "NEW"
-		}
-		err = p.orderStorage.Set(context.Background(), updateOrder)
-		if err != nil {
-			logger.Log().Sugar().Errorw("Can not update order", zap.Error(err))
-			continue
+		p.process(ctx, order)
+	}
+}
+
+func (p processor) process(ctx context.Context, order models.Order) {
+	ctx, span := tracing.Start(ctx, "accrual.ProcessOrder", attribute.String("order.number", order.Number))
+	defer span.End()
+
+	// у фонового опроса нет входящего запроса, поэтому request id заводим сами — он уйдёт в accrual
+	requestID := logger.NewRequestID()
+	ctx = logger.WithRequestID(ctx, requestID)
+	ctx = logger.WithLogger(ctx, logger.Log().With(zap.String("request_id", requestID), zap.String("order", order.Number)))
+
+	accOrder, err := p.client.SendOrder(ctx, order.Number)
+	if err != nil {
+		return
+	}
+	_, err = p.apply(ctx, order, *accOrder)
+	if err != nil {
+		logger.FromContext(ctx).Sugar().Errorw("Can not update order", zap.Error(err))
+	}
+}
+
+func (p processor) Apply(ctx context.Context, result dto.AccrualOrderResponse) error {
+	ctx, span := tracing.Start(ctx, "accrual.ApplyCallback", attribute.String("order.number", result.Order))
+	defer span.End()
+
+	if !knownStatuses[result.Status] {
+		metrics.AccrualCallbacks.WithLabelValues("rejected").Inc()
+		return ErrUnknownStatus
+	}
+	order, err := p.orderStorage.Get(ctx, result.Order)
+	if err != nil {
+		if errors.Is(err, orders.ErrNotFound) {
+			metrics.AccrualCallbacks.WithLabelValues("rejected").Inc()
 		}
-		ord, err := p.orderStorage.Get(context.Background(), order.Number)
+		return err
+	}
+	updated, err := p.apply(ctx, *order, result)
+	if err != nil {
+		return err
+	}
+	if updated {
+		metrics.AccrualCallbacks.WithLabelValues("applied").Inc()
+	} else {
+		// заказ уже в конечном статусе — повтор колбэка или опрос успел раньше
+		metrics.AccrualCallbacks.WithLabelValues("duplicate").Inc()
+	}
+	return nil
+}
+
+// apply — общий путь опроса и колбэка: обновление статуса и начисление идут одной идемпотентной
+// транзакцией Accrue, которая не трогает заказы в конечном статусе, поэтому гонка опроса с колбэком безопасна
+func (p processor) apply(ctx context.Context, order models.Order, accOrder dto.AccrualOrderResponse) (bool, error) {
+	updateOrder := models.Order{
+		Accrual: accOrder.Accrual,
+		Number:  accOrder.Order,
+		Status:  accOrder.Status,
+	}
+	if updateOrder.Status == "REGISTERED" {
+		logger.FromContext(ctx).Sugar().Infow("Order is just registered", updateOrder)
+		updateOrder.Status = "NEW"
+	}
+	// правила кампаний считаем до транзакции, а бонусы зачисляются в ней вместе с заказом
+	var bonuses []models.CampaignBonus
+	if updateOrder.Status == "PROCESSED" && order.Status != "PROCESSED" {
+		var err error
+		bonuses, err = p.campaigns.Evaluate(ctx, order, updateOrder.Accrual)
 		if err != nil {
-			logger.Log().Sugar().Errorw("Can not get order", zap.Error(err))
+			return false, err
 		}
-		err = p.balanceStorage.SetBalance(context.Background(), updateOrder.Accrual, ord.UserID)
-		if err != nil {
-			logger.Log().Sugar().Errorw("Can not update balances", zap.Error(err))
+	}
+	result, updated, err := p.balanceStorage.Accrue(ctx, updateOrder, bonuses)
+	if err != nil {
+		return false, err
+	}
+	if !updated {
+		return false, nil
+	}
+	if updateOrder.Status == "PROCESSED" {
+		metrics.PointsAccrued.Add(result.Credited)
+	}
+	if updateOrder.Status != order.Status || updateOrder.Accrual != order.Accrual {
+		p.events.Publish(ctx, order.UserID, models.EventOrder, models.OrderEvent{
+			Number:  updateOrder.Number,
+			Status:  updateOrder.Status,
+			Accrual: result.Credited,
+		})
+	}
+	for _, bonus := range bonuses {
+		metrics.CampaignBonuses.WithLabelValues(bonus.Kind).Add(bonus.Amount)
+	}
+	if updateOrder.Status == "PROCESSED" && (result.Credited > 0 || len(bonuses) > 0 || result.RefereeRewarded) {
+		events.PublishBalance(ctx, p.events, p.balanceStorage, order.UserID)
+	}
+	// награда за приглашение меняет и баланс пригласившего
+	if result.ReferrerID != "" {
+		events.PublishBalance(ctx, p.events, p.balanceStorage, result.ReferrerID)
+	}
+	return true, nil
+}
+
+func (p processor) observeQueue(orders []models.Order) {
+	metrics.AccrualQueueDepth.Set(float64(len(orders)))
+
+	var oldest time.Time
+	for _, order := range orders {
+		if oldest.IsZero() || order.UploadedAt.Before(oldest) {
+			oldest = order.UploadedAt
 		}
 	}
+	if oldest.IsZero() {
+		metrics.AccrualOldestPendingAge.Set(0)
+		return
+	}
+	metrics.AccrualOldestPendingAge.Set(time.Since(oldest).Seconds())
 }
 
-func New(log *zap.Logger, client accrual.Client, orderStorage orders.Storage, balanceStorage balance.Storage) Processor {
-	return &processor{log, client, orderStorage, balanceStorage}
+func New(log *zap.Logger, client accrual.Client, orderStorage orders.Storage, balanceStorage balance.Storage, campaigns campaigns.Service, events events.Publisher) Processor {
+	return &processor{log, client, orderStorage, balanceStorage, campaigns, events}
 }
@@ -0,0 +1,177 @@
+package accrual
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/dto"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/campaigns"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestProcessor_Apply(t *testing.T) {
+	ctx := context.Background()
+	pending := models.Order{Number: "12345678903", UserID: "1", Status: "PROCESSING"}
+	processed := models.Order{Number: "12345678903", Status: "PROCESSED", Accrual: 500}
+	bonuses := []models.CampaignBonus{{CampaignID: "7", Kind: models.CampaignFixed, Amount: 100}}
+
+	type fields struct {
+		orderStorage   func(ctrl *gomock.Controller) orders.Storage
+		balanceStorage func(ctrl *gomock.Controller) balance.Storage
+		campaigns      func(ctrl *gomock.Controller) campaigns.Service
+		events         func(ctrl *gomock.Controller) events.Publisher
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		result  dto.AccrualOrderResponse
+		wantErr error
+	}{
+		{
+			name: "processed is credited with tier multiplier and published",
+			fields: fields{
+				orderStorage: func(ctrl *gomock.Controller) orders.Storage {
+					mock := orders.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), pending.Number).Return(&pending, nil)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					mock := balance.NewMockStorage(ctrl)
+					// уровень пользователя с множителем 1.5 — зачислено больше, чем вернул accrual
+					mock.EXPECT().Accrue(gomock.Any(), processed, bonuses).Return(models.AccrualResult{Credited: 750}, true, nil)
+					mock.EXPECT().GetBalance(gomock.Any(), "1").Return(850.0, nil)
+					mock.EXPECT().GetSumWithdrawal(gomock.Any(), "1").Return(0.0, nil)
+					return mock
+				},
+				events: func(ctrl *gomock.Controller) events.Publisher {
+					mock := events.NewMockPublisher(ctrl)
+					mock.EXPECT().Publish(gomock.Any(), "1", models.EventOrder, models.OrderEvent{Number: pending.Number, Status: "PROCESSED", Accrual: 750})
+					mock.EXPECT().Publish(gomock.Any(), "1", models.EventBalance, models.BalanceEvent{Current: 850})
+					return mock
+				},
+				campaigns: func(ctrl *gomock.Controller) campaigns.Service {
+					mock := campaigns.NewMockService(ctrl)
+					mock.EXPECT().Evaluate(gomock.Any(), pending, 500.0).Return(bonuses, nil)
+					return mock
+				},
+			},
+			result: dto.AccrualOrderResponse{Order: pending.Number, Status: "PROCESSED", Accrual: 500},
+		},
+		{
+			name: "referral reward publishes referrer balance",
+			fields: fields{
+				orderStorage: func(ctrl *gomock.Controller) orders.Storage {
+					mock := orders.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), pending.Number).Return(&pending, nil)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					mock := balance.NewMockStorage(ctrl)
+					mock.EXPECT().Accrue(gomock.Any(), processed, nil).Return(models.AccrualResult{Credited: 500, RefereeRewarded: true, ReferrerID: "7"}, true, nil)
+					mock.EXPECT().GetBalance(gomock.Any(), "1").Return(550.0, nil)
+					mock.EXPECT().GetSumWithdrawal(gomock.Any(), "1").Return(0.0, nil)
+					mock.EXPECT().GetBalance(gomock.Any(), "7").Return(100.0, nil)
+					mock.EXPECT().GetSumWithdrawal(gomock.Any(), "7").Return(20.0, nil)
+					return mock
+				},
+				events: func(ctrl *gomock.Controller) events.Publisher {
+					mock := events.NewMockPublisher(ctrl)
+					mock.EXPECT().Publish(gomock.Any(), "1", models.EventOrder, models.OrderEvent{Number: pending.Number, Status: "PROCESSED", Accrual: 500})
+					mock.EXPECT().Publish(gomock.Any(), "1", models.EventBalance, models.BalanceEvent{Current: 550})
+					mock.EXPECT().Publish(gomock.Any(), "7", models.EventBalance, models.BalanceEvent{Current: 100, Withdrawn: 20})
+					return mock
+				},
+				campaigns: func(ctrl *gomock.Controller) campaigns.Service {
+					mock := campaigns.NewMockService(ctrl)
+					mock.EXPECT().Evaluate(gomock.Any(), pending, 500.0).Return(nil, nil)
+					return mock
+				},
+			},
+			result: dto.AccrualOrderResponse{Order: pending.Number, Status: "PROCESSED", Accrual: 500},
+		},
+		{
+			name: "repeated callback changes nothing",
+			fields: fields{
+				orderStorage: func(ctrl *gomock.Controller) orders.Storage {
+					mock := orders.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), pending.Number).Return(&models.Order{Number: pending.Number, UserID: "1", Status: "PROCESSED", Accrual: 500}, nil)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					mock := balance.NewMockStorage(ctrl)
+					// заказ уже начислен — правила кампаний не считаем
+					mock.EXPECT().Accrue(gomock.Any(), processed, nil).Return(models.AccrualResult{}, false, nil)
+					return mock
+				},
+				events: func(ctrl *gomock.Controller) events.Publisher {
+					return events.NewMockPublisher(ctrl)
+				},
+			},
+			result: dto.AccrualOrderResponse{Order: pending.Number, Status: "PROCESSED", Accrual: 500},
+		},
+		{
+			name: "unknown order",
+			fields: fields{
+				orderStorage: func(ctrl *gomock.Controller) orders.Storage {
+					mock := orders.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(nil, orders.ErrNotFound)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					return balance.NewMockStorage(ctrl)
+				},
+				events: func(ctrl *gomock.Controller) events.Publisher {
+					return events.NewMockPublisher(ctrl)
+				},
+			},
+			result:  dto.AccrualOrderResponse{Order: "1", Status: "PROCESSED"},
+			wantErr: orders.ErrNotFound,
+		},
+		{
+			name: "unknown status",
+			fields: fields{
+				orderStorage: func(ctrl *gomock.Controller) orders.Storage {
+					return orders.NewMockStorage(ctrl)
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					return balance.NewMockStorage(ctrl)
+				},
+				events: func(ctrl *gomock.Controller) events.Publisher {
+					return events.NewMockPublisher(ctrl)
+				},
+			},
+			result:  dto.AccrualOrderResponse{Order: pending.Number, Status: "DONE"},
+			wantErr: ErrUnknownStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			p := &processor{
+				log:            zap.NewNop(),
+				orderStorage:   tt.fields.orderStorage(ctrl),
+				balanceStorage: tt.fields.balanceStorage(ctrl),
+				campaigns:      campaignsService(ctrl, tt.fields.campaigns),
+				events:         tt.fields.events(ctrl),
+			}
+			err := p.Apply(ctx, tt.result)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// campaignsService по умолчанию — мок без ожиданий: в этих случаях правила кампаний считаться не должны
+func campaignsService(ctrl *gomock.Controller, build func(ctrl *gomock.Controller) campaigns.Service) campaigns.Service {
+	if build == nil {
+		return campaigns.NewMockService(ctrl)
+	}
+	return build(ctrl)
+}
@@ -0,0 +1,5 @@
+package audit
+
+type Processor interface {
+	Do()
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+)
+
+const batchSize = 100
+
+type processor struct {
+	log     *zap.Logger
+	storage audit.Storage
+}
+
+// Do сцепляет накопившиеся события аудита, пока очередь не опустеет
+func (p processor) Do() {
+	ctx, span := tracing.Start(context.Background(), "audit.Chain")
+	defer span.End()
+
+	for {
+		chained, err := p.storage.Chain(ctx, batchSize)
+		if err != nil {
+			logger.FromContext(ctx).Sugar().Errorw("Cannot chain audit events", zap.Error(err))
+			return
+		}
+		if chained < batchSize {
+			return
+		}
+	}
+}
+
+func New(log *zap.Logger, storage audit.Storage) Processor {
+	return &processor{log: log, storage: storage}
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestProcessor_Do(t *testing.T) {
+	tests := []struct {
+		name    string
+		chained []int
+		err     error
+	}{
+		{name: "empty outbox", chained: []int{0}},
+		// полная пачка — в очереди может остаться ещё, берём следующую
+		{name: "drains backlog", chained: []int{batchSize, batchSize, 3}},
+		{name: "stops on error", chained: []int{batchSize}, err: errors.New("timeout")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := audit.NewMockStorage(ctrl)
+			var calls []*gomock.Call
+			for _, chained := range tt.chained {
+				calls = append(calls, storage.EXPECT().Chain(gomock.Any(), batchSize).Return(chained, nil))
+			}
+			if tt.err != nil {
+				calls = append(calls, storage.EXPECT().Chain(gomock.Any(), batchSize).Return(0, tt.err))
+			}
+			gomock.InOrder(calls...)
+
+			New(zap.NewNop(), storage).Do()
+		})
+	}
+}
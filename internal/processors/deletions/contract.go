@@ -0,0 +1,5 @@
+package deletions
+
+type Processor interface {
+	Do()
+}
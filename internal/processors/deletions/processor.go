@@ -0,0 +1,38 @@
+package deletions
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+)
+
+const batchSize = 100
+
+type processor struct {
+	log          *zap.Logger
+	usersStorage users.Storage
+}
+
+// Do обезличивает аккаунты, у которых истёк срок на отмену удаления.
+// Заказы и списания остаются привязанными к тому же id, но уже без логина.
+func (p processor) Do() {
+	ctx, span := tracing.Start(context.Background(), "deletions.Run")
+	defer span.End()
+
+	anonymized, err := p.usersStorage.AnonymizeDue(ctx, batchSize)
+	if err != nil {
+		logger.FromContext(ctx).Sugar().Errorw("Cannot anonymize deleted accounts", zap.Error(err))
+		return
+	}
+	for _, userID := range anonymized {
+		logger.FromContext(ctx).Sugar().Infow("Account anonymized", "user_id", userID)
+	}
+	metrics.AccountsAnonymized.Add(float64(len(anonymized)))
+}
+
+func New(log *zap.Logger, usersStorage users.Storage) Processor {
+	return &processor{log: log, usersStorage: usersStorage}
+}
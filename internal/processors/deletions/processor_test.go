@@ -0,0 +1,50 @@
+package deletions
+
+import (
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestProcessor_Do(t *testing.T) {
+	tests := []struct {
+		name           string
+		anonymized     []string
+		err            error
+		wantAnonymized float64
+	}{
+		{
+			name:           "due accounts are anonymized",
+			anonymized:     []string{"1", "2"},
+			wantAnonymized: 2,
+		},
+		{
+			name:           "nothing is due",
+			wantAnonymized: 0,
+		},
+		{
+			// сбой не роняет процессор и не попадает в метрику, аккаунты обезличатся на следующем проходе
+			name:           "storage failure",
+			err:            errors.New("timeout"),
+			wantAnonymized: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := users.NewMockStorage(ctrl)
+			storage.EXPECT().AnonymizeDue(gomock.Any(), batchSize).Return(tt.anonymized, tt.err)
+
+			before := testutil.ToFloat64(metrics.AccountsAnonymized)
+			New(zap.NewNop(), storage).Do()
+			assert.Equal(t, tt.wantAnonymized, testutil.ToFloat64(metrics.AccountsAnonymized)-before)
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package expiry
+
+type Processor interface {
+	Do()
+}
@@ -0,0 +1,49 @@
+package expiry
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+)
+
+const batchSize = 100
+
+type processor struct {
+	log            *zap.Logger
+	balanceStorage balance.Storage
+	events         events.Publisher
+}
+
+// Do списывает сгоревшие партии. Каждый пользователь — отдельная транзакция, так что сбой на одном
+// не откатывает остальных, а недосписанное подберёт следующий проход.
+func (p processor) Do() {
+	ctx, span := tracing.Start(context.Background(), "expiry.Run")
+	defer span.End()
+
+	users, err := p.balanceStorage.GetUsersWithLapsedLots(ctx, batchSize)
+	if err != nil {
+		logger.FromContext(ctx).Sugar().Errorw("Cannot get users with lapsed lots", zap.Error(err))
+		return
+	}
+
+	for _, userID := range users {
+		expired, err := p.balanceStorage.ExpireLots(ctx, userID)
+		if err != nil {
+			logger.FromContext(ctx).Sugar().Errorw("Cannot expire lots", "user_id", userID, zap.Error(err))
+			continue
+		}
+		if expired == 0 {
+			continue
+		}
+		metrics.PointsExpired.Add(expired)
+		events.PublishBalance(ctx, p.events, p.balanceStorage, userID)
+	}
+}
+
+func New(log *zap.Logger, balanceStorage balance.Storage, events events.Publisher) Processor {
+	return &processor{log: log, balanceStorage: balanceStorage, events: events}
+}
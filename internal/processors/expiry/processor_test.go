@@ -0,0 +1,31 @@
+package expiry
+
+import (
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestProcessor_Do(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := balance.NewMockStorage(ctrl)
+	storage.EXPECT().GetUsersWithLapsedLots(gomock.Any(), batchSize).Return([]string{"1", "2", "3"}, nil)
+	storage.EXPECT().ExpireLots(gomock.Any(), "1").Return(150.0, nil)
+	storage.EXPECT().GetBalance(gomock.Any(), "1").Return(50.0, nil)
+	storage.EXPECT().GetSumWithdrawal(gomock.Any(), "1").Return(10.0, nil)
+	// сбой по одному пользователю не мешает остальным
+	storage.EXPECT().ExpireLots(gomock.Any(), "2").Return(0.0, errors.New("timeout"))
+	// партии успел списать параллельный проход — событие не шлём
+	storage.EXPECT().ExpireLots(gomock.Any(), "3").Return(0.0, nil)
+
+	publisher := events.NewMockPublisher(ctrl)
+	publisher.EXPECT().Publish(gomock.Any(), "1", models.EventBalance, models.BalanceEvent{Current: 50, Withdrawn: 10})
+
+	New(zap.NewNop(), storage, publisher).Do()
+}
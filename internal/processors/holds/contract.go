@@ -0,0 +1,5 @@
+package holds
+
+type Processor interface {
+	Do()
+}
@@ -0,0 +1,38 @@
+package holds
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+)
+
+const batchSize = 100
+
+type processor struct {
+	log            *zap.Logger
+	balanceStorage balance.Storage
+}
+
+// Do отпускает блокировки баллов, которые не подтвердили и не отменили вовремя. Доступная сумма
+// перестаёт их учитывать сразу по истечении срока, здесь они только закрываются.
+func (p processor) Do() {
+	ctx, span := tracing.Start(context.Background(), "holds.Run")
+	defer span.End()
+
+	expired, err := p.balanceStorage.ExpireHolds(ctx, batchSize)
+	if err != nil {
+		logger.FromContext(ctx).Sugar().Errorw("Cannot expire holds", zap.Error(err))
+		return
+	}
+	for _, hold := range expired {
+		logger.FromContext(ctx).Sugar().Infow("Hold expired", "hold_id", hold.ID, "user_id", hold.UserID, "sum", hold.Sum)
+	}
+	metrics.HoldsExpired.Add(float64(len(expired)))
+}
+
+func New(log *zap.Logger, balanceStorage balance.Storage) Processor {
+	return &processor{log: log, balanceStorage: balanceStorage}
+}
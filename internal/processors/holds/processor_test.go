@@ -0,0 +1,73 @@
+package holds
+
+import (
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestProcessor_Do(t *testing.T) {
+	type fields struct {
+		balanceStorage func(ctrl *gomock.Controller) balance.Storage
+	}
+	tests := []struct {
+		name        string
+		fields      fields
+		wantExpired float64
+	}{
+		{
+			name: "expired holds are counted",
+			fields: fields{
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					mock := balance.NewMockStorage(ctrl)
+					mock.EXPECT().ExpireHolds(gomock.Any(), batchSize).Return([]models.Hold{
+						{ID: "1", UserID: "1", Number: "12345678903", Sum: 100, Status: models.HoldExpired},
+						{ID: "2", UserID: "2", Number: "9278923470", Sum: 50, Status: models.HoldExpired},
+					}, nil)
+					return mock
+				},
+			},
+			wantExpired: 2,
+		},
+		{
+			// баланс у блокировок не меняется, поэтому кроме закрытия в хранилище сборщик ничего не трогает
+			name: "nothing to expire",
+			fields: fields{
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					mock := balance.NewMockStorage(ctrl)
+					mock.EXPECT().ExpireHolds(gomock.Any(), batchSize).Return(nil, nil)
+					return mock
+				},
+			},
+			wantExpired: 0,
+		},
+		{
+			// сбой не роняет процессор, следующий проход подберёт блокировки
+			name: "storage failure",
+			fields: fields{
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					mock := balance.NewMockStorage(ctrl)
+					mock.EXPECT().ExpireHolds(gomock.Any(), batchSize).Return(nil, errors.New("timeout"))
+					return mock
+				},
+			},
+			wantExpired: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			before := testutil.ToFloat64(metrics.HoldsExpired)
+			New(zap.NewNop(), tt.fields.balanceStorage(ctrl)).Do()
+			assert.Equal(t, tt.wantExpired, testutil.ToFloat64(metrics.HoldsExpired)-before)
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package tiers
+
+type Processor interface {
+	Do()
+}
@@ -0,0 +1,46 @@
+package tiers
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/tiers"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+	"time"
+)
+
+type processor struct {
+	log        *zap.Logger
+	storage    tiers.Storage
+	windowDays int
+}
+
+// Do пересчитывает уровни всех пользователей. Пересчёт идемпотентен, повторный запуск ничего не меняет.
+func (p processor) Do() {
+	ctx, span := tracing.Start(context.Background(), "tiers.Recalculate")
+	defer span.End()
+
+	changes, err := p.storage.Recalculate(ctx, p.windowDays)
+	if err != nil {
+		logger.FromContext(ctx).Sugar().Errorw("Cannot recalculate tiers", zap.Error(err))
+		return
+	}
+	for _, change := range *changes {
+		metrics.TierChanges.WithLabelValues(change.To).Inc()
+	}
+	logger.FromContext(ctx).Sugar().Infow("Tiers recalculated", "changed", len(*changes))
+}
+
+// NextRun — ближайший момент после now, когда на часах hour:00
+func NextRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func New(log *zap.Logger, storage tiers.Storage, windowDays int) Processor {
+	return &processor{log: log, storage: storage, windowDays: windowDays}
+}
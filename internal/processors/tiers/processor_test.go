@@ -0,0 +1,29 @@
+package tiers
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/tiers"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestProcessor_Do(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := tiers.NewMockStorage(ctrl)
+	storage.EXPECT().Recalculate(gomock.Any(), 365).Return(&[]models.TierChange{{UserID: "1", From: "BASE", To: "SILVER"}}, nil)
+
+	New(zap.NewNop(), storage, 365).Do()
+}
+
+func TestNextRun(t *testing.T) {
+	before := time.Date(2024, 3, 10, 1, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC), NextRun(before, 3))
+
+	after := time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 11, 3, 0, 0, 0, time.UTC), NextRun(after, 3))
+}
@@ -0,0 +1,5 @@
+package webhooks
+
+type Processor interface {
+	Do()
+}
@@ -0,0 +1,174 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/netguard"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/webhooks"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/pkg/webhook"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	batchSize = 50
+	workers   = 8
+	// lease — на сколько откладываем взятые доставки; должен с запасом покрывать обработку пачки
+	lease       = 2 * time.Minute
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+	// maxErrorLength — сколько текста ошибки храним в логе доставок
+	maxErrorLength = 512
+)
+
+type processor struct {
+	log         *zap.Logger
+	client      *http.Client
+	storage     webhooks.Storage
+	maxAttempts int
+}
+
+func New(log *zap.Logger, storage webhooks.Storage, timeout time.Duration, maxAttempts int) Processor {
+	return &processor{
+		log:         log,
+		client:      newClient(timeout, netguard.Control),
+		storage:     storage,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// newClient — клиент для адресов, которые задали пользователи. control проверяет каждый адрес при соединении;
+// прокси из окружения не используем, иначе проверялся бы адрес прокси, а не получателя
+func newClient(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: timeout, Control: control}).DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// редирект превратил бы POST в GET без тела — считаем его неудачной попыткой
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+func (p processor) Do() {
+	ctx, span := tracing.Start(context.Background(), "webhooks.Dispatch")
+	defer span.End()
+
+	due, err := p.storage.ClaimDue(ctx, batchSize, lease)
+	if err != nil {
+		logger.FromContext(ctx).Sugar().Errorw("Cannot claim webhook deliveries", zap.Error(err))
+		return
+	}
+	span.SetAttributes(attribute.Int("webhooks.batch", len(*due)))
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, delivery := range *due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(delivery models.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			p.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+}
+
+func (p processor) deliver(ctx context.Context, delivery models.WebhookDelivery) {
+	ctx, span := tracing.Tracer().Start(ctx, "webhooks.Deliver",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("webhook.id", delivery.WebhookID),
+			attribute.String("webhook.delivery_id", delivery.ID),
+			attribute.String("webhook.event", delivery.EventType)))
+	defer span.End()
+
+	ctx = logger.WithLogger(ctx, logger.Log().With(
+		zap.String("webhook_id", delivery.WebhookID), zap.String("delivery_id", delivery.ID)))
+
+	statusCode, err := p.send(ctx, delivery)
+	if err == nil {
+		metrics.WebhookDeliveries.WithLabelValues("delivered").Inc()
+		if err = p.storage.MarkDelivered(ctx, delivery.ID, statusCode); err != nil {
+			logger.FromContext(ctx).Error("Cannot mark webhook delivered", zap.Error(err))
+		}
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	errText := err.Error()
+	if len(errText) > maxErrorLength {
+		errText = errText[:maxErrorLength]
+	}
+
+	attempts := delivery.Attempts + 1
+	var next *time.Time
+	if attempts < p.maxAttempts {
+		at := time.Now().Add(backoff(attempts))
+		next = &at
+		metrics.WebhookDeliveries.WithLabelValues("retry").Inc()
+	} else {
+		metrics.WebhookDeliveries.WithLabelValues("failed").Inc()
+		logger.FromContext(ctx).Warn("Webhook delivery gave up", zap.Int("attempts", attempts), zap.String("error", errText))
+	}
+	if err = p.storage.MarkFailed(ctx, delivery.ID, statusCode, errText, next); err != nil {
+		logger.FromContext(ctx).Error("Cannot mark webhook attempt", zap.Error(err))
+	}
+}
+
+// send возвращает код ответа (0, если ответа не было) и ошибку для всего, кроме 2xx
+func (p processor) send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gophermart-webhooks/1.0")
+	req.Header.Set(webhook.EventHeader, delivery.EventType)
+	req.Header.Set(webhook.DeliveryHeader, delivery.ID)
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(delivery.Secret, delivery.Payload, time.Now()))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// дочитываем немного тела, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff — экспоненциальная задержка перед попыткой номер attempts+1
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
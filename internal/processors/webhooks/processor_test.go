@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/netguard"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/webhooks"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/pkg/webhook"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProcessor_Do(t *testing.T) {
+	payload := []byte(`{"id":"e1","type":"order.finalized"}`)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := webhook.Verify("secret", body, r.Header.Get(webhook.SignatureHeader), webhook.DefaultTolerance, time.Now())
+		if err != nil || r.Header.Get(webhook.EventHeader) != models.WebhookOrderFinalized {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	due := []models.WebhookDelivery{
+		{ID: "1", EventType: models.WebhookOrderFinalized, Payload: payload, URL: receiver.URL + "/ok", Secret: "secret"},
+		{ID: "2", EventType: models.WebhookOrderFinalized, Payload: payload, URL: receiver.URL + "/down", Secret: "secret", Attempts: 1},
+		{ID: "3", EventType: models.WebhookOrderFinalized, Payload: payload, URL: receiver.URL + "/down", Secret: "secret", Attempts: 2},
+		{ID: "4", EventType: models.WebhookOrderFinalized, Payload: payload, URL: receiver.URL + "/ok", Secret: "wrong"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storage := webhooks.NewMockStorage(ctrl)
+	storage.EXPECT().ClaimDue(gomock.Any(), batchSize, lease).Return(&due, nil)
+	storage.EXPECT().MarkDelivered(gomock.Any(), "1", http.StatusNoContent).Return(nil)
+	storage.EXPECT().MarkFailed(gomock.Any(), "2", http.StatusServiceUnavailable, "unexpected status 503", gomock.Not(gomock.Nil())).
+		DoAndReturn(func(_, _ any, _ int, _ string, next *time.Time) error {
+			// вторая неудача — ждём удвоенную базовую задержку
+			assert.WithinDuration(t, time.Now().Add(2*baseBackoff), *next, 5*time.Second)
+			return nil
+		})
+	// третья попытка из трёх — больше не пробуем
+	storage.EXPECT().MarkFailed(gomock.Any(), "3", http.StatusServiceUnavailable, "unexpected status 503", gomock.Nil()).Return(nil)
+	storage.EXPECT().MarkFailed(gomock.Any(), "4", http.StatusUnauthorized, "unexpected status 401", gomock.Not(gomock.Nil())).Return(nil)
+
+	// тестовый получатель слушает loopback, поэтому без проверки адресов
+	processor{log: zap.NewNop(), client: newClient(time.Second, nil), storage: storage, maxAttempts: 3}.Do()
+}
+
+func TestProcessor_DoRejectsInternalAddress(t *testing.T) {
+	requested := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	due := []models.WebhookDelivery{
+		{ID: "1", EventType: models.WebhookOrderFinalized, Payload: []byte(`{}`), URL: receiver.URL, Secret: "secret"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	storage := webhooks.NewMockStorage(ctrl)
+	storage.EXPECT().ClaimDue(gomock.Any(), batchSize, lease).Return(&due, nil)
+	storage.EXPECT().MarkFailed(gomock.Any(), "1", 0, gomock.Any(), gomock.Not(gomock.Nil())).
+		DoAndReturn(func(_, _ any, _ int, errText string, _ *time.Time) error {
+			assert.Contains(t, errText, netguard.ErrForbiddenAddress.Error())
+			return nil
+		})
+
+	New(zap.NewNop(), storage, time.Second, 3).Do()
+	assert.False(t, requested)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, baseBackoff, backoff(1))
+	assert.Equal(t, 4*baseBackoff, backoff(3))
+	assert.Equal(t, maxBackoff, backoff(30))
+}
@@ -1,18 +1,79 @@
 package server
 
 import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/accrualcallback"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminapproveadjustment"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admincreateadjustment"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admincreatecampaign"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admindeletecampaign"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetbalance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetcampaign"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetorders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetuser"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/admingetwithdrawals"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminlistadjustments"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminlistaudit"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminlistcampaigns"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminlistusers"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminrejectadjustment"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminrepollorder"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminreversewithdrawal"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminsetrole"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminupdatecampaign"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/adminverifyaudit"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/canceldeletion"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/capturehold"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/confirmtotp"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createhold"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createorder"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createtransfer"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createwebhook"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/createwithdraw"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/deleteaccount"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/deletewebhook"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/disabletotp"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/enrolltotp"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/exportdata"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/exportorders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/exportwithdrawals"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getadjustments"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getbalance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getmonthlystatement"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getopenapi"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getorders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getreferrals"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getstatement"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/gettierhistory"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/gettransfers"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getwebhookdeliveries"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getwebhooks"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/getwithdrawals"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/healthz"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/login"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/orderevents"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/readyz"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/redeliverwebhook"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/registration"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/handlers/voidhold"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/middlewares"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/problem"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/audit"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/idempotency"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/services/users"
+	"net/http"
 )
 import "github.com/go-chi/chi/v5"
 
 type Server struct {
+	audit       audit.Service
+	idempotency idempotency.Service
+	users       users.Service
+	openAPI     func(http.Handler) http.Handler
+	// accrualSecret — общий с accrual секрет подписи колбэков, пустой выключает приём
+	accrualSecret string
+
 	registration   *registration.Handler
 	login          *login.Handler
 	createOrder    *createorder.Handler
@@ -20,31 +81,205 @@ type Server struct {
 	getBalance     *getbalance.Handler
 	createWithdraw *createwithdraw.Handler
 	getWithdrawals *getwithdrawals.Handler
+	enrollTOTP     *enrolltotp.Handler
+	confirmTOTP    *confirmtotp.Handler
+	disableTOTP    *disabletotp.Handler
+	getAdjustments *getadjustments.Handler
+
+	adminListUsers      *adminlistusers.Handler
+	adminGetUser        *admingetuser.Handler
+	adminGetOrders      *admingetorders.Handler
+	adminGetWithdrawals *admingetwithdrawals.Handler
+	adminGetBalance     *admingetbalance.Handler
+	adminRepollOrder    *adminrepollorder.Handler
+	adminSetRole        *adminsetrole.Handler
+
+	adminCreateAdjustment  *admincreateadjustment.Handler
+	adminListAdjustments   *adminlistadjustments.Handler
+	adminApproveAdjustment *adminapproveadjustment.Handler
+	adminRejectAdjustment  *adminrejectadjustment.Handler
+	adminListAudit         *adminlistaudit.Handler
+	adminVerifyAudit       *adminverifyaudit.Handler
+	healthz                *healthz.Handler
+	readyz                 *readyz.Handler
+	getOpenAPI             *getopenapi.Handler
+	orderEvents            *orderevents.Handler
+	createWebhook          *createwebhook.Handler
+	getWebhooks            *getwebhooks.Handler
+	deleteWebhook          *deletewebhook.Handler
+	getWebhookDeliveries   *getwebhookdeliveries.Handler
+	redeliverWebhook       *redeliverwebhook.Handler
+	accrualCallback        *accrualcallback.Handler
+	getTierHistory         *gettierhistory.Handler
+	adminCreateCampaign    *admincreatecampaign.Handler
+	adminListCampaigns     *adminlistcampaigns.Handler
+	adminGetCampaign       *admingetcampaign.Handler
+	adminUpdateCampaign    *adminupdatecampaign.Handler
+	adminDeleteCampaign    *admindeletecampaign.Handler
+	getReferrals           *getreferrals.Handler
+	createTransfer         *createtransfer.Handler
+	getTransfers           *gettransfers.Handler
+	createHold             *createhold.Handler
+	captureHold            *capturehold.Handler
+	voidHold               *voidhold.Handler
+	adminReverseWithdrawal *adminreversewithdrawal.Handler
+	getStatement           *getstatement.Handler
+	getMonthlyStatement    *getmonthlystatement.Handler
+	exportOrders           *exportorders.Handler
+	exportWithdrawals      *exportwithdrawals.Handler
+	deleteAccount          *deleteaccount.Handler
+	cancelDeletion         *canceldeletion.Handler
+	exportData             *exportdata.Handler
 }
 
 func New(
+	audit audit.Service,
+	idempotency idempotency.Service,
+	users users.Service,
+	openAPI func(http.Handler) http.Handler,
+	accrualSecret string,
 	registration *registration.Handler,
 	login *login.Handler,
 	createOrder *createorder.Handler,
 	getOrders *getorders.Handler,
 	getBalance *getbalance.Handler,
 	createWithdraw *createwithdraw.Handler,
-	getWithdrawals *getwithdrawals.Handler) *Server {
+	getWithdrawals *getwithdrawals.Handler,
+	enrollTOTP *enrolltotp.Handler,
+	confirmTOTP *confirmtotp.Handler,
+	disableTOTP *disabletotp.Handler,
+	getAdjustments *getadjustments.Handler,
+	adminListUsers *adminlistusers.Handler,
+	adminGetUser *admingetuser.Handler,
+	adminGetOrders *admingetorders.Handler,
+	adminGetWithdrawals *admingetwithdrawals.Handler,
+	adminGetBalance *admingetbalance.Handler,
+	adminRepollOrder *adminrepollorder.Handler,
+	adminSetRole *adminsetrole.Handler,
+	adminCreateAdjustment *admincreateadjustment.Handler,
+	adminListAdjustments *adminlistadjustments.Handler,
+	adminApproveAdjustment *adminapproveadjustment.Handler,
+	adminRejectAdjustment *adminrejectadjustment.Handler,
+	adminListAudit *adminlistaudit.Handler,
+	adminVerifyAudit *adminverifyaudit.Handler,
+	healthz *healthz.Handler,
+	readyz *readyz.Handler,
+	getOpenAPI *getopenapi.Handler,
+	orderEvents *orderevents.Handler,
+	createWebhook *createwebhook.Handler,
+	getWebhooks *getwebhooks.Handler,
+	deleteWebhook *deletewebhook.Handler,
+	getWebhookDeliveries *getwebhookdeliveries.Handler,
+	redeliverWebhook *redeliverwebhook.Handler,
+	accrualCallback *accrualcallback.Handler,
+	getTierHistory *gettierhistory.Handler,
+	adminCreateCampaign *admincreatecampaign.Handler,
+	adminListCampaigns *adminlistcampaigns.Handler,
+	adminGetCampaign *admingetcampaign.Handler,
+	adminUpdateCampaign *adminupdatecampaign.Handler,
+	adminDeleteCampaign *admindeletecampaign.Handler,
+	getReferrals *getreferrals.Handler,
+	createTransfer *createtransfer.Handler,
+	getTransfers *gettransfers.Handler,
+	createHold *createhold.Handler,
+	captureHold *capturehold.Handler,
+	voidHold *voidhold.Handler,
+	adminReverseWithdrawal *adminreversewithdrawal.Handler,
+	getStatement *getstatement.Handler,
+	getMonthlyStatement *getmonthlystatement.Handler,
+	exportOrders *exportorders.Handler,
+	exportWithdrawals *exportwithdrawals.Handler,
+	deleteAccount *deleteaccount.Handler,
+	cancelDeletion *canceldeletion.Handler,
+	exportData *exportdata.Handler) *Server {
 	return &Server{
+		audit:         audit,
+		idempotency:   idempotency,
+		users:         users,
+		openAPI:       openAPI,
+		accrualSecret: accrualSecret,
+
 		registration:   registration,
 		login:          login,
 		createOrder:    createOrder,
 		getOrders:      getOrders,
 		getBalance:     getBalance,
 		createWithdraw: createWithdraw,
-		getWithdrawals: getWithdrawals}
+		getWithdrawals: getWithdrawals,
+		enrollTOTP:     enrollTOTP,
+		confirmTOTP:    confirmTOTP,
+		disableTOTP:    disableTOTP,
+		getAdjustments: getAdjustments,
+
+		adminListUsers:      adminListUsers,
+		adminGetUser:        adminGetUser,
+		adminGetOrders:      adminGetOrders,
+		adminGetWithdrawals: adminGetWithdrawals,
+		adminGetBalance:     adminGetBalance,
+		adminRepollOrder:    adminRepollOrder,
+		adminSetRole:        adminSetRole,
+
+		adminCreateAdjustment:  adminCreateAdjustment,
+		adminListAdjustments:   adminListAdjustments,
+		adminApproveAdjustment: adminApproveAdjustment,
+		adminRejectAdjustment:  adminRejectAdjustment,
+		adminListAudit:         adminListAudit,
+		adminVerifyAudit:       adminVerifyAudit,
+		healthz:                healthz,
+		readyz:                 readyz,
+		getOpenAPI:             getOpenAPI,
+		orderEvents:            orderEvents,
+		createWebhook:          createWebhook,
+		getWebhooks:            getWebhooks,
+		deleteWebhook:          deleteWebhook,
+		getWebhookDeliveries:   getWebhookDeliveries,
+		redeliverWebhook:       redeliverWebhook,
+		accrualCallback:        accrualCallback,
+		getTierHistory:         getTierHistory,
+		adminCreateCampaign:    adminCreateCampaign,
+		adminListCampaigns:     adminListCampaigns,
+		adminGetCampaign:       adminGetCampaign,
+		adminUpdateCampaign:    adminUpdateCampaign,
+		adminDeleteCampaign:    adminDeleteCampaign,
+		getReferrals:           getReferrals,
+		createTransfer:         createTransfer,
+		getTransfers:           getTransfers,
+		createHold:             createHold,
+		captureHold:            captureHold,
+		voidHold:               voidHold,
+		adminReverseWithdrawal: adminReverseWithdrawal,
+		getStatement:           getStatement,
+		getMonthlyStatement:    getMonthlyStatement,
+		exportOrders:           exportOrders,
+		exportWithdrawals:      exportWithdrawals,
+		deleteAccount:          deleteAccount,
+		cancelDeletion:         cancelDeletion,
+		exportData:             exportData}
 }
 
 func (s *Server) Mux() *chi.Mux {
 	r := chi.NewRouter()
 
+	r.Use(middlewares.TracingMiddleware)
+	r.Use(middlewares.RequestIDMiddleware)
+	r.Use(middlewares.MetricsMiddleware)
 	r.Use(middlewares.LoggerMiddleware)
 	r.Use(middlewares.GzipMiddleware)
+	r.Use(s.openAPI)
+
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, http.StatusNotFound, problem.CodeNotFound, "Route not found")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+	})
+
+	r.Get("/healthz", s.healthz.Handle)
+	r.Get("/readyz", s.readyz.Handle)
+	r.Get("/api/openapi.json", s.getOpenAPI.Handle)
+
+	// результаты accrual в push-режиме, аутентификация — подпись тела общим секретом
+	r.With(middlewares.SignatureMiddleware(s.accrualSecret)).Post("/internal/accrual/callback", s.accrualCallback.Handle)
 
 	r.Group(func(r chi.Router) {
 		r.Post("/api/user/register", s.registration.Handle)
@@ -52,12 +287,76 @@ func (s *Server) Mux() *chi.Mux {
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(middlewares.AuthorizedMiddleware)
+		r.Use(middlewares.SessionMiddleware(s.users))
+		r.Use(middlewares.IdempotencyMiddleware(s.idempotency))
+		r.Delete("/api/user", s.deleteAccount.Handle)
+		r.Post("/api/user/deletion/cancel", s.cancelDeletion.Handle)
+		r.Get("/api/user/export", s.exportData.Handle)
 		r.Post("/api/user/orders", s.createOrder.Handle)
 		r.Get("/api/user/orders", s.getOrders.Handle)
+		r.Get("/api/user/orders/events", s.orderEvents.Handle)
+		r.Get("/api/user/orders/export", s.exportOrders.Handle)
 		r.Get("/api/user/balance", s.getBalance.Handle)
+		r.Get("/api/user/tier/history", s.getTierHistory.Handle)
+		r.Get("/api/user/referrals", s.getReferrals.Handle)
 		r.Post("/api/user/balance/withdraw", s.createWithdraw.Handle)
 		r.Get("/api/user/withdrawals", s.getWithdrawals.Handle)
+		r.Get("/api/user/withdrawals/export", s.exportWithdrawals.Handle)
+		r.Get("/api/user/statement", s.getStatement.Handle)
+		r.Get("/api/user/statement/{month}", s.getMonthlyStatement.Handle)
+		r.Post("/api/user/balance/transfer", s.createTransfer.Handle)
+		r.Get("/api/user/balance/transfers", s.getTransfers.Handle)
+		r.Post("/api/user/balance/holds", s.createHold.Handle)
+		r.Post("/api/user/balance/holds/{holdID}/capture", s.captureHold.Handle)
+		r.Post("/api/user/balance/holds/{holdID}/void", s.voidHold.Handle)
+		r.Post("/api/user/2fa/enroll", s.enrollTOTP.Handle)
+		r.Post("/api/user/2fa/verify", s.confirmTOTP.Handle)
+		r.Post("/api/user/2fa/disable", s.disableTOTP.Handle)
+		r.Get("/api/user/adjustments", s.getAdjustments.Handle)
+		r.Post("/api/user/webhooks", s.createWebhook.Handle)
+		r.Get("/api/user/webhooks", s.getWebhooks.Handle)
+		r.Delete("/api/user/webhooks/{webhookID}", s.deleteWebhook.Handle)
+		r.Get("/api/user/webhooks/{webhookID}/deliveries", s.getWebhookDeliveries.Handle)
+		r.Post("/api/user/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver", s.redeliverWebhook.Handle)
+
+	})
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(middlewares.AuthorizedMiddleware)
+		r.Use(middlewares.SessionMiddleware(s.users))
+		r.Use(middlewares.AdminAuditMiddleware(s.audit))
+		r.Use(middlewares.RequireRole(models.RoleSupport, models.RoleAdmin))
+		r.Use(middlewares.IdempotencyMiddleware(s.idempotency))
+
+		r.Get("/users", s.adminListUsers.Handle)
+		r.Get("/users/{userID}", s.adminGetUser.Handle)
+		r.Get("/users/{userID}/orders", s.adminGetOrders.Handle)
+		r.Get("/users/{userID}/withdrawals", s.adminGetWithdrawals.Handle)
+		r.Get("/users/{userID}/balance", s.adminGetBalance.Handle)
+		r.Get("/adjustments", s.adminListAdjustments.Handle)
+		r.Get("/campaigns", s.adminListCampaigns.Handle)
+		r.Get("/campaigns/{campaignID}", s.adminGetCampaign.Handle)
 
+		r.Group(func(r chi.Router) {
+			r.Use(middlewares.RequireRole(models.RoleAdmin))
+			r.Post("/orders/{number}/repoll", s.adminRepollOrder.Handle)
+			r.Put("/users/{userID}/role", s.adminSetRole.Handle)
+			r.Post("/users/{userID}/adjustments", s.adminCreateAdjustment.Handle)
+			r.Post("/adjustments/{adjustmentID}/approve", s.adminApproveAdjustment.Handle)
+			r.Post("/adjustments/{adjustmentID}/reject", s.adminRejectAdjustment.Handle)
+			r.Post("/withdrawals/{withdrawalID}/reverse", s.adminReverseWithdrawal.Handle)
+			r.Post("/campaigns", s.adminCreateCampaign.Handle)
+			r.Put("/campaigns/{campaignID}", s.adminUpdateCampaign.Handle)
+			r.Delete("/campaigns/{campaignID}", s.adminDeleteCampaign.Handle)
+			r.Get("/audit", s.adminListAudit.Handle)
+			r.Get("/audit/verify", s.adminVerifyAudit.Handle)
+		})
 	})
 	return r
 }
+
+// AdminMux — служебные эндпоинты, которые слушаются на отдельном адресе и не торчат наружу
+func (s *Server) AdminMux() *chi.Mux {
+	r := chi.NewRouter()
+	r.Handle("/metrics", metrics.Handler())
+	return r
+}
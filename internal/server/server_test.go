@@ -0,0 +1,39 @@
+package server
+
+import (
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/api"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Каждый маршрут из Mux должен быть описан в OpenAPI, иначе он уходит мимо валидации и клиента
+func TestMuxRoutesAreInOpenAPI(t *testing.T) {
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+
+	s := &Server{openAPI: func(h http.Handler) http.Handler { return h }}
+	walked := 0
+	err = chi.Walk(s.Mux(), func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		walked++
+		route = strings.TrimSuffix(route, "/")
+		item := doc.Paths.Find(route)
+		if item == nil {
+			t.Errorf("route %s is not described in openapi.json", route)
+			return nil
+		}
+		if item.GetOperation(method) == nil {
+			t.Errorf("%s %s is not described in openapi.json", method, route)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if walked == 0 {
+		t.Fatal("no routes walked")
+	}
+}
@@ -0,0 +1,27 @@
+package adjustments
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=adjustments
+
+var (
+	ErrInvalidType       = errors.New("invalid adjustment type")
+	ErrInvalidReason     = errors.New("invalid reason code")
+	ErrInvalidAmount     = errors.New("amount must be positive")
+	ErrSameApprover      = errors.New("adjustment must be approved by another admin")
+	ErrSelfAdjustment    = errors.New("admin cannot adjust or approve their own balance")
+	ErrNotPending        = errors.New("adjustment is not pending")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+)
+
+type Service interface {
+	Create(ctx context.Context, adjustment models.Adjustment) (*models.Adjustment, error)
+	Approve(ctx context.Context, adjustmentID string, approverID string) (*models.Adjustment, error)
+	Reject(ctx context.Context, adjustmentID string, approverID string) (*models.Adjustment, error)
+	GetAllByStatus(ctx context.Context, status string) (*[]models.Adjustment, error)
+	GetAllAppliedByUser(ctx context.Context, userID string) (*[]models.Adjustment, error)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package adjustments is a generated GoMock package.
+package adjustments
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Approve mocks base method.
+func (m *MockService) Approve(ctx context.Context, adjustmentID, approverID string) (*models.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Approve", ctx, adjustmentID, approverID)
+	ret0, _ := ret[0].(*models.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Approve indicates an expected call of Approve.
+func (mr *MockServiceMockRecorder) Approve(ctx, adjustmentID, approverID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Approve", reflect.TypeOf((*MockService)(nil).Approve), ctx, adjustmentID, approverID)
+}
+
+// Create mocks base method.
+func (m *MockService) Create(ctx context.Context, adjustment models.Adjustment) (*models.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, adjustment)
+	ret0, _ := ret[0].(*models.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockServiceMockRecorder) Create(ctx, adjustment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), ctx, adjustment)
+}
+
+// GetAllAppliedByUser mocks base method.
+func (m *MockService) GetAllAppliedByUser(ctx context.Context, userID string) (*[]models.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllAppliedByUser", ctx, userID)
+	ret0, _ := ret[0].(*[]models.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllAppliedByUser indicates an expected call of GetAllAppliedByUser.
+func (mr *MockServiceMockRecorder) GetAllAppliedByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllAppliedByUser", reflect.TypeOf((*MockService)(nil).GetAllAppliedByUser), ctx, userID)
+}
+
+// GetAllByStatus mocks base method.
+func (m *MockService) GetAllByStatus(ctx context.Context, status string) (*[]models.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByStatus", ctx, status)
+	ret0, _ := ret[0].(*[]models.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByStatus indicates an expected call of GetAllByStatus.
+func (mr *MockServiceMockRecorder) GetAllByStatus(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByStatus", reflect.TypeOf((*MockService)(nil).GetAllByStatus), ctx, status)
+}
+
+// Reject mocks base method.
+func (m *MockService) Reject(ctx context.Context, adjustmentID, approverID string) (*models.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reject", ctx, adjustmentID, approverID)
+	ret0, _ := ret[0].(*models.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reject indicates an expected call of Reject.
+func (mr *MockServiceMockRecorder) Reject(ctx, adjustmentID, approverID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reject", reflect.TypeOf((*MockService)(nil).Reject), ctx, adjustmentID, approverID)
+}
@@ -0,0 +1,162 @@
+package adjustments
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/adjustments"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+)
+
+type service struct {
+	log               *zap.Logger
+	storage           adjustments.Storage
+	balanceStorage    balance.Storage
+	events            events.Publisher
+	approvalThreshold float64
+}
+
+func New(log *zap.Logger, storage adjustments.Storage, balanceStorage balance.Storage, events events.Publisher, approvalThreshold float64) Service {
+	return &service{log: log, storage: storage, balanceStorage: balanceStorage, events: events, approvalThreshold: approvalThreshold}
+}
+
+// Create заводит корректировку. Суммы не выше порога применяются сразу, остальные ждут подтверждения вторым админом.
+// Свой баланс админ корректировать не может — иначе мелкими суммами он начислил бы себе что угодно без второго админа.
+func (s *service) Create(ctx context.Context, adjustment models.Adjustment) (*models.Adjustment, error) {
+	ctx, span := tracing.Start(ctx, "adjustments.Service.Create")
+	defer span.End()
+
+	if !adjustment.IsValidType() {
+		return nil, ErrInvalidType
+	}
+	if !adjustment.IsValidReason() {
+		return nil, ErrInvalidReason
+	}
+	if adjustment.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if adjustment.UserID == adjustment.CreatedBy {
+		return nil, ErrSelfAdjustment
+	}
+
+	created, err := s.storage.Add(ctx, adjustment)
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("balance adjustment created",
+		zap.String("adjustment_id", created.ID),
+		zap.String("user_id", created.UserID),
+		zap.String("type", created.Type),
+		zap.Float64("amount", created.Amount),
+		zap.String("reason", created.Reason),
+		zap.String("created_by", created.CreatedBy))
+
+	if created.Amount > s.approvalThreshold {
+		return created, nil
+	}
+
+	err = s.apply(ctx, *created, created.CreatedBy)
+	if errors.Is(err, ErrInsufficientFunds) {
+		_ = s.storage.Reject(ctx, created.ID, created.CreatedBy)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.storage.Get(ctx, created.ID)
+}
+
+func (s *service) Approve(ctx context.Context, adjustmentID string, approverID string) (*models.Adjustment, error) {
+	ctx, span := tracing.Start(ctx, "adjustments.Service.Approve")
+	defer span.End()
+
+	adjustment, err := s.getPending(ctx, adjustmentID, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.apply(ctx, *adjustment, approverID)
+	if err != nil {
+		return nil, err
+	}
+	return s.storage.Get(ctx, adjustmentID)
+}
+
+func (s *service) Reject(ctx context.Context, adjustmentID string, approverID string) (*models.Adjustment, error) {
+	ctx, span := tracing.Start(ctx, "adjustments.Service.Reject")
+	defer span.End()
+
+	adjustment, err := s.getPending(ctx, adjustmentID, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.storage.Reject(ctx, adjustment.ID, approverID)
+	if errors.Is(err, adjustments.ErrConflict) {
+		return nil, ErrNotPending
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("balance adjustment rejected",
+		zap.String("adjustment_id", adjustment.ID),
+		zap.String("user_id", adjustment.UserID),
+		zap.String("decided_by", approverID))
+	return s.storage.Get(ctx, adjustmentID)
+}
+
+func (s *service) GetAllByStatus(ctx context.Context, status string) (*[]models.Adjustment, error) {
+	ctx, span := tracing.Start(ctx, "adjustments.Service.GetAllByStatus")
+	defer span.End()
+
+	return s.storage.GetAllByStatus(ctx, status)
+}
+
+func (s *service) GetAllAppliedByUser(ctx context.Context, userID string) (*[]models.Adjustment, error) {
+	ctx, span := tracing.Start(ctx, "adjustments.Service.GetAllAppliedByUser")
+	defer span.End()
+
+	return s.storage.GetAllAppliedByUser(ctx, userID)
+}
+
+func (s *service) getPending(ctx context.Context, adjustmentID string, approverID string) (*models.Adjustment, error) {
+	adjustment, err := s.storage.Get(ctx, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.Status != models.AdjustmentPending {
+		return nil, ErrNotPending
+	}
+	if adjustment.CreatedBy == approverID {
+		return nil, ErrSameApprover
+	}
+	// подтверждает третий админ: ни автор, ни тот, чей баланс меняется
+	if adjustment.UserID == approverID {
+		return nil, ErrSelfAdjustment
+	}
+	return adjustment, nil
+}
+
+func (s *service) apply(ctx context.Context, adjustment models.Adjustment, deciderID string) error {
+	err := s.balanceStorage.ApplyAdjustment(ctx, adjustment, deciderID)
+	if errors.Is(err, balance.ErrConflict) {
+		return ErrNotPending
+	}
+	if errors.Is(err, balance.ErrInsufficientFunds) {
+		return ErrInsufficientFunds
+	}
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("balance adjustment applied",
+		zap.String("adjustment_id", adjustment.ID),
+		zap.String("user_id", adjustment.UserID),
+		zap.Float64("sum", adjustment.SignedAmount()),
+		zap.String("decided_by", deciderID))
+	events.PublishBalance(ctx, s.events, s.balanceStorage, adjustment.UserID)
+	return nil
+}
@@ -0,0 +1,246 @@
+package adjustments
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/adjustments"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func Test_service_Create(t *testing.T) {
+	ctx := context.Background()
+
+	small := models.Adjustment{UserID: "1", Type: models.AdjustmentCredit, Amount: 10, Reason: models.ReasonGoodwill, CreatedBy: "2"}
+	smallCreated := small
+	smallCreated.ID = "1"
+	smallCreated.Status = models.AdjustmentPending
+	smallApplied := smallCreated
+	smallApplied.Status = models.AdjustmentApplied
+
+	big := models.Adjustment{UserID: "1", Type: models.AdjustmentDebit, Amount: 500, Reason: models.ReasonFraud, CreatedBy: "2"}
+	bigCreated := big
+	bigCreated.ID = "2"
+	bigCreated.Status = models.AdjustmentPending
+
+	type fields struct {
+		storage        func(ctrl *gomock.Controller) adjustments.Storage
+		balanceStorage func(ctrl *gomock.Controller) balance.Storage
+		events         func(ctrl *gomock.Controller) events.Publisher
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		adjustment models.Adjustment
+		wantStatus string
+		wantErr    error
+	}{
+		{
+			name: "below threshold applied at once",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) adjustments.Storage {
+					mock := adjustments.NewMockStorage(ctrl)
+					mock.EXPECT().Add(gomock.Any(), small).Return(&smallCreated, nil)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&smallApplied, nil)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					mock := balance.NewMockStorage(ctrl)
+					mock.EXPECT().ApplyAdjustment(gomock.Any(), smallCreated, "2").Return(nil)
+					mock.EXPECT().GetBalance(gomock.Any(), "1").Return(510.0, nil)
+					mock.EXPECT().GetSumWithdrawal(gomock.Any(), "1").Return(0.0, nil)
+					return mock
+				},
+				events: func(ctrl *gomock.Controller) events.Publisher {
+					mock := events.NewMockPublisher(ctrl)
+					mock.EXPECT().Publish(gomock.Any(), "1", models.EventBalance, models.BalanceEvent{Current: 510})
+					return mock
+				},
+			},
+			adjustment: small,
+			wantStatus: models.AdjustmentApplied,
+		},
+		{
+			name: "above threshold stays pending",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) adjustments.Storage {
+					mock := adjustments.NewMockStorage(ctrl)
+					mock.EXPECT().Add(gomock.Any(), big).Return(&bigCreated, nil)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					return balance.NewMockStorage(ctrl)
+				},
+			},
+			adjustment: big,
+			wantStatus: models.AdjustmentPending,
+		},
+		{
+			name: "unknown reason",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) adjustments.Storage {
+					return adjustments.NewMockStorage(ctrl)
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					return balance.NewMockStorage(ctrl)
+				},
+			},
+			adjustment: models.Adjustment{UserID: "1", Type: models.AdjustmentCredit, Amount: 10, Reason: "BECAUSE"},
+			wantErr:    ErrInvalidReason,
+		},
+		{
+			// ниже порога корректировка применилась бы сразу, без второго админа
+			name: "admin cannot adjust own balance",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) adjustments.Storage {
+					return adjustments.NewMockStorage(ctrl)
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					return balance.NewMockStorage(ctrl)
+				},
+			},
+			adjustment: models.Adjustment{UserID: "2", Type: models.AdjustmentCredit, Amount: 10, Reason: models.ReasonGoodwill, CreatedBy: "2"},
+			wantErr:    ErrSelfAdjustment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := &service{
+				log:               zap.NewNop(),
+				storage:           tt.fields.storage(ctrl),
+				balanceStorage:    tt.fields.balanceStorage(ctrl),
+				events:            publisher(ctrl, tt.fields.events),
+				approvalThreshold: 100,
+			}
+			got, err := s.Create(ctx, tt.adjustment)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Status != tt.wantStatus {
+				t.Errorf("Create() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_service_Approve(t *testing.T) {
+	ctx := context.Background()
+	pending := models.Adjustment{ID: "1", UserID: "1", Type: models.AdjustmentCredit, Amount: 500, Status: models.AdjustmentPending, CreatedBy: "2"}
+	applied := pending
+	applied.Status = models.AdjustmentApplied
+
+	type fields struct {
+		storage        func(ctrl *gomock.Controller) adjustments.Storage
+		balanceStorage func(ctrl *gomock.Controller) balance.Storage
+		events         func(ctrl *gomock.Controller) events.Publisher
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		approverID string
+		wantErr    error
+	}{
+		{
+			name: "second admin approves",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) adjustments.Storage {
+					mock := adjustments.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&pending, nil)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&applied, nil)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					mock := balance.NewMockStorage(ctrl)
+					mock.EXPECT().ApplyAdjustment(gomock.Any(), pending, "3").Return(nil)
+					mock.EXPECT().GetBalance(gomock.Any(), "1").Return(510.0, nil)
+					mock.EXPECT().GetSumWithdrawal(gomock.Any(), "1").Return(0.0, nil)
+					return mock
+				},
+				events: func(ctrl *gomock.Controller) events.Publisher {
+					mock := events.NewMockPublisher(ctrl)
+					mock.EXPECT().Publish(gomock.Any(), "1", models.EventBalance, models.BalanceEvent{Current: 510})
+					return mock
+				},
+			},
+			approverID: "3",
+		},
+		{
+			name: "creator cannot approve",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) adjustments.Storage {
+					mock := adjustments.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&pending, nil)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					return balance.NewMockStorage(ctrl)
+				},
+			},
+			approverID: "2",
+			wantErr:    ErrSameApprover,
+		},
+		{
+			name: "beneficiary cannot approve",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) adjustments.Storage {
+					mock := adjustments.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&pending, nil)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					return balance.NewMockStorage(ctrl)
+				},
+			},
+			approverID: "1",
+			wantErr:    ErrSelfAdjustment,
+		},
+		{
+			name: "insufficient funds",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) adjustments.Storage {
+					mock := adjustments.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&pending, nil)
+					return mock
+				},
+				balanceStorage: func(ctrl *gomock.Controller) balance.Storage {
+					mock := balance.NewMockStorage(ctrl)
+					mock.EXPECT().ApplyAdjustment(gomock.Any(), pending, "3").Return(balance.ErrInsufficientFunds)
+					return mock
+				},
+			},
+			approverID: "3",
+			wantErr:    ErrInsufficientFunds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := &service{
+				log:            zap.NewNop(),
+				storage:        tt.fields.storage(ctrl),
+				balanceStorage: tt.fields.balanceStorage(ctrl),
+				events:         publisher(ctrl, tt.fields.events),
+			}
+			_, err := s.Approve(ctx, "1", tt.approverID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Approve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// publisher по умолчанию — мок без ожиданий: событие там, где его не ждут, валит тест
+func publisher(ctrl *gomock.Controller, build func(ctrl *gomock.Controller) events.Publisher) events.Publisher {
+	if build == nil {
+		return events.NewMockPublisher(ctrl)
+	}
+	return build(ctrl)
+}
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=audit
+
+type Service interface {
+	Record(ctx context.Context, event models.AuditEvent)
+	Find(ctx context.Context, filter models.AuditFilter) (*[]models.AuditEvent, error)
+	Verify(ctx context.Context) (*models.AuditVerification, error)
+}
@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package audit is a generated GoMock package.
+package audit
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockService) Find(ctx context.Context, filter models.AuditFilter) (*[]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, filter)
+	ret0, _ := ret[0].(*[]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockServiceMockRecorder) Find(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockService)(nil).Find), ctx, filter)
+}
+
+// Record mocks base method.
+func (m *MockService) Record(ctx context.Context, event models.AuditEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, event)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockServiceMockRecorder) Record(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockService)(nil).Record), ctx, event)
+}
+
+// Verify mocks base method.
+func (m *MockService) Verify(ctx context.Context) (*models.AuditVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx)
+	ret0, _ := ret[0].(*models.AuditVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockServiceMockRecorder) Verify(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockService)(nil).Verify), ctx)
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+)
+
+const verifyBatchSize = 1000
+
+type service struct {
+	log     *zap.Logger
+	storage audit.Storage
+}
+
+func New(log *zap.Logger, storage audit.Storage) Service {
+	return &service{log: log, storage: storage}
+}
+
+// Record пишет событие, не связанное с изменением данных. Ошибка записи не должна ронять сам запрос,
+// поэтому она только логируется.
+func (s *service) Record(ctx context.Context, event models.AuditEvent) {
+	ctx, span := tracing.Start(ctx, "audit.Service.Record")
+	defer span.End()
+
+	err := s.storage.Add(ctx, event)
+	if err != nil {
+		logger.FromContext(ctx).Error("Cannot write audit event", zap.String("type", event.Type), zap.Error(err))
+	}
+}
+
+func (s *service) Find(ctx context.Context, filter models.AuditFilter) (*[]models.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "audit.Service.Find")
+	defer span.End()
+
+	return s.storage.Find(ctx, filter)
+}
+
+// Verify проходит всю цепочку и проверяет, что ни одно событие не было изменено или удалено
+func (s *service) Verify(ctx context.Context) (*models.AuditVerification, error) {
+	ctx, span := tracing.Start(ctx, "audit.Service.Verify")
+	defer span.End()
+
+	result := &models.AuditVerification{Valid: true}
+	prevHash := models.AuditGenesisHash
+	afterID := ""
+
+	for {
+		batch, err := s.storage.GetBatch(ctx, afterID, verifyBatchSize)
+		if errors.Is(err, audit.ErrNotFound) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range *batch {
+			result.Checked++
+			if event.PrevHash != prevHash || event.ComputeHash() != event.Hash {
+				result.Valid = false
+				result.BrokenAt = event.ID
+				return result, nil
+			}
+			prevHash = event.Hash
+			afterID = event.ID
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func chain(events ...models.AuditEvent) []models.AuditEvent {
+	prevHash := models.AuditGenesisHash
+	for i := range events {
+		events[i].PrevHash = prevHash
+		events[i].Hash = events[i].ComputeHash()
+		prevHash = events[i].Hash
+	}
+	return events
+}
+
+func Test_service_Verify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	valid := chain(
+		models.AuditEvent{ID: "1", Type: models.AuditLoginSucceeded, UserID: "1", CreatedAt: now},
+		models.AuditEvent{ID: "2", Type: models.AuditWithdrawal, UserID: "1", Payload: map[string]string{"order": "1", "sum": "10"}, CreatedAt: now},
+	)
+	tampered := chain(
+		models.AuditEvent{ID: "1", Type: models.AuditLoginSucceeded, UserID: "1", CreatedAt: now},
+		models.AuditEvent{ID: "2", Type: models.AuditWithdrawal, UserID: "1", Payload: map[string]string{"order": "1", "sum": "10"}, CreatedAt: now},
+	)
+	tampered[1].Payload["sum"] = "1000"
+
+	type fields struct {
+		storage func(ctrl *gomock.Controller) audit.Storage
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   *models.AuditVerification
+	}{
+		{
+			name: "valid chain",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) audit.Storage {
+					mock := audit.NewMockStorage(ctrl)
+					mock.EXPECT().GetBatch(gomock.Any(), "", verifyBatchSize).Return(&valid, nil)
+					mock.EXPECT().GetBatch(gomock.Any(), "2", verifyBatchSize).Return(nil, audit.ErrNotFound)
+					return mock
+				},
+			},
+			want: &models.AuditVerification{Checked: 2, Valid: true},
+		},
+		{
+			name: "tampered payload",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) audit.Storage {
+					mock := audit.NewMockStorage(ctrl)
+					mock.EXPECT().GetBatch(gomock.Any(), "", verifyBatchSize).Return(&tampered, nil)
+					return mock
+				},
+			},
+			want: &models.AuditVerification{Checked: 2, Valid: false, BrokenAt: "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := &service{
+				log:     zap.NewNop(),
+				storage: tt.fields.storage(ctrl),
+			}
+			got, err := s.Verify(ctx)
+			if err != nil {
+				t.Errorf("Verify() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Verify() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,15 +2,37 @@ package balance
 
 import (
 	"context"
+	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
 )
 
 //go:generate mockgen -source=contract.go -destination=contract_mock.go -package=balance
 
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInvalidSum        = errors.New("sum must be positive")
+	ErrRecipientNotFound = errors.New("recipient not found")
+	ErrSelfTransfer      = errors.New("transfer to yourself")
+	ErrTransferLimit     = errors.New("daily transfer limit exceeded")
+	ErrInvalidOrder      = errors.New("invalid order number")
+	ErrHoldNotFound      = errors.New("hold not found")
+	ErrHoldNotActive     = errors.New("hold is not active")
+	ErrInvalidReason     = errors.New("reason is required")
+)
+
 type Service interface {
 	GetBalance(ctx context.Context, userID string) (float64, error)
 	GetSumWithdraw(ctx context.Context, userID string) (float64, error)
 	AddWithdraw(ctx context.Context, withdraw models.Withdrawal, userID string) error
 	CanWithdraw(ctx context.Context, sum float64, userID string) (bool, error)
 	GetAllWithdrawByUser(ctx context.Context, userID string) (*[]models.Withdrawal, error)
+	ExportWithdrawByUser(ctx context.Context, userID string, fn func(models.Withdrawal) error) error
+	GetNextExpiry(ctx context.Context, userID string) (*models.Expiry, error)
+	Transfer(ctx context.Context, fromUserID string, toLogin string, sum float64) (*models.Transfer, error)
+	GetTransfers(ctx context.Context, userID string) (*[]models.Transfer, error)
+	Hold(ctx context.Context, userID string, number string, sum float64) (*models.Hold, error)
+	CaptureHold(ctx context.Context, userID string, holdID string) (*models.Hold, error)
+	VoidHold(ctx context.Context, userID string, holdID string) (*models.Hold, error)
+	GetHeld(ctx context.Context, userID string) (float64, error)
+	ReverseWithdrawal(ctx context.Context, reversal models.Reversal) (*models.Reversal, error)
 }
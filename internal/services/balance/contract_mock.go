@@ -64,6 +64,35 @@ func (mr *MockServiceMockRecorder) CanWithdraw(ctx, sum, userID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanWithdraw", reflect.TypeOf((*MockService)(nil).CanWithdraw), ctx, sum, userID)
 }
 
+// CaptureHold mocks base method.
+func (m *MockService) CaptureHold(ctx context.Context, userID, holdID string) (*models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(*models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockServiceMockRecorder) CaptureHold(ctx, userID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockService)(nil).CaptureHold), ctx, userID, holdID)
+}
+
+// ExportWithdrawByUser mocks base method.
+func (m *MockService) ExportWithdrawByUser(ctx context.Context, userID string, fn func(models.Withdrawal) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportWithdrawByUser", ctx, userID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportWithdrawByUser indicates an expected call of ExportWithdrawByUser.
+func (mr *MockServiceMockRecorder) ExportWithdrawByUser(ctx, userID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportWithdrawByUser", reflect.TypeOf((*MockService)(nil).ExportWithdrawByUser), ctx, userID, fn)
+}
+
 // GetAllWithdrawByUser mocks base method.
 func (m *MockService) GetAllWithdrawByUser(ctx context.Context, userID string) (*[]models.Withdrawal, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +123,36 @@ func (mr *MockServiceMockRecorder) GetBalance(ctx, userID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockService)(nil).GetBalance), ctx, userID)
 }
 
+// GetHeld mocks base method.
+func (m *MockService) GetHeld(ctx context.Context, userID string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeld", ctx, userID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeld indicates an expected call of GetHeld.
+func (mr *MockServiceMockRecorder) GetHeld(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeld", reflect.TypeOf((*MockService)(nil).GetHeld), ctx, userID)
+}
+
+// GetNextExpiry mocks base method.
+func (m *MockService) GetNextExpiry(ctx context.Context, userID string) (*models.Expiry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextExpiry", ctx, userID)
+	ret0, _ := ret[0].(*models.Expiry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextExpiry indicates an expected call of GetNextExpiry.
+func (mr *MockServiceMockRecorder) GetNextExpiry(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextExpiry", reflect.TypeOf((*MockService)(nil).GetNextExpiry), ctx, userID)
+}
+
 // GetSumWithdraw mocks base method.
 func (m *MockService) GetSumWithdraw(ctx context.Context, userID string) (float64, error) {
 	m.ctrl.T.Helper()
@@ -108,3 +167,78 @@ func (mr *MockServiceMockRecorder) GetSumWithdraw(ctx, userID interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSumWithdraw", reflect.TypeOf((*MockService)(nil).GetSumWithdraw), ctx, userID)
 }
+
+// GetTransfers mocks base method.
+func (m *MockService) GetTransfers(ctx context.Context, userID string) (*[]models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfers", ctx, userID)
+	ret0, _ := ret[0].(*[]models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfers indicates an expected call of GetTransfers.
+func (mr *MockServiceMockRecorder) GetTransfers(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfers", reflect.TypeOf((*MockService)(nil).GetTransfers), ctx, userID)
+}
+
+// Hold mocks base method.
+func (m *MockService) Hold(ctx context.Context, userID, number string, sum float64) (*models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hold", ctx, userID, number, sum)
+	ret0, _ := ret[0].(*models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hold indicates an expected call of Hold.
+func (mr *MockServiceMockRecorder) Hold(ctx, userID, number, sum interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hold", reflect.TypeOf((*MockService)(nil).Hold), ctx, userID, number, sum)
+}
+
+// ReverseWithdrawal mocks base method.
+func (m *MockService) ReverseWithdrawal(ctx context.Context, reversal models.Reversal) (*models.Reversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseWithdrawal", ctx, reversal)
+	ret0, _ := ret[0].(*models.Reversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseWithdrawal indicates an expected call of ReverseWithdrawal.
+func (mr *MockServiceMockRecorder) ReverseWithdrawal(ctx, reversal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseWithdrawal", reflect.TypeOf((*MockService)(nil).ReverseWithdrawal), ctx, reversal)
+}
+
+// Transfer mocks base method.
+func (m *MockService) Transfer(ctx context.Context, fromUserID, toLogin string, sum float64) (*models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, fromUserID, toLogin, sum)
+	ret0, _ := ret[0].(*models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockServiceMockRecorder) Transfer(ctx, fromUserID, toLogin, sum interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockService)(nil).Transfer), ctx, fromUserID, toLogin, sum)
+}
+
+// VoidHold mocks base method.
+func (m *MockService) VoidHold(ctx context.Context, userID, holdID string) (*models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(*models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidHold indicates an expected call of VoidHold.
+func (mr *MockServiceMockRecorder) VoidHold(ctx, userID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHold", reflect.TypeOf((*MockService)(nil).VoidHold), ctx, userID, holdID)
+}
@@ -2,29 +2,50 @@ package balance
 
 import (
 	"context"
+	"errors"
+	"github.com/EClaesson/go-luhn"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/metrics"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
 	"go.uber.org/zap"
+	"strings"
+	"time"
 )
 
 type service struct {
-	log     *zap.Logger
-	storage balance.Storage
+	log           *zap.Logger
+	storage       balance.Storage
+	events        events.Publisher
+	transferLimit float64
+	holdTTL       time.Duration
 }
 
-func New(log *zap.Logger, storage balance.Storage) Service {
-	return &service{log: log, storage: storage}
+// New — transferLimit ограничивает сумму исходящих переводов пользователя за сутки, 0 — без ограничения;
+// holdTTL — через сколько неподтверждённая блокировка баллов отпускается сама
+func New(log *zap.Logger, storage balance.Storage, events events.Publisher, transferLimit float64, holdTTL time.Duration) Service {
+	return &service{log: log, storage: storage, events: events, transferLimit: transferLimit, holdTTL: holdTTL}
 }
 
 func (s *service) GetBalance(ctx context.Context, userID string) (float64, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.GetBalance")
+	defer span.End()
+
 	return s.storage.GetBalance(ctx, userID)
 }
 
 func (s *service) GetSumWithdraw(ctx context.Context, userID string) (float64, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.GetSumWithdraw")
+	defer span.End()
+
 	return s.storage.GetSumWithdrawal(ctx, userID)
 }
 
 func (s *service) CanWithdraw(ctx context.Context, sum float64, userID string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.CanWithdraw")
+	defer span.End()
+
 	getBalance, err := s.storage.GetBalance(ctx, userID)
 	if err != nil {
 		return false, err
@@ -44,20 +65,153 @@ func (s *service) CanWithdraw(ctx context.Context, sum float64, userID string) (
 }
 
 func (s *service) AddWithdraw(ctx context.Context, withdraw models.Withdrawal, userID string) error {
+	ctx, span := tracing.Start(ctx, "balance.Service.AddWithdraw")
+	defer span.End()
+
 	err := s.storage.AddWithdraw(ctx, withdraw, userID)
-	if err != nil {
-		return err
+	if errors.Is(err, balance.ErrInsufficientFunds) {
+		return ErrInsufficientFunds
 	}
-
-	newBal := -withdraw.Sum
-
-	err = s.storage.SetBalance(ctx, newBal, userID)
 	if err != nil {
 		return err
 	}
+	metrics.PointsWithdrawn.Add(withdraw.Sum)
+	events.PublishBalance(ctx, s.events, s.storage, userID)
 	return nil
 }
 
 func (s *service) GetAllWithdrawByUser(ctx context.Context, userID string) (*[]models.Withdrawal, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.GetAllWithdrawByUser")
+	defer span.End()
+
 	return s.storage.GetAllWithdrawByUser(ctx, userID)
 }
+
+func (s *service) ExportWithdrawByUser(ctx context.Context, userID string, fn func(models.Withdrawal) error) error {
+	ctx, span := tracing.Start(ctx, "balance.Service.ExportWithdrawByUser")
+	defer span.End()
+
+	return s.storage.StreamWithdrawByUser(ctx, userID, fn)
+}
+
+func (s *service) GetNextExpiry(ctx context.Context, userID string) (*models.Expiry, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.GetNextExpiry")
+	defer span.End()
+
+	return s.storage.GetNextExpiry(ctx, userID)
+}
+
+func (s *service) Transfer(ctx context.Context, fromUserID string, toLogin string, sum float64) (*models.Transfer, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.Transfer")
+	defer span.End()
+
+	if sum <= 0 {
+		return nil, ErrInvalidSum
+	}
+	transfer, err := s.storage.Transfer(ctx, fromUserID, toLogin, sum, s.transferLimit)
+	switch {
+	case errors.Is(err, balance.ErrInsufficientFunds):
+		return nil, ErrInsufficientFunds
+	case errors.Is(err, balance.ErrRecipientNotFound):
+		return nil, ErrRecipientNotFound
+	case errors.Is(err, balance.ErrSelfTransfer):
+		return nil, ErrSelfTransfer
+	case errors.Is(err, balance.ErrTransferLimit):
+		return nil, ErrTransferLimit
+	case err != nil:
+		return nil, err
+	}
+
+	metrics.PointsTransferred.Add(sum)
+	s.events.Publish(ctx, transfer.ToUserID, models.EventTransfer, models.TransferEvent{ID: transfer.ID, From: transfer.FromLogin, Sum: transfer.Sum})
+	events.PublishBalance(ctx, s.events, s.storage, transfer.ToUserID)
+	events.PublishBalance(ctx, s.events, s.storage, fromUserID)
+	return transfer, nil
+}
+
+func (s *service) GetTransfers(ctx context.Context, userID string) (*[]models.Transfer, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.GetTransfers")
+	defer span.End()
+
+	return s.storage.GetTransfers(ctx, userID)
+}
+
+func (s *service) Hold(ctx context.Context, userID string, number string, sum float64) (*models.Hold, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.Hold")
+	defer span.End()
+
+	if ok, _ := luhn.IsValid(number); !ok {
+		return nil, ErrInvalidOrder
+	}
+	if sum <= 0 {
+		return nil, ErrInvalidSum
+	}
+	hold, err := s.storage.Hold(ctx, models.Hold{UserID: userID, Number: number, Sum: sum}, s.holdTTL)
+	if errors.Is(err, balance.ErrInsufficientFunds) {
+		return nil, ErrInsufficientFunds
+	}
+	return hold, err
+}
+
+func (s *service) CaptureHold(ctx context.Context, userID string, holdID string) (*models.Hold, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.CaptureHold")
+	defer span.End()
+
+	hold, err := s.storage.CaptureHold(ctx, userID, holdID)
+	if err != nil {
+		return nil, holdError(err)
+	}
+	metrics.PointsWithdrawn.Add(hold.Sum)
+	events.PublishBalance(ctx, s.events, s.storage, userID)
+	return hold, nil
+}
+
+func (s *service) VoidHold(ctx context.Context, userID string, holdID string) (*models.Hold, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.VoidHold")
+	defer span.End()
+
+	hold, err := s.storage.VoidHold(ctx, userID, holdID)
+	if err != nil {
+		return nil, holdError(err)
+	}
+	return hold, nil
+}
+
+func (s *service) GetHeld(ctx context.Context, userID string) (float64, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.GetHeld")
+	defer span.End()
+
+	return s.storage.GetHeld(ctx, userID)
+}
+
+// ReverseWithdrawal возвращает баллы по списанию; Sum == 0 — вернуть весь остаток
+func (s *service) ReverseWithdrawal(ctx context.Context, reversal models.Reversal) (*models.Reversal, error) {
+	ctx, span := tracing.Start(ctx, "balance.Service.ReverseWithdrawal")
+	defer span.End()
+
+	if reversal.Sum < 0 {
+		return nil, ErrInvalidSum
+	}
+	if strings.TrimSpace(reversal.Reason) == "" {
+		return nil, ErrInvalidReason
+	}
+	created, err := s.storage.ReverseWithdrawal(ctx, reversal)
+	if err != nil {
+		return nil, err
+	}
+	metrics.PointsReversed.Add(created.Sum)
+	events.PublishBalance(ctx, s.events, s.storage, created.UserID)
+	return created, nil
+}
+
+func holdError(err error) error {
+	switch {
+	case errors.Is(err, balance.ErrHoldNotFound):
+		return ErrHoldNotFound
+	case errors.Is(err, balance.ErrHoldNotActive):
+		return ErrHoldNotActive
+	case errors.Is(err, balance.ErrInsufficientFunds):
+		return ErrInsufficientFunds
+	}
+	return err
+}
@@ -0,0 +1,216 @@
+package balance
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/events"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_service_Transfer(t *testing.T) {
+	transfer := &models.Transfer{ID: "7", FromUserID: "1", FromLogin: "alice", ToUserID: "2", ToLogin: "bob", Sum: 50}
+
+	tests := []struct {
+		name    string
+		sum     float64
+		storage func(ctrl *gomock.Controller) balance.Storage
+		events  func(ctrl *gomock.Controller) events.Publisher
+		want    *models.Transfer
+		wantErr error
+	}{
+		{
+			name: "success notifies recipient",
+			sum:  50,
+			storage: func(ctrl *gomock.Controller) balance.Storage {
+				mock := balance.NewMockStorage(ctrl)
+				mock.EXPECT().Transfer(gomock.Any(), "1", "bob", 50.0, 1000.0).Return(transfer, nil)
+				mock.EXPECT().GetBalance(gomock.Any(), "2").Return(150.0, nil)
+				mock.EXPECT().GetSumWithdrawal(gomock.Any(), "2").Return(0.0, nil)
+				mock.EXPECT().GetBalance(gomock.Any(), "1").Return(10.0, nil)
+				mock.EXPECT().GetSumWithdrawal(gomock.Any(), "1").Return(5.0, nil)
+				return mock
+			},
+			events: func(ctrl *gomock.Controller) events.Publisher {
+				mock := events.NewMockPublisher(ctrl)
+				mock.EXPECT().Publish(gomock.Any(), "2", models.EventTransfer, models.TransferEvent{ID: "7", From: "alice", Sum: 50})
+				mock.EXPECT().Publish(gomock.Any(), "2", models.EventBalance, models.BalanceEvent{Current: 150})
+				mock.EXPECT().Publish(gomock.Any(), "1", models.EventBalance, models.BalanceEvent{Current: 10, Withdrawn: 5})
+				return mock
+			},
+			want: transfer,
+		},
+		{
+			name: "non positive sum",
+			sum:  0,
+			storage: func(ctrl *gomock.Controller) balance.Storage {
+				return balance.NewMockStorage(ctrl)
+			},
+			wantErr: ErrInvalidSum,
+		},
+		{
+			name: "insufficient funds",
+			sum:  50,
+			storage: func(ctrl *gomock.Controller) balance.Storage {
+				mock := balance.NewMockStorage(ctrl)
+				mock.EXPECT().Transfer(gomock.Any(), "1", "bob", 50.0, 1000.0).Return(nil, balance.ErrInsufficientFunds)
+				return mock
+			},
+			wantErr: ErrInsufficientFunds,
+		},
+		{
+			name: "daily limit",
+			sum:  50,
+			storage: func(ctrl *gomock.Controller) balance.Storage {
+				mock := balance.NewMockStorage(ctrl)
+				mock.EXPECT().Transfer(gomock.Any(), "1", "bob", 50.0, 1000.0).Return(nil, balance.ErrTransferLimit)
+				return mock
+			},
+			wantErr: ErrTransferLimit,
+		},
+		{
+			name: "unknown recipient",
+			sum:  50,
+			storage: func(ctrl *gomock.Controller) balance.Storage {
+				mock := balance.NewMockStorage(ctrl)
+				mock.EXPECT().Transfer(gomock.Any(), "1", "bob", 50.0, 1000.0).Return(nil, balance.ErrRecipientNotFound)
+				return mock
+			},
+			wantErr: ErrRecipientNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			publisher := events.Publisher(events.NewMockPublisher(ctrl))
+			if tt.events != nil {
+				publisher = tt.events(ctrl)
+			}
+			s := &service{storage: tt.storage(ctrl), events: publisher, transferLimit: 1000}
+			got, err := s.Transfer(context.Background(), "1", "bob", tt.sum)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_service_Hold(t *testing.T) {
+	hold := &models.Hold{ID: "1", UserID: "1", Number: "12345678903", Sum: 100, Status: models.HoldHeld}
+
+	tests := []struct {
+		name    string
+		number  string
+		sum     float64
+		storage func(ctrl *gomock.Controller) balance.Storage
+		want    *models.Hold
+		wantErr error
+	}{
+		{
+			name:   "success",
+			number: "12345678903",
+			sum:    100,
+			storage: func(ctrl *gomock.Controller) balance.Storage {
+				mock := balance.NewMockStorage(ctrl)
+				mock.EXPECT().Hold(gomock.Any(), models.Hold{UserID: "1", Number: "12345678903", Sum: 100}, 30*time.Minute).Return(hold, nil)
+				return mock
+			},
+			want: hold,
+		},
+		{
+			name:   "invalid order number",
+			number: "12345678900",
+			sum:    100,
+			storage: func(ctrl *gomock.Controller) balance.Storage {
+				return balance.NewMockStorage(ctrl)
+			},
+			wantErr: ErrInvalidOrder,
+		},
+		{
+			name:   "not enough available points",
+			number: "12345678903",
+			sum:    100,
+			storage: func(ctrl *gomock.Controller) balance.Storage {
+				mock := balance.NewMockStorage(ctrl)
+				mock.EXPECT().Hold(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, balance.ErrInsufficientFunds)
+				return mock
+			},
+			wantErr: ErrInsufficientFunds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := &service{storage: tt.storage(ctrl), holdTTL: 30 * time.Minute}
+			got, err := s.Hold(context.Background(), "1", tt.number, tt.sum)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_service_CaptureHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := balance.NewMockStorage(ctrl)
+	storage.EXPECT().CaptureHold(gomock.Any(), "1", "5").Return(nil, balance.ErrHoldNotActive)
+	storage.EXPECT().CaptureHold(gomock.Any(), "1", "6").Return(nil, balance.ErrHoldNotFound)
+	storage.EXPECT().CaptureHold(gomock.Any(), "1", "7").Return(&models.Hold{ID: "7", Sum: 40, Status: models.HoldCaptured}, nil)
+	storage.EXPECT().GetBalance(gomock.Any(), "1").Return(60.0, nil)
+	storage.EXPECT().GetSumWithdrawal(gomock.Any(), "1").Return(40.0, nil)
+
+	publisher := events.NewMockPublisher(ctrl)
+	publisher.EXPECT().Publish(gomock.Any(), "1", models.EventBalance, models.BalanceEvent{Current: 60, Withdrawn: 40})
+
+	s := &service{storage: storage, events: publisher}
+
+	_, err := s.CaptureHold(context.Background(), "1", "5")
+	assert.True(t, errors.Is(err, ErrHoldNotActive))
+	_, err = s.CaptureHold(context.Background(), "1", "6")
+	assert.True(t, errors.Is(err, ErrHoldNotFound))
+	got, err := s.CaptureHold(context.Background(), "1", "7")
+	assert.NoError(t, err)
+	assert.Equal(t, models.HoldCaptured, got.Status)
+}
+
+func Test_service_ReverseWithdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := balance.NewMockStorage(ctrl)
+	storage.EXPECT().ReverseWithdrawal(gomock.Any(), models.Reversal{WithdrawalID: "3", Sum: 500, Reason: "order cancelled", CreatedBy: "9"}).
+		Return(nil, balance.ErrReversalExceeded)
+	storage.EXPECT().ReverseWithdrawal(gomock.Any(), models.Reversal{WithdrawalID: "3", Reason: "order cancelled", CreatedBy: "9"}).
+		Return(&models.Reversal{ID: "1", WithdrawalID: "3", UserID: "1", Sum: 40, Reason: "order cancelled", CreatedBy: "9"}, nil)
+	storage.EXPECT().GetBalance(gomock.Any(), "1").Return(100.0, nil)
+	storage.EXPECT().GetSumWithdrawal(gomock.Any(), "1").Return(0.0, nil)
+
+	publisher := events.NewMockPublisher(ctrl)
+	publisher.EXPECT().Publish(gomock.Any(), "1", models.EventBalance, models.BalanceEvent{Current: 100, Withdrawn: 0})
+
+	s := &service{storage: storage, events: publisher}
+
+	_, err := s.ReverseWithdrawal(context.Background(), models.Reversal{WithdrawalID: "3", Sum: -1, Reason: "order cancelled", CreatedBy: "9"})
+	assert.True(t, errors.Is(err, ErrInvalidSum))
+	_, err = s.ReverseWithdrawal(context.Background(), models.Reversal{WithdrawalID: "3", Reason: " ", CreatedBy: "9"})
+	assert.True(t, errors.Is(err, ErrInvalidReason))
+	_, err = s.ReverseWithdrawal(context.Background(), models.Reversal{WithdrawalID: "3", Sum: 500, Reason: "order cancelled", CreatedBy: "9"})
+	assert.True(t, errors.Is(err, balance.ErrReversalExceeded))
+	got, err := s.ReverseWithdrawal(context.Background(), models.Reversal{WithdrawalID: "3", Reason: "order cancelled", CreatedBy: "9"})
+	assert.NoError(t, err)
+	assert.Equal(t, 40.0, got.Sum)
+}
@@ -0,0 +1,26 @@
+package campaigns
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=campaigns
+
+var (
+	ErrInvalidName   = errors.New("invalid campaign name")
+	ErrInvalidKind   = errors.New("invalid campaign kind")
+	ErrInvalidValue  = errors.New("invalid campaign value")
+	ErrInvalidPeriod = errors.New("invalid campaign period")
+)
+
+type Service interface {
+	Create(ctx context.Context, campaign models.Campaign) (*models.Campaign, error)
+	Get(ctx context.Context, campaignID string) (*models.Campaign, error)
+	GetAll(ctx context.Context) (*[]models.Campaign, error)
+	Update(ctx context.Context, campaign models.Campaign) (*models.Campaign, error)
+	Delete(ctx context.Context, campaignID string) error
+	// Evaluate считает бонусы кампаний за начисленный заказ; окно кампании сверяется со временем загрузки заказа
+	Evaluate(ctx context.Context, order models.Order, accrual float64) ([]models.CampaignBonus, error)
+}
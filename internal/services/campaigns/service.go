@@ -0,0 +1,116 @@
+package campaigns
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/campaigns"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+	"math"
+	"strings"
+)
+
+type service struct {
+	log          *zap.Logger
+	storage      campaigns.Storage
+	orderStorage orders.Storage
+}
+
+func New(log *zap.Logger, storage campaigns.Storage, orderStorage orders.Storage) Service {
+	return &service{log: log, storage: storage, orderStorage: orderStorage}
+}
+
+func (s *service) Create(ctx context.Context, campaign models.Campaign) (*models.Campaign, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Service.Create")
+	defer span.End()
+
+	err := validate(&campaign)
+	if err != nil {
+		return nil, err
+	}
+	return s.storage.Add(ctx, campaign)
+}
+
+func (s *service) Get(ctx context.Context, campaignID string) (*models.Campaign, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Service.Get")
+	defer span.End()
+
+	return s.storage.Get(ctx, campaignID)
+}
+
+func (s *service) GetAll(ctx context.Context) (*[]models.Campaign, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Service.GetAll")
+	defer span.End()
+
+	return s.storage.GetAll(ctx)
+}
+
+func (s *service) Update(ctx context.Context, campaign models.Campaign) (*models.Campaign, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Service.Update")
+	defer span.End()
+
+	err := validate(&campaign)
+	if err != nil {
+		return nil, err
+	}
+	return s.storage.Update(ctx, campaign)
+}
+
+func (s *service) Delete(ctx context.Context, campaignID string) error {
+	ctx, span := tracing.Start(ctx, "campaigns.Service.Delete")
+	defer span.End()
+
+	return s.storage.Delete(ctx, campaignID)
+}
+
+func (s *service) Evaluate(ctx context.Context, order models.Order, accrual float64) ([]models.CampaignBonus, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Service.Evaluate")
+	defer span.End()
+
+	active, err := s.storage.GetActive(ctx, order.UploadedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// в базу за числом заказов идём, только если есть кампания на первый заказ
+	first := false
+	for _, campaign := range *active {
+		if campaign.Kind != models.CampaignFirstOrder {
+			continue
+		}
+		processed, err := s.orderStorage.CountProcessed(ctx, order.UserID)
+		if err != nil {
+			return nil, err
+		}
+		first = processed == 0
+		break
+	}
+
+	var bonuses []models.CampaignBonus
+	for _, campaign := range *active {
+		amount := math.Round(campaign.Bonus(accrual, first)*100) / 100
+		if amount <= 0 {
+			continue
+		}
+		bonuses = append(bonuses, models.CampaignBonus{CampaignID: campaign.ID, Kind: campaign.Kind, Amount: amount})
+	}
+	return bonuses, nil
+}
+
+func validate(campaign *models.Campaign) error {
+	campaign.Name = strings.TrimSpace(campaign.Name)
+	if campaign.Name == "" {
+		return ErrInvalidName
+	}
+	if !models.IsValidCampaignKind(campaign.Kind) {
+		return ErrInvalidKind
+	}
+	if campaign.Value <= 0 || (campaign.Kind == models.CampaignMultiplier && campaign.Value <= 1) {
+		return ErrInvalidValue
+	}
+	if campaign.StartsAt.IsZero() || !campaign.EndsAt.After(campaign.StartsAt) {
+		return ErrInvalidPeriod
+	}
+	return nil
+}
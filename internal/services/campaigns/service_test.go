@@ -0,0 +1,133 @@
+package campaigns
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/campaigns"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func Test_service_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	uploadedAt := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+	order := models.Order{Number: "12345678903", UserID: "1", UploadedAt: uploadedAt}
+
+	double := models.Campaign{ID: "1", Kind: models.CampaignMultiplier, Value: 2}
+	fixed := models.Campaign{ID: "2", Kind: models.CampaignFixed, Value: 50}
+	first := models.Campaign{ID: "3", Kind: models.CampaignFirstOrder, Value: 100}
+
+	tests := []struct {
+		name      string
+		active    []models.Campaign
+		processed int
+		want      []models.CampaignBonus
+	}{
+		{
+			name: "no campaigns",
+		},
+		{
+			name:   "double points and fixed bonus",
+			active: []models.Campaign{double, fixed},
+			want: []models.CampaignBonus{
+				{CampaignID: "1", Kind: models.CampaignMultiplier, Amount: 120.5},
+				{CampaignID: "2", Kind: models.CampaignFixed, Amount: 50},
+			},
+		},
+		{
+			name:      "first order",
+			active:    []models.Campaign{first},
+			processed: 0,
+			want:      []models.CampaignBonus{{CampaignID: "3", Kind: models.CampaignFirstOrder, Amount: 100}},
+		},
+		{
+			name:      "not the first order",
+			active:    []models.Campaign{first},
+			processed: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			active := tt.active
+			if active == nil {
+				active = []models.Campaign{}
+			}
+			storage := campaigns.NewMockStorage(ctrl)
+			storage.EXPECT().GetActive(gomock.Any(), uploadedAt).Return(&active, nil)
+			orderStorage := orders.NewMockStorage(ctrl)
+			for _, campaign := range active {
+				if campaign.Kind == models.CampaignFirstOrder {
+					orderStorage.EXPECT().CountProcessed(gomock.Any(), "1").Return(tt.processed, nil)
+				}
+			}
+
+			s := &service{log: zap.NewNop(), storage: storage, orderStorage: orderStorage}
+			got, err := s.Evaluate(ctx, order, 120.5)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_service_Create(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	valid := models.Campaign{Name: "Weekend x2", Kind: models.CampaignMultiplier, Value: 2, StartsAt: start, EndsAt: start.Add(48 * time.Hour)}
+
+	tests := []struct {
+		name     string
+		campaign func(c models.Campaign) models.Campaign
+		wantErr  error
+	}{
+		{
+			name:     "valid",
+			campaign: func(c models.Campaign) models.Campaign { return c },
+		},
+		{
+			name:     "blank name",
+			campaign: func(c models.Campaign) models.Campaign { c.Name = "  "; return c },
+			wantErr:  ErrInvalidName,
+		},
+		{
+			name:     "unknown kind",
+			campaign: func(c models.Campaign) models.Campaign { c.Kind = "CASHBACK"; return c },
+			wantErr:  ErrInvalidKind,
+		},
+		{
+			name:     "multiplier must exceed one",
+			campaign: func(c models.Campaign) models.Campaign { c.Value = 1; return c },
+			wantErr:  ErrInvalidValue,
+		},
+		{
+			name:     "ends before start",
+			campaign: func(c models.Campaign) models.Campaign { c.EndsAt = c.StartsAt; return c },
+			wantErr:  ErrInvalidPeriod,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			campaign := tt.campaign(valid)
+			storage := campaigns.NewMockStorage(ctrl)
+			if tt.wantErr == nil {
+				storage.EXPECT().Add(gomock.Any(), campaign).Return(&campaign, nil)
+			}
+
+			s := &service{log: zap.NewNop(), storage: storage}
+			_, err := s.Create(ctx, campaign)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
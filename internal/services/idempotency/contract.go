@@ -0,0 +1,22 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=idempotency
+
+var (
+	ErrKeyReused  = errors.New("idempotency key reused with different payload")
+	ErrInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+type Service interface {
+	// Begin возвращает nil, если запрос нужно выполнить, или сохранённый ответ, если его нужно повторить
+	Begin(ctx context.Context, userID string, key string, fingerprint string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record models.IdempotencyRecord) error
+	Release(ctx context.Context, userID string, key string) error
+	DeleteExpired(ctx context.Context) error
+}
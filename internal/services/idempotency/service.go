@@ -0,0 +1,66 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/idempotency"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+	"time"
+)
+
+type service struct {
+	log     *zap.Logger
+	storage idempotency.Storage
+	ttl     time.Duration
+}
+
+func New(log *zap.Logger, storage idempotency.Storage, ttl time.Duration) Service {
+	return &service{log: log, storage: storage, ttl: ttl}
+}
+
+func (s *service) Begin(ctx context.Context, userID string, key string, fingerprint string) (*models.IdempotencyRecord, error) {
+	ctx, span := tracing.Start(ctx, "idempotency.Service.Begin")
+	defer span.End()
+
+	reserved, err := s.storage.Reserve(ctx, models.IdempotencyRecord{UserID: userID, Key: key, Fingerprint: fingerprint}, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	record, err := s.storage.Get(ctx, userID, key)
+	if err != nil {
+		return nil, err
+	}
+	if record.Fingerprint != fingerprint {
+		return nil, ErrKeyReused
+	}
+	if !record.IsCompleted() {
+		return nil, ErrInProgress
+	}
+	return record, nil
+}
+
+func (s *service) Complete(ctx context.Context, record models.IdempotencyRecord) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Service.Complete")
+	defer span.End()
+
+	return s.storage.Complete(ctx, record)
+}
+
+func (s *service) Release(ctx context.Context, userID string, key string) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Service.Release")
+	defer span.End()
+
+	return s.storage.Delete(ctx, userID, key)
+}
+
+func (s *service) DeleteExpired(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Service.DeleteExpired")
+	defer span.End()
+
+	return s.storage.DeleteExpired(ctx, s.ttl)
+}
@@ -0,0 +1,101 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/idempotency"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_service_Begin(t *testing.T) {
+	ctx := context.Background()
+	completed := models.IdempotencyRecord{UserID: "1", Key: "k", Fingerprint: "f", StatusCode: 200, Body: []byte("ok")}
+	inFlight := models.IdempotencyRecord{UserID: "1", Key: "k", Fingerprint: "f"}
+
+	type fields struct {
+		storage func(ctrl *gomock.Controller) idempotency.Storage
+	}
+	tests := []struct {
+		name        string
+		fields      fields
+		fingerprint string
+		want        *models.IdempotencyRecord
+		wantErr     error
+	}{
+		{
+			name: "new key",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) idempotency.Storage {
+					mock := idempotency.NewMockStorage(ctrl)
+					mock.EXPECT().Reserve(gomock.Any(), gomock.Any(), time.Hour).Return(true, nil)
+					return mock
+				},
+			},
+			fingerprint: "f",
+			want:        nil,
+		},
+		{
+			name: "replay",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) idempotency.Storage {
+					mock := idempotency.NewMockStorage(ctrl)
+					mock.EXPECT().Reserve(gomock.Any(), gomock.Any(), time.Hour).Return(false, nil)
+					mock.EXPECT().Get(gomock.Any(), "1", "k").Return(&completed, nil)
+					return mock
+				},
+			},
+			fingerprint: "f",
+			want:        &completed,
+		},
+		{
+			name: "different payload",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) idempotency.Storage {
+					mock := idempotency.NewMockStorage(ctrl)
+					mock.EXPECT().Reserve(gomock.Any(), gomock.Any(), time.Hour).Return(false, nil)
+					mock.EXPECT().Get(gomock.Any(), "1", "k").Return(&completed, nil)
+					return mock
+				},
+			},
+			fingerprint: "other",
+			wantErr:     ErrKeyReused,
+		},
+		{
+			name: "in progress",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) idempotency.Storage {
+					mock := idempotency.NewMockStorage(ctrl)
+					mock.EXPECT().Reserve(gomock.Any(), gomock.Any(), time.Hour).Return(false, nil)
+					mock.EXPECT().Get(gomock.Any(), "1", "k").Return(&inFlight, nil)
+					return mock
+				},
+			},
+			fingerprint: "f",
+			wantErr:     ErrInProgress,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := &service{
+				log:     zap.NewNop(),
+				storage: tt.fields.storage(ctrl),
+				ttl:     time.Hour,
+			}
+			got, err := s.Begin(ctx, "1", "k", tt.fingerprint)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Begin() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Begin() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,10 +12,13 @@ var (
 	ErrDuplicate        = errors.New("duplicate order")
 	ErrOrderAnotherUser = errors.New("order created by another user")
 	ErrLuhn             = errors.New("luhn error")
+	ErrAlreadyProcessed = errors.New("order already processed")
 )
 
 type Service interface {
 	Add(ctx context.Context, orderID string, userID string) error
 	Get(ctx context.Context, orderID string) (*models.Order, error)
 	GetAllByUser(ctx context.Context, userID string) (*[]models.Order, error)
+	ExportByUser(ctx context.Context, userID string, fn func(models.Order) error) error
+	Repoll(ctx context.Context, orderID string) error
 }
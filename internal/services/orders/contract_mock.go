@@ -49,6 +49,20 @@ func (mr *MockServiceMockRecorder) Add(ctx, orderID, userID interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockService)(nil).Add), ctx, orderID, userID)
 }
 
+// ExportByUser mocks base method.
+func (m *MockService) ExportByUser(ctx context.Context, userID string, fn func(models.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportByUser", ctx, userID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportByUser indicates an expected call of ExportByUser.
+func (mr *MockServiceMockRecorder) ExportByUser(ctx, userID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportByUser", reflect.TypeOf((*MockService)(nil).ExportByUser), ctx, userID, fn)
+}
+
 // Get mocks base method.
 func (m *MockService) Get(ctx context.Context, orderID string) (*models.Order, error) {
 	m.ctrl.T.Helper()
@@ -78,3 +92,17 @@ func (mr *MockServiceMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockService)(nil).GetAllByUser), ctx, userID)
 }
+
+// Repoll mocks base method.
+func (m *MockService) Repoll(ctx context.Context, orderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Repoll", ctx, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Repoll indicates an expected call of Repoll.
+func (mr *MockServiceMockRecorder) Repoll(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Repoll", reflect.TypeOf((*MockService)(nil).Repoll), ctx, orderID)
+}
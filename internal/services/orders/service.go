@@ -6,6 +6,7 @@ import (
 	"github.com/EClaesson/go-luhn"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
 	"go.uber.org/zap"
 )
 
@@ -19,6 +20,9 @@ func New(log *zap.Logger, storage orders.Storage) Service {
 }
 
 func (s *service) Add(ctx context.Context, orderID string, userID string) error {
+	ctx, span := tracing.Start(ctx, "orders.Service.Add")
+	defer span.End()
+
 	ok := s.isValid(orderID)
 	if !ok {
 		return ErrLuhn
@@ -40,9 +44,37 @@ func (s *service) isValid(orderID string) bool {
 }
 
 func (s *service) GetAllByUser(ctx context.Context, userID string) (*[]models.Order, error) {
+	ctx, span := tracing.Start(ctx, "orders.Service.GetAllByUser")
+	defer span.End()
+
 	return s.storage.GetAllByUser(ctx, userID)
 }
 
+func (s *service) ExportByUser(ctx context.Context, userID string, fn func(models.Order) error) error {
+	ctx, span := tracing.Start(ctx, "orders.Service.ExportByUser")
+	defer span.End()
+
+	return s.storage.StreamByUser(ctx, userID, fn)
+}
+
 func (s *service) Get(ctx context.Context, orderID string) (*models.Order, error) {
+	ctx, span := tracing.Start(ctx, "orders.Service.Get")
+	defer span.End()
+
 	return s.storage.Get(ctx, orderID)
 }
+
+func (s *service) Repoll(ctx context.Context, orderID string) error {
+	ctx, span := tracing.Start(ctx, "orders.Service.Repoll")
+	defer span.End()
+
+	_, err := s.storage.Get(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	err = s.storage.Repoll(ctx, orderID)
+	if errors.Is(err, orders.ErrConflict) {
+		return ErrAlreadyProcessed
+	}
+	return err
+}
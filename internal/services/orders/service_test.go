@@ -2,6 +2,7 @@ package orders
 
 import (
 	"context"
+	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
 	"github.com/golang/mock/gomock"
@@ -304,3 +305,67 @@ func Test_service_GetAllByUser(t *testing.T) {
 		})
 	}
 }
+
+func Test_service_Repoll(t *testing.T) {
+	ctx := context.Background()
+	order := models.Order{Number: "1", UserID: "1", Status: "INVALID"}
+
+	type fields struct {
+		log     *zap.Logger
+		storage func(ctrl *gomock.Controller) orders.Storage
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr error
+	}{
+		{
+			name: "successCase",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) orders.Storage {
+					mock := orders.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&order, nil)
+					mock.EXPECT().Repoll(gomock.Any(), "1").Return(nil)
+					return mock
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "not found",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) orders.Storage {
+					mock := orders.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(nil, orders.ErrNotFound)
+					return mock
+				},
+			},
+			wantErr: orders.ErrNotFound,
+		},
+		{
+			name: "already processed",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) orders.Storage {
+					mock := orders.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&order, nil)
+					mock.EXPECT().Repoll(gomock.Any(), "1").Return(orders.ErrConflict)
+					return mock
+				},
+			},
+			wantErr: ErrAlreadyProcessed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := &service{
+				log:     tt.fields.log,
+				storage: tt.fields.storage(ctrl),
+			}
+			if err := s.Repoll(ctx, "1"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Repoll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package privacy
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=privacy
+
+type Service interface {
+	Export(ctx context.Context, userID string) (*models.DataExport, error)
+}
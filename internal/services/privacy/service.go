@@ -0,0 +1,65 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+	"time"
+)
+
+type service struct {
+	log            *zap.Logger
+	usersStorage   users.Storage
+	ordersStorage  orders.Storage
+	balanceStorage balance.Storage
+}
+
+func New(log *zap.Logger, usersStorage users.Storage, ordersStorage orders.Storage, balanceStorage balance.Storage) Service {
+	return &service{log: log, usersStorage: usersStorage, ordersStorage: ordersStorage, balanceStorage: balanceStorage}
+}
+
+// Export собирает данные пользователя целиком. Хеш пароля и секрет TOTP не отдаём:
+// это не персональные данные, а средства входа.
+func (s *service) Export(ctx context.Context, userID string) (*models.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "privacy.Service.Export")
+	defer span.End()
+
+	user, err := s.usersStorage.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	export := &models.DataExport{GeneratedAt: time.Now(), Profile: *user}
+	export.Profile.Password = ""
+	export.Profile.TOTPSecret = ""
+
+	userOrders, err := s.ordersStorage.GetAllByUser(ctx, userID)
+	if err != nil && !errors.Is(err, orders.ErrNotFound) {
+		return nil, err
+	}
+	if userOrders != nil {
+		export.Orders = *userOrders
+	}
+
+	withdrawals, err := s.balanceStorage.GetAllWithdrawByUser(ctx, userID)
+	if err != nil && !errors.Is(err, balance.ErrNotFound) {
+		return nil, err
+	}
+	if withdrawals != nil {
+		export.Withdrawals = *withdrawals
+	}
+
+	statement, err := s.balanceStorage.GetStatement(ctx, userID, models.StatementFilter{})
+	if err != nil && !errors.Is(err, balance.ErrNotFound) {
+		return nil, err
+	}
+	if statement != nil {
+		export.Statement = *statement
+	}
+
+	return export, nil
+}
@@ -0,0 +1,76 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/orders"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_service_Export(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{UserID: "1", Login: "login", Password: "hash", TOTPSecret: "SECRET", TOTPEnabled: true, ReferralCode: "ABCD2345"}
+	userOrders := &[]models.Order{{Number: "12345678903", Status: "PROCESSED", Accrual: 100}}
+	entries := &[]models.StatementEntry{{ID: "L1", Type: models.StatementAccrual, Amount: 100, Balance: 100}}
+
+	tests := []struct {
+		name    string
+		setup   func(u *users.MockStorage, o *orders.MockStorage, b *balance.MockStorage)
+		want    *models.DataExport
+		wantErr error
+	}{
+		{
+			name: "without withdrawals",
+			setup: func(u *users.MockStorage, o *orders.MockStorage, b *balance.MockStorage) {
+				u.EXPECT().Get(gomock.Any(), "1").Return(user, nil)
+				o.EXPECT().GetAllByUser(gomock.Any(), "1").Return(userOrders, nil)
+				b.EXPECT().GetAllWithdrawByUser(gomock.Any(), "1").Return(nil, balance.ErrNotFound)
+				b.EXPECT().GetStatement(gomock.Any(), "1", models.StatementFilter{}).Return(entries, nil)
+			},
+			want: &models.DataExport{
+				Profile:   models.User{UserID: "1", Login: "login", TOTPEnabled: true, ReferralCode: "ABCD2345"},
+				Orders:    *userOrders,
+				Statement: *entries,
+			},
+		},
+		{
+			name: "user not found",
+			setup: func(u *users.MockStorage, o *orders.MockStorage, b *balance.MockStorage) {
+				u.EXPECT().Get(gomock.Any(), "1").Return(nil, users.ErrNotFound)
+			},
+			wantErr: users.ErrNotFound,
+		},
+		{
+			name: "storage error",
+			setup: func(u *users.MockStorage, o *orders.MockStorage, b *balance.MockStorage) {
+				u.EXPECT().Get(gomock.Any(), "1").Return(user, nil)
+				o.EXPECT().GetAllByUser(gomock.Any(), "1").Return(nil, errors.New("db down"))
+			},
+			wantErr: errors.New("db down"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			u, o, b := users.NewMockStorage(ctrl), orders.NewMockStorage(ctrl), balance.NewMockStorage(ctrl)
+			tt.setup(u, o, b)
+
+			s := &service{usersStorage: u, ordersStorage: o, balanceStorage: b}
+			got, err := s.Export(ctx, "1")
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, got.GeneratedAt.IsZero())
+			got.GeneratedAt = tt.want.GeneratedAt
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=statement
+
+var ErrInvalidType = errors.New("invalid statement entry type")
+
+type Service interface {
+	Get(ctx context.Context, userID string, filter models.StatementFilter) (*[]models.StatementEntry, error)
+	GetMonthly(ctx context.Context, userID string, month time.Time) (*models.MonthlyStatement, error)
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package statement is a generated GoMock package.
+package statement
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockService) Get(ctx context.Context, userID string, filter models.StatementFilter) (*[]models.StatementEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID, filter)
+	ret0, _ := ret[0].(*[]models.StatementEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockServiceMockRecorder) Get(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), ctx, userID, filter)
+}
+
+// GetMonthly mocks base method.
+func (m *MockService) GetMonthly(ctx context.Context, userID string, month time.Time) (*models.MonthlyStatement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMonthly", ctx, userID, month)
+	ret0, _ := ret[0].(*models.MonthlyStatement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMonthly indicates an expected call of GetMonthly.
+func (mr *MockServiceMockRecorder) GetMonthly(ctx, userID, month interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMonthly", reflect.TypeOf((*MockService)(nil).GetMonthly), ctx, userID, month)
+}
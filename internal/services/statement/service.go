@@ -0,0 +1,70 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+	"math"
+	"time"
+)
+
+type service struct {
+	log     *zap.Logger
+	storage balance.Storage
+}
+
+func New(log *zap.Logger, storage balance.Storage) Service {
+	return &service{log: log, storage: storage}
+}
+
+func (s *service) Get(ctx context.Context, userID string, filter models.StatementFilter) (*[]models.StatementEntry, error) {
+	ctx, span := tracing.Start(ctx, "statement.Service.Get")
+	defer span.End()
+
+	for _, entryType := range filter.Types {
+		if !models.IsStatementType(entryType) {
+			return nil, ErrInvalidType
+		}
+	}
+	return s.storage.GetStatement(ctx, userID, filter)
+}
+
+// GetMonthly собирает выписку за месяц, в который попадает month. Месяц без движений — не ошибка:
+// выписка с одинаковыми остатками на начало и конец.
+func (s *service) GetMonthly(ctx context.Context, userID string, month time.Time) (*models.MonthlyStatement, error) {
+	ctx, span := tracing.Start(ctx, "statement.Service.GetMonthly")
+	defer span.End()
+
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	opening, err := s.storage.GetBalanceAt(ctx, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	statement := &models.MonthlyStatement{Month: from, Opening: opening, Closing: opening}
+
+	entries, err := s.storage.GetStatement(ctx, userID, models.StatementFilter{From: from, To: to})
+	if errors.Is(err, balance.ErrNotFound) {
+		return statement, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	statement.Entries = *entries
+	for _, entry := range *entries {
+		if entry.Amount >= 0 {
+			statement.Credited += entry.Amount
+		} else {
+			statement.Debited -= entry.Amount
+		}
+	}
+	statement.Credited = math.Round(statement.Credited*100) / 100
+	statement.Debited = math.Round(statement.Debited*100) / 100
+	statement.Closing = statement.Entries[len(statement.Entries)-1].Balance
+	return statement, nil
+}
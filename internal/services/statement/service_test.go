@@ -0,0 +1,77 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/balance"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_service_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	filter := models.StatementFilter{Types: []string{models.StatementAccrual, models.StatementWithdrawal}, Limit: 10}
+	entries := &[]models.StatementEntry{{ID: "L1", Type: models.StatementAccrual, Amount: 100, Balance: 100}}
+
+	storage := balance.NewMockStorage(ctrl)
+	storage.EXPECT().GetStatement(gomock.Any(), "1", filter).Return(entries, nil)
+
+	s := &service{storage: storage}
+
+	_, err := s.Get(context.Background(), "1", models.StatementFilter{Types: []string{"BONUS"}})
+	assert.True(t, errors.Is(err, ErrInvalidType))
+	got, err := s.Get(context.Background(), "1", filter)
+	assert.NoError(t, err)
+	assert.Equal(t, entries, got)
+}
+
+func Test_service_GetMonthly(t *testing.T) {
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		entries *[]models.StatementEntry
+		err     error
+		want    *models.MonthlyStatement
+	}{
+		{
+			name: "Entries",
+			entries: &[]models.StatementEntry{
+				{ID: "L2", Type: models.StatementAccrual, Amount: 100.1, Balance: 150.1},
+				{ID: "W1", Type: models.StatementWithdrawal, Amount: -30, Balance: 120.1},
+				{ID: "E2", Type: models.StatementExpiry, Amount: -0.1, Balance: 120},
+			},
+			want: &models.MonthlyStatement{Month: from, Opening: 50, Closing: 120, Credited: 100.1, Debited: 30.1},
+		},
+		{
+			name: "No entries",
+			err:  balance.ErrNotFound,
+			want: &models.MonthlyStatement{Month: from, Opening: 50, Closing: 50},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := balance.NewMockStorage(ctrl)
+			storage.EXPECT().GetBalanceAt(gomock.Any(), "1", from).Return(50.0, nil)
+			storage.EXPECT().GetStatement(gomock.Any(), "1", models.StatementFilter{From: from, To: to}).Return(tt.entries, tt.err)
+
+			s := &service{storage: storage}
+
+			got, err := s.GetMonthly(context.Background(), "1", time.Date(2024, time.March, 17, 12, 0, 0, 0, time.UTC))
+			assert.NoError(t, err)
+			if tt.entries != nil {
+				tt.want.Entries = *tt.entries
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package tiers
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=tiers
+
+type Service interface {
+	// GetStatus — уровень с последнего пересчёта и прогресс по текущему окну
+	GetStatus(ctx context.Context, userID string) (*models.TierStatus, error)
+	GetHistory(ctx context.Context, userID string) (*[]models.TierChange, error)
+}
@@ -0,0 +1,64 @@
+package tiers
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/tiers"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+)
+
+type service struct {
+	log        *zap.Logger
+	storage    tiers.Storage
+	windowDays int
+}
+
+func New(log *zap.Logger, storage tiers.Storage, windowDays int) Service {
+	return &service{log: log, storage: storage, windowDays: windowDays}
+}
+
+func (s *service) GetStatus(ctx context.Context, userID string) (*models.TierStatus, error) {
+	ctx, span := tracing.Start(ctx, "tiers.Service.GetStatus")
+	defer span.End()
+
+	all, err := s.storage.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.storage.GetUserTier(ctx, userID)
+	if err != nil && !errors.Is(err, tiers.ErrNotFound) {
+		return nil, err
+	}
+	spend, err := s.storage.GetSpend(ctx, userID, s.windowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	// до первого пересчёта пользователь на нижнем уровне
+	status := &models.TierStatus{Tier: (*all)[0], Spend: spend}
+	for i, tier := range *all {
+		if tier.Name != current {
+			continue
+		}
+		status.Tier = tier
+		if i+1 < len(*all) {
+			next := (*all)[i+1]
+			status.Next = &next
+		}
+		return status, nil
+	}
+	if len(*all) > 1 {
+		next := (*all)[1]
+		status.Next = &next
+	}
+	return status, nil
+}
+
+func (s *service) GetHistory(ctx context.Context, userID string) (*[]models.TierChange, error) {
+	ctx, span := tracing.Start(ctx, "tiers.Service.GetHistory")
+	defer span.End()
+
+	return s.storage.GetHistory(ctx, userID)
+}
@@ -0,0 +1,81 @@
+package tiers
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/tiers"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func Test_service_GetStatus(t *testing.T) {
+	ctx := context.Background()
+	all := []models.Tier{
+		{Name: "BASE", Threshold: 0, Multiplier: 1},
+		{Name: "SILVER", Threshold: 1000, Multiplier: 1.05},
+		{Name: "GOLD", Threshold: 5000, Multiplier: 1.1},
+	}
+
+	tests := []struct {
+		name     string
+		current  string
+		tierErr  error
+		wantTier string
+		wantNext string
+		wantErr  error
+	}{
+		{
+			name:     "not recalculated yet",
+			tierErr:  tiers.ErrNotFound,
+			wantTier: "BASE",
+			wantNext: "SILVER",
+		},
+		{
+			name:     "middle tier",
+			current:  "SILVER",
+			wantTier: "SILVER",
+			wantNext: "GOLD",
+		},
+		{
+			name:     "top tier has no next",
+			current:  "GOLD",
+			wantTier: "GOLD",
+		},
+		{
+			name:    "storage error",
+			tierErr: errors.New("timeout"),
+			wantErr: errors.New("timeout"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := tiers.NewMockStorage(ctrl)
+			storage.EXPECT().GetAll(gomock.Any()).Return(&all, nil)
+			storage.EXPECT().GetUserTier(gomock.Any(), "1").Return(tt.current, tt.tierErr)
+			if tt.wantErr == nil {
+				storage.EXPECT().GetSpend(gomock.Any(), "1", 365).Return(1200.0, nil)
+			}
+
+			s := &service{log: zap.NewNop(), storage: storage, windowDays: 365}
+			got, err := s.GetStatus(ctx, "1")
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTier, got.Tier.Name)
+			assert.Equal(t, 1200.0, got.Spend)
+			if tt.wantNext == "" {
+				assert.Nil(t, got.Next)
+			} else {
+				assert.Equal(t, tt.wantNext, got.Next.Name)
+			}
+		})
+	}
+}
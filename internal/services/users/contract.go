@@ -4,13 +4,36 @@ import (
 	"context"
 	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
 )
 
 //go:generate mockgen -source=contract.go -destination=contract_mock.go -package=users
 
-var ErrIncorrectData = errors.New("data incorrect")
+var (
+	ErrIncorrectData        = errors.New("data incorrect")
+	ErrSecondFactorRequired = errors.New("second factor required")
+	ErrInvalidCode          = errors.New("invalid two-factor code")
+	ErrSecondFactorLocked   = errors.New("too many invalid two-factor codes")
+	ErrTOTPAlreadyEnabled   = errors.New("two-factor authentication already enabled")
+	ErrTOTPNotEnrolled      = errors.New("two-factor authentication is not enrolled")
+	ErrTOTPNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrSessionRevoked       = errors.New("session revoked")
+)
 
 type Service interface {
 	Register(ctx context.Context, userIn models.User) (*models.User, error)
-	Login(ctx context.Context, userIn models.User) (*models.User, error)
+	Login(ctx context.Context, userIn models.User, code string) (*models.User, error)
+	Get(ctx context.Context, userID string) (*models.User, error)
+	List(ctx context.Context, query string, limit int, offset int) (*[]models.User, error)
+	SetRole(ctx context.Context, userID string, role string) error
+	EnrollTOTP(ctx context.Context, userID string) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID string, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID string, code string) error
+	VerifyTOTP(ctx context.Context, userID string, code string) error
+	RequireSecondFactor(ctx context.Context, userID string, sum float64, threshold float64, code string) error
+	GetReferrals(ctx context.Context, userID string) (*models.ReferralProgram, error)
+	RequestDeletion(ctx context.Context, userID string) (*models.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, userID string) error
+	CheckSession(ctx context.Context, userID string, issuedAt time.Time) error
 }
@@ -7,6 +7,7 @@ package users
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,25 +36,142 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CancelDeletion mocks base method.
+func (m *MockService) CancelDeletion(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockServiceMockRecorder) CancelDeletion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockService)(nil).CancelDeletion), ctx, userID)
+}
+
+// CheckSession mocks base method.
+func (m *MockService) CheckSession(ctx context.Context, userID string, issuedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSession", ctx, userID, issuedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckSession indicates an expected call of CheckSession.
+func (mr *MockServiceMockRecorder) CheckSession(ctx, userID, issuedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSession", reflect.TypeOf((*MockService)(nil).CheckSession), ctx, userID, issuedAt)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockService) ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockServiceMockRecorder) ConfirmTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockService)(nil).ConfirmTOTP), ctx, userID, code)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockService) DisableTOTP(ctx context.Context, userID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockServiceMockRecorder) DisableTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockService)(nil).DisableTOTP), ctx, userID, code)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockService) EnrollTOTP(ctx context.Context, userID string) (*models.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, userID)
+	ret0, _ := ret[0].(*models.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockServiceMockRecorder) EnrollTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockService)(nil).EnrollTOTP), ctx, userID)
+}
+
+// Get mocks base method.
+func (m *MockService) Get(ctx context.Context, userID string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockServiceMockRecorder) Get(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), ctx, userID)
+}
+
+// GetReferrals mocks base method.
+func (m *MockService) GetReferrals(ctx context.Context, userID string) (*models.ReferralProgram, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferrals", ctx, userID)
+	ret0, _ := ret[0].(*models.ReferralProgram)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferrals indicates an expected call of GetReferrals.
+func (mr *MockServiceMockRecorder) GetReferrals(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferrals", reflect.TypeOf((*MockService)(nil).GetReferrals), ctx, userID)
+}
+
+// List mocks base method.
+func (m *MockService) List(ctx context.Context, query string, limit, offset int) (*[]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query, limit, offset)
+	ret0, _ := ret[0].(*[]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockServiceMockRecorder) List(ctx, query, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx, query, limit, offset)
+}
+
 // Login mocks base method.
-func (m *MockService) Login(ctx context.Context, userIn models.User) (*models.User, error) {
+func (m *MockService) Login(ctx context.Context, userIn models.User, code string) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, userIn)
+	ret := m.ctrl.Call(m, "Login", ctx, userIn, code)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockServiceMockRecorder) Login(ctx, userIn interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Login(ctx, userIn, code interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockService)(nil).Login), ctx, userIn)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockService)(nil).Login), ctx, userIn, code)
 }
 
 // Register mocks base method.
 func (m *MockService) Register(ctx context.Context, userIn models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Handle", ctx, userIn)
+	ret := m.ctrl.Call(m, "Register", ctx, userIn)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
@@ -62,5 +180,62 @@ func (m *MockService) Register(ctx context.Context, userIn models.User) (*models
 // Register indicates an expected call of Register.
 func (mr *MockServiceMockRecorder) Register(ctx, userIn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockService)(nil).Register), ctx, userIn)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockService)(nil).Register), ctx, userIn)
+}
+
+// RequestDeletion mocks base method.
+func (m *MockService) RequestDeletion(ctx context.Context, userID string) (*models.AccountDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestDeletion", ctx, userID)
+	ret0, _ := ret[0].(*models.AccountDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestDeletion indicates an expected call of RequestDeletion.
+func (mr *MockServiceMockRecorder) RequestDeletion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestDeletion", reflect.TypeOf((*MockService)(nil).RequestDeletion), ctx, userID)
+}
+
+// RequireSecondFactor mocks base method.
+func (m *MockService) RequireSecondFactor(ctx context.Context, userID string, sum, threshold float64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequireSecondFactor", ctx, userID, sum, threshold, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequireSecondFactor indicates an expected call of RequireSecondFactor.
+func (mr *MockServiceMockRecorder) RequireSecondFactor(ctx, userID, sum, threshold, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequireSecondFactor", reflect.TypeOf((*MockService)(nil).RequireSecondFactor), ctx, userID, sum, threshold, code)
+}
+
+// SetRole mocks base method.
+func (m *MockService) SetRole(ctx context.Context, userID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *MockServiceMockRecorder) SetRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*MockService)(nil).SetRole), ctx, userID, role)
+}
+
+// VerifyTOTP mocks base method.
+func (m *MockService) VerifyTOTP(ctx context.Context, userID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTOTP", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyTOTP indicates an expected call of VerifyTOTP.
+func (mr *MockServiceMockRecorder) VerifyTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTOTP", reflect.TypeOf((*MockService)(nil).VerifyTOTP), ctx, userID, code)
 }
@@ -2,29 +2,70 @@ package users
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/totp"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"time"
+)
+
+const (
+	totpIssuer         = "Gophermart"
+	recoveryCodesCount = 10
+	recoveryCodeSize   = 5
+	referralCodeSize   = 5
+	// после maxSecondFactorFailures неверных кодов подряд второй фактор не проверяется secondFactorLockout
+	maxSecondFactorFailures = 5
+	secondFactorLockout     = 15 * time.Minute
 )
 
 type service struct {
-	log     *zap.Logger
-	storage users.Storage
+	log           *zap.Logger
+	storage       users.Storage
+	audit         audit.Storage
+	deletionGrace time.Duration
+	// auditLoginKey — ключ HMAC для логинов в журнале аудита: сам логин туда не пишем, журнал не обезличивается
+	auditLoginKey []byte
 }
 
-func New(log *zap.Logger, storage users.Storage) Service {
-	return &service{log: log, storage: storage}
+func New(log *zap.Logger, storage users.Storage, audit audit.Storage, deletionGrace time.Duration, auditLoginKey string) Service {
+	key := []byte(auditLoginKey)
+	if len(key) == 0 {
+		// без заданного ключа хеши сопоставимы только в пределах процесса
+		key = make([]byte, sha256.Size)
+		_, _ = rand.Read(key)
+	}
+	return &service{log: log, storage: storage, audit: audit, deletionGrace: deletionGrace, auditLoginKey: key}
 }
 
 func (s *service) Register(ctx context.Context, userIn models.User) (*models.User, error) {
-	hashPassword, err := s.getHashPassword(userIn.Password)
+	ctx, span := tracing.Start(ctx, "users.Service.Register")
+	defer span.End()
+
+	hashPassword, err := s.getHashPassword(ctx, userIn.Password)
+	if err != nil {
+		return nil, err
+	}
+	referralCode, err := newReferralCode()
 	if err != nil {
 		return nil, err
 	}
 	user := models.User{
-		Login:    userIn.Login,
-		Password: hashPassword,
+		Login:        userIn.Login,
+		Password:     hashPassword,
+		ReferralCode: referralCode,
+		InviteCode:   strings.ToUpper(strings.TrimSpace(userIn.InviteCode)),
 	}
 	registeredUser, err := s.storage.Register(ctx, user)
 	if err != nil {
@@ -34,19 +75,315 @@ func (s *service) Register(ctx context.Context, userIn models.User) (*models.Use
 	return registeredUser, nil
 }
 
-func (s *service) Login(ctx context.Context, userIn models.User) (*models.User, error) {
+// Login проверяет пароль и, если у пользователя включена двухфакторная аутентификация, код: из приложения
+// или одноразовый код восстановления. Без кода такой вход возвращает ErrSecondFactorRequired.
+func (s *service) Login(ctx context.Context, userIn models.User, code string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "users.Service.Login")
+	defer span.End()
+
 	user, err := s.storage.Login(ctx, userIn.Login)
 	if err != nil {
+		s.record(ctx, models.AuditEvent{Type: models.AuditLoginFailed, Payload: map[string]string{"login_hash": s.hashLogin(userIn.Login), "reason": "unknown login"}})
 		return nil, err
 	}
 
-	if !s.checkPassword(user.Password, userIn.Password) {
+	if !s.checkPassword(ctx, user.Password, userIn.Password) {
+		// аккаунт известен по UserID, логин в журнал не пишем — после обезличивания он остался бы в журнале
+		s.record(ctx, models.AuditEvent{Type: models.AuditLoginFailed, UserID: user.UserID, Payload: map[string]string{"reason": "wrong password"}})
 		return nil, ErrIncorrectData
 	}
+	if user.TOTPEnabled {
+		if code == "" {
+			s.record(ctx, models.AuditEvent{Type: models.AuditSecondFactorRequired, UserID: user.UserID})
+			return nil, ErrSecondFactorRequired
+		}
+		err = s.verifySecondFactor(ctx, user, code)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.record(ctx, models.AuditEvent{Type: models.AuditLoginSucceeded, ActorID: user.UserID, UserID: user.UserID})
 	return user, nil
 }
 
-func (s *service) getHashPassword(password string) (string, error) {
+func (s *service) Get(ctx context.Context, userID string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "users.Service.Get")
+	defer span.End()
+
+	return s.storage.Get(ctx, userID)
+}
+
+func (s *service) List(ctx context.Context, query string, limit int, offset int) (*[]models.User, error) {
+	ctx, span := tracing.Start(ctx, "users.Service.List")
+	defer span.End()
+
+	return s.storage.List(ctx, query, limit, offset)
+}
+
+func (s *service) SetRole(ctx context.Context, userID string, role string) error {
+	ctx, span := tracing.Start(ctx, "users.Service.SetRole")
+	defer span.End()
+
+	if !models.IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	return s.storage.SetRole(ctx, userID, role)
+}
+
+func (s *service) EnrollTOTP(ctx context.Context, userID string) (*models.TOTPEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "users.Service.EnrollTOTP")
+	defer span.End()
+
+	user, err := s.storage.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = s.storage.SetTOTPSecret(ctx, userID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(totpIssuer, user.Login, secret),
+	}, nil
+}
+
+func (s *service) ConfirmTOTP(ctx context.Context, userID string, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "users.Service.ConfirmTOTP")
+	defer span.End()
+
+	user, err := s.storage.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+
+	err = s.checkTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := s.generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, s.hashRecoveryCode(code))
+	}
+
+	err = s.storage.EnableTOTP(ctx, userID, hashes)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *service) DisableTOTP(ctx context.Context, userID string, code string) error {
+	ctx, span := tracing.Start(ctx, "users.Service.DisableTOTP")
+	defer span.End()
+
+	err := s.VerifyTOTP(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	return s.storage.DisableTOTP(ctx, userID)
+}
+
+// VerifyTOTP принимает как код из приложения, так и одноразовый код восстановления
+func (s *service) VerifyTOTP(ctx context.Context, userID string, code string) error {
+	ctx, span := tracing.Start(ctx, "users.Service.VerifyTOTP")
+	defer span.End()
+
+	user, err := s.storage.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	return s.verifySecondFactor(ctx, user, code)
+}
+
+// RequireSecondFactor проверяет второй фактор для операции на sum баллов. Код нужен, только если порог
+// threshold задан, сумма его превышает и у пользователя включена двухфакторная аутентификация.
+func (s *service) RequireSecondFactor(ctx context.Context, userID string, sum float64, threshold float64, code string) error {
+	ctx, span := tracing.Start(ctx, "users.Service.RequireSecondFactor")
+	defer span.End()
+
+	if threshold <= 0 || sum <= threshold {
+		return nil
+	}
+	user, err := s.storage.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return nil
+	}
+	if code == "" {
+		return ErrSecondFactorRequired
+	}
+	return s.verifySecondFactor(ctx, user, code)
+}
+
+// verifySecondFactor проверяет код с учётом блокировки: попытка засчитывается до проверки, а во время
+// блокировки код не проверяется вовсе, иначе перебор шестизначного кода был бы делом времени
+func (s *service) verifySecondFactor(ctx context.Context, user *models.User, code string) error {
+	allowed, err := s.storage.TakeSecondFactorAttempt(ctx, user.UserID, maxSecondFactorFailures, secondFactorLockout)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		s.record(ctx, models.AuditEvent{Type: models.AuditSecondFactorLocked, UserID: user.UserID})
+		return ErrSecondFactorLocked
+	}
+
+	method := "totp"
+	if len(code) == totp.Digits {
+		err = s.checkTOTP(ctx, user, code)
+	} else {
+		method = "recovery code"
+		err = s.checkRecoveryCode(ctx, user.UserID, code)
+	}
+	if errors.Is(err, ErrInvalidCode) {
+		s.record(ctx, models.AuditEvent{Type: models.AuditSecondFactorFailed, UserID: user.UserID, Payload: map[string]string{"method": method}})
+	}
+	if err != nil {
+		return err
+	}
+	err = s.storage.ResetSecondFactorFailures(ctx, user.UserID)
+	if err != nil {
+		return err
+	}
+	s.record(ctx, models.AuditEvent{Type: models.AuditSecondFactorPassed, ActorID: user.UserID, UserID: user.UserID, Payload: map[string]string{"method": method}})
+	return nil
+}
+
+func (s *service) checkRecoveryCode(ctx context.Context, userID string, code string) error {
+	ok, err := s.storage.UseRecoveryCode(ctx, userID, s.hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+func (s *service) checkTOTP(ctx context.Context, user *models.User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return ErrInvalidCode
+	}
+	fresh, err := s.storage.UseTOTPStep(ctx, user.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// RequestDeletion назначает обезличивание аккаунта по истечении deletionGrace.
+// Токены отзываются только в момент удаления, до этого пользователь может войти и отменить запрос
+func (s *service) RequestDeletion(ctx context.Context, userID string) (*models.AccountDeletion, error) {
+	ctx, span := tracing.Start(ctx, "users.Service.RequestDeletion")
+	defer span.End()
+
+	deletion, err := s.storage.RequestDeletion(ctx, userID, s.deletionGrace)
+	if err != nil {
+		return nil, err
+	}
+	s.record(ctx, models.AuditEvent{
+		Type:    models.AuditDeletionRequested,
+		ActorID: userID,
+		UserID:  userID,
+		Payload: map[string]string{"scheduled_at": deletion.ScheduledAt.UTC().Format(time.RFC3339)},
+	})
+	return deletion, nil
+}
+
+func (s *service) CancelDeletion(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "users.Service.CancelDeletion")
+	defer span.End()
+
+	err := s.storage.CancelDeletion(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.record(ctx, models.AuditEvent{Type: models.AuditDeletionCancelled, ActorID: userID, UserID: userID})
+	return nil
+}
+
+// CheckSession отклоняет токены, выпущенные до отзыва сессий. iat в JWT хранится с точностью до секунды
+func (s *service) CheckSession(ctx context.Context, userID string, issuedAt time.Time) error {
+	ctx, span := tracing.Start(ctx, "users.Service.CheckSession")
+	defer span.End()
+
+	revokedAt, err := s.storage.GetSessionsRevokedAt(ctx, userID)
+	if errors.Is(err, users.ErrNotFound) {
+		return ErrSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if !revokedAt.IsZero() && issuedAt.Before(revokedAt.Truncate(time.Second).Add(time.Second)) {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// record пишет событие безопасности. Сбой аудита не должен мешать входу, поэтому ошибка только логируется
+func (s *service) record(ctx context.Context, event models.AuditEvent) {
+	err := s.audit.Add(ctx, event)
+	if err != nil {
+		logger.FromContext(ctx).Error("Cannot write audit event", zap.String("type", event.Type), zap.Error(err))
+	}
+}
+
+func (s *service) generateRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *service) hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashLogin позволяет сопоставить попытки входа под одним несуществующим логином, не храня сам логин
+func (s *service) hashLogin(login string) string {
+	mac := hmac.New(sha256.New, s.auditLoginKey)
+	mac.Write([]byte(login))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *service) getHashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+
 	bytePassword := []byte(password)
 	hash, err := bcrypt.GenerateFromPassword(bytePassword, bcrypt.DefaultCost)
 	if err != nil {
@@ -55,7 +392,40 @@ func (s *service) getHashPassword(password string) (string, error) {
 	return string(hash), nil
 }
 
-func (s *service) checkPassword(hashPassword, password string) bool {
+func (s *service) checkPassword(ctx context.Context, hashPassword, password string) bool {
+	_, span := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
 	err := bcrypt.CompareHashAndPassword([]byte(hashPassword), []byte(password))
 	return err == nil
 }
+
+func (s *service) GetReferrals(ctx context.Context, userID string) (*models.ReferralProgram, error) {
+	ctx, span := tracing.Start(ctx, "users.Service.GetReferrals")
+	defer span.End()
+
+	user, err := s.storage.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	program := &models.ReferralProgram{Code: user.ReferralCode}
+
+	referrals, err := s.storage.GetReferrals(ctx, userID)
+	if errors.Is(err, users.ErrNotFound) {
+		return program, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	program.Referrals = *referrals
+	return program, nil
+}
+
+// newReferralCode — 8 символов base32 в верхнем регистре, такой код легко продиктовать
+func newReferralCode() (string, error) {
+	buf := make([]byte, referralCodeSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(buf), nil
+}
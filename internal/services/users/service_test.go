@@ -2,12 +2,20 @@ package users
 
 import (
 	"context"
+	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/users"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/totp"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func Test_service_Login(t *testing.T) {
@@ -24,6 +32,9 @@ func Test_service_Login(t *testing.T) {
 		Password: "$2a$10$mFTV7pqNmJC1VWdTtVi2geNGLLlK7Xo7NwjrZDqBrOF1WX.8kMgoC",
 	}
 
+	userTOTP := userOut
+	userTOTP.TOTPEnabled = true
+
 	type fields struct {
 		log     *zap.Logger
 		storage func(ctrl *gomock.Controller) users.Storage
@@ -31,6 +42,7 @@ func Test_service_Login(t *testing.T) {
 	type args struct {
 		ctx    context.Context
 		userIn models.User
+		code   string
 	}
 	tests := []struct {
 		name    string
@@ -56,16 +68,96 @@ func Test_service_Login(t *testing.T) {
 			want:    &userOut,
 			wantErr: false,
 		},
+		{
+			name: "second factor required",
+			fields: fields{
+				log: nil,
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Login(gomock.Any(), userIn.Login).Return(&userTOTP, nil)
+					return mock
+				},
+			},
+			args: args{
+				ctx:    ctx,
+				userIn: userIn,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "valid recovery code",
+			fields: fields{
+				log: nil,
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Login(gomock.Any(), userIn.Login).Return(&userTOTP, nil)
+					mock.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).Return(true, nil)
+					mock.EXPECT().UseRecoveryCode(gomock.Any(), "1", gomock.Any()).Return(true, nil)
+					mock.EXPECT().ResetSecondFactorFailures(gomock.Any(), "1").Return(nil)
+					return mock
+				},
+			},
+			args: args{
+				ctx:    ctx,
+				userIn: userIn,
+				code:   "recovery-code",
+			},
+			want:    &userTOTP,
+			wantErr: false,
+		},
+		{
+			name: "invalid recovery code",
+			fields: fields{
+				log: nil,
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Login(gomock.Any(), userIn.Login).Return(&userTOTP, nil)
+					mock.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).Return(true, nil)
+					mock.EXPECT().UseRecoveryCode(gomock.Any(), "1", gomock.Any()).Return(false, nil)
+					return mock
+				},
+			},
+			args: args{
+				ctx:    ctx,
+				userIn: userIn,
+				code:   "recovery-code",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "second factor locked",
+			fields: fields{
+				log: nil,
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Login(gomock.Any(), userIn.Login).Return(&userTOTP, nil)
+					mock.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).Return(false, nil)
+					return mock
+				},
+			},
+			args: args{
+				ctx:    ctx,
+				userIn: userIn,
+				code:   "123456",
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
+			auditMock := audit.NewMockStorage(ctrl)
+			auditMock.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 			s := &service{
 				log:     tt.fields.log,
 				storage: tt.fields.storage(ctrl),
+				audit:   auditMock,
 			}
-			got, err := s.Login(tt.args.ctx, tt.args.userIn)
+			got, err := s.Login(tt.args.ctx, tt.args.userIn, tt.args.code)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Login() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -77,6 +169,314 @@ func Test_service_Login(t *testing.T) {
 	}
 }
 
+// после обезличивания пользователь остаётся только id; журнал аудита не переписывается,
+// поэтому логина в нём не должно быть ни у известного аккаунта, ни у несуществующего
+func Test_service_LoginAuditHasNoLogin(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storage := users.NewMockStorage(ctrl)
+	storage.EXPECT().Login(gomock.Any(), "alice").Return(&models.User{UserID: "1", Login: "alice", Password: "$2a$10$mFTV7pqNmJC1VWdTtVi2geNGLLlK7Xo7NwjrZDqBrOF1WX.8kMgoC"}, nil)
+	storage.EXPECT().Login(gomock.Any(), "bob").Return(nil, users.ErrNotFound).Times(2)
+
+	var recorded []models.AuditEvent
+	auditMock := audit.NewMockStorage(ctrl)
+	auditMock.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event models.AuditEvent) error {
+		recorded = append(recorded, event)
+		return nil
+	}).Times(3)
+
+	s := New(zap.NewNop(), storage, auditMock, time.Hour, "key").(*service)
+	_, err := s.Login(ctx, models.User{Login: "alice", Password: "wrong"}, "")
+	require.Error(t, err)
+	_, err = s.Login(ctx, models.User{Login: "bob", Password: "wrong"}, "")
+	require.Error(t, err)
+	_, err = s.Login(ctx, models.User{Login: "bob", Password: "wrong"}, "")
+	require.Error(t, err)
+
+	require.Len(t, recorded, 3)
+	for _, event := range recorded {
+		assert.Equal(t, models.AuditLoginFailed, event.Type)
+		for key, value := range event.Payload {
+			assert.NotContains(t, value, "alice", key)
+			assert.NotContains(t, value, "bob", key)
+		}
+	}
+	assert.Equal(t, "1", recorded[0].UserID)
+	// попытки под одним несуществующим логином сопоставимы между собой
+	assert.NotEmpty(t, recorded[1].Payload["login_hash"])
+	assert.Equal(t, recorded[1].Payload["login_hash"], recorded[2].Payload["login_hash"])
+}
+
+func Test_service_VerifyTOTP(t *testing.T) {
+	ctx := context.Background()
+	secret, _ := totp.GenerateSecret()
+	now := time.Now()
+	code, _ := totp.Code(secret, totp.Step(now))
+
+	user := models.User{
+		UserID:      "1",
+		Login:       "login",
+		TOTPSecret:  secret,
+		TOTPEnabled: true,
+	}
+
+	type fields struct {
+		log     *zap.Logger
+		storage func(ctrl *gomock.Controller) users.Storage
+	}
+	type args struct {
+		ctx  context.Context
+		code string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "valid code",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&user, nil)
+					mock.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).Return(true, nil)
+					mock.EXPECT().UseTOTPStep(gomock.Any(), "1", gomock.Any()).Return(true, nil)
+					mock.EXPECT().ResetSecondFactorFailures(gomock.Any(), "1").Return(nil)
+					return mock
+				},
+			},
+			args:    args{ctx: ctx, code: code},
+			wantErr: nil,
+		},
+		{
+			name: "replayed code",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&user, nil)
+					mock.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).Return(true, nil)
+					mock.EXPECT().UseTOTPStep(gomock.Any(), "1", gomock.Any()).Return(false, nil)
+					return mock
+				},
+			},
+			args:    args{ctx: ctx, code: code},
+			wantErr: ErrInvalidCode,
+		},
+		{
+			name: "recovery code",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&user, nil)
+					mock.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).Return(true, nil)
+					mock.EXPECT().UseRecoveryCode(gomock.Any(), "1", gomock.Any()).Return(true, nil)
+					mock.EXPECT().ResetSecondFactorFailures(gomock.Any(), "1").Return(nil)
+					return mock
+				},
+			},
+			args:    args{ctx: ctx, code: "0a1b2c3d4e"},
+			wantErr: nil,
+		},
+		{
+			name: "locked user is not checked",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&user, nil)
+					mock.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).Return(false, nil)
+					return mock
+				},
+			},
+			args:    args{ctx: ctx, code: code},
+			wantErr: ErrSecondFactorLocked,
+		},
+		{
+			name: "not enabled",
+			fields: fields{
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Get(gomock.Any(), "1").Return(&models.User{UserID: "1"}, nil)
+					return mock
+				},
+			},
+			args:    args{ctx: ctx, code: code},
+			wantErr: ErrTOTPNotEnabled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			auditMock := audit.NewMockStorage(ctrl)
+			auditMock.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			s := &service{
+				log:     tt.fields.log,
+				storage: tt.fields.storage(ctrl),
+				audit:   auditMock,
+			}
+			err := s.VerifyTOTP(tt.args.ctx, "1", tt.args.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyTOTP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// параллельные попытки не должны проверить больше maxSecondFactorFailures кодов: хранилище здесь повторяет
+// семантику условного UPDATE — попытка засчитывается и сверяется с блокировкой атомарно
+func Test_service_VerifyTOTPConcurrentAttempts(t *testing.T) {
+	ctx := context.Background()
+	secret, _ := totp.GenerateSecret()
+	user := models.User{UserID: "1", TOTPSecret: secret, TOTPEnabled: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var mu sync.Mutex
+	var failures, checked int
+	locked := false
+	storage := users.NewMockStorage(ctrl)
+	storage.EXPECT().Get(gomock.Any(), "1").Return(&user, nil).AnyTimes()
+	storage.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).DoAndReturn(
+		func(context.Context, string, int, time.Duration) (bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if locked {
+				return false, nil
+			}
+			failures++
+			if failures >= maxSecondFactorFailures {
+				locked = true
+			}
+			return true, nil
+		}).AnyTimes()
+	storage.EXPECT().UseRecoveryCode(gomock.Any(), "1", gomock.Any()).DoAndReturn(
+		func(context.Context, string, string) (bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			checked++
+			return false, nil
+		}).AnyTimes()
+
+	auditMock := audit.NewMockStorage(ctrl)
+	auditMock.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	s := &service{storage: storage, audit: auditMock}
+
+	const attempts = 50
+	var wg sync.WaitGroup
+	var lockedErrors int32
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errors.Is(s.VerifyTOTP(ctx, "1", "wrong-code"), ErrSecondFactorLocked) {
+				atomic.AddInt32(&lockedErrors, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, maxSecondFactorFailures, checked)
+	assert.Equal(t, int32(attempts-maxSecondFactorFailures), lockedErrors)
+}
+
+func Test_service_RequireSecondFactor(t *testing.T) {
+	ctx := context.Background()
+	secret, _ := totp.GenerateSecret()
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+
+	user := models.User{UserID: "1", TOTPSecret: secret, TOTPEnabled: true}
+
+	type args struct {
+		sum       float64
+		threshold float64
+		code      string
+	}
+	tests := []struct {
+		name    string
+		storage func(ctrl *gomock.Controller) users.Storage
+		args    args
+		wantErr error
+	}{
+		{
+			name: "below threshold",
+			storage: func(ctrl *gomock.Controller) users.Storage {
+				return users.NewMockStorage(ctrl)
+			},
+			args: args{sum: 100, threshold: 100},
+		},
+		{
+			name: "threshold disabled",
+			storage: func(ctrl *gomock.Controller) users.Storage {
+				return users.NewMockStorage(ctrl)
+			},
+			args: args{sum: 1000, threshold: 0},
+		},
+		{
+			name: "two-factor not enabled",
+			storage: func(ctrl *gomock.Controller) users.Storage {
+				mock := users.NewMockStorage(ctrl)
+				mock.EXPECT().Get(gomock.Any(), "1").Return(&models.User{UserID: "1"}, nil)
+				return mock
+			},
+			args: args{sum: 1000, threshold: 100},
+		},
+		{
+			name: "code missing",
+			storage: func(ctrl *gomock.Controller) users.Storage {
+				mock := users.NewMockStorage(ctrl)
+				mock.EXPECT().Get(gomock.Any(), "1").Return(&user, nil)
+				return mock
+			},
+			args:    args{sum: 1000, threshold: 100},
+			wantErr: ErrSecondFactorRequired,
+		},
+		{
+			name: "valid code",
+			storage: func(ctrl *gomock.Controller) users.Storage {
+				mock := users.NewMockStorage(ctrl)
+				mock.EXPECT().Get(gomock.Any(), "1").Return(&user, nil)
+				mock.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).Return(true, nil)
+				mock.EXPECT().UseTOTPStep(gomock.Any(), "1", gomock.Any()).Return(true, nil)
+				mock.EXPECT().ResetSecondFactorFailures(gomock.Any(), "1").Return(nil)
+				return mock
+			},
+			args: args{sum: 1000, threshold: 100, code: code},
+		},
+		{
+			name: "invalid code",
+			storage: func(ctrl *gomock.Controller) users.Storage {
+				mock := users.NewMockStorage(ctrl)
+				mock.EXPECT().Get(gomock.Any(), "1").Return(&user, nil)
+				mock.EXPECT().TakeSecondFactorAttempt(gomock.Any(), "1", maxSecondFactorFailures, secondFactorLockout).Return(true, nil)
+				mock.EXPECT().UseRecoveryCode(gomock.Any(), "1", gomock.Any()).Return(false, nil)
+				return mock
+			},
+			args:    args{sum: 1000, threshold: 100, code: "wrong-code"},
+			wantErr: ErrInvalidCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			auditMock := audit.NewMockStorage(ctrl)
+			auditMock.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			s := &service{
+				storage: tt.storage(ctrl),
+				audit:   auditMock,
+			}
+			err := s.RequireSecondFactor(ctx, "1", tt.args.sum, tt.args.threshold, tt.args.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequireSecondFactor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // TODO как правильно неэкспортируемые методы самого сервиса замокать?
 func Test_service_Register(t *testing.T) {
 	ctx := context.Background()
@@ -124,14 +524,59 @@ func Test_service_Register(t *testing.T) {
 			want:    &userOut,
 			wantErr: false,
 		},
+		{
+			name: "invite code normalized and own code generated",
+			fields: fields{
+				log: nil,
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Register(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user models.User) (*models.User, error) {
+						if user.InviteCode != "ABCD2345" {
+							t.Errorf("invite code = %q", user.InviteCode)
+						}
+						if len(user.ReferralCode) != 8 {
+							t.Errorf("referral code = %q", user.ReferralCode)
+						}
+						return &userOut, nil
+					}).Times(1)
+					return mock
+				},
+			},
+			args: args{
+				ctx:    ctx,
+				userIn: models.User{Login: "login", Password: "password", InviteCode: " abcd2345 "},
+			},
+			want:    &userOut,
+			wantErr: false,
+		},
+		{
+			name: "unknown invite code",
+			fields: fields{
+				log: nil,
+				storage: func(ctrl *gomock.Controller) users.Storage {
+					mock := users.NewMockStorage(ctrl)
+					mock.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil, users.ErrUnknownReferral).Times(1)
+					return mock
+				},
+			},
+			args: args{
+				ctx:    ctx,
+				userIn: models.User{Login: "login", Password: "password", InviteCode: "NOPE"},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
+			auditMock := audit.NewMockStorage(ctrl)
+			auditMock.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 			s := &service{
 				log:     tt.fields.log,
 				storage: tt.fields.storage(ctrl),
+				audit:   auditMock,
 			}
 			got, err := s.Register(tt.args.ctx, tt.args.userIn)
 			if (err != nil) != tt.wantErr {
@@ -144,3 +589,140 @@ func Test_service_Register(t *testing.T) {
 		})
 	}
 }
+
+func Test_service_GetReferrals(t *testing.T) {
+	ctx := context.Background()
+	rewardedAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	referrals := []models.Referral{
+		{RefereeLogin: "friend", Status: models.ReferralRewarded, ReferrerReward: 100, RewardedAt: &rewardedAt},
+		{RefereeLogin: "other", Status: models.ReferralPending},
+	}
+
+	tests := []struct {
+		name       string
+		storage    func(ctrl *gomock.Controller) users.Storage
+		want       *models.ReferralProgram
+		wantEarned float64
+		wantErr    bool
+	}{
+		{
+			name: "with referrals",
+			storage: func(ctrl *gomock.Controller) users.Storage {
+				mock := users.NewMockStorage(ctrl)
+				mock.EXPECT().Get(gomock.Any(), "1").Return(&models.User{UserID: "1", ReferralCode: "ABCD2345"}, nil)
+				mock.EXPECT().GetReferrals(gomock.Any(), "1").Return(&referrals, nil)
+				return mock
+			},
+			want:       &models.ReferralProgram{Code: "ABCD2345", Referrals: referrals},
+			wantEarned: 100,
+		},
+		{
+			name: "no referrals yet",
+			storage: func(ctrl *gomock.Controller) users.Storage {
+				mock := users.NewMockStorage(ctrl)
+				mock.EXPECT().Get(gomock.Any(), "1").Return(&models.User{UserID: "1", ReferralCode: "ABCD2345"}, nil)
+				mock.EXPECT().GetReferrals(gomock.Any(), "1").Return(nil, users.ErrNotFound)
+				return mock
+			},
+			want: &models.ReferralProgram{Code: "ABCD2345"},
+		},
+		{
+			name: "storage error",
+			storage: func(ctrl *gomock.Controller) users.Storage {
+				mock := users.NewMockStorage(ctrl)
+				mock.EXPECT().Get(gomock.Any(), "1").Return(&models.User{UserID: "1", ReferralCode: "ABCD2345"}, nil)
+				mock.EXPECT().GetReferrals(gomock.Any(), "1").Return(nil, errors.New("db down"))
+				return mock
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			s := &service{storage: tt.storage(ctrl)}
+			got, err := s.GetReferrals(ctx, "1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetReferrals() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetReferrals() got = %v, want %v", got, tt.want)
+			}
+			if got != nil && got.Earned() != tt.wantEarned {
+				t.Errorf("Earned() = %v, want %v", got.Earned(), tt.wantEarned)
+			}
+		})
+	}
+}
+
+func Test_service_RequestDeletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	requestedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	deletion := &models.AccountDeletion{RequestedAt: requestedAt, ScheduledAt: requestedAt.Add(720 * time.Hour)}
+
+	storage := users.NewMockStorage(ctrl)
+	storage.EXPECT().RequestDeletion(gomock.Any(), "1", 720*time.Hour).Return(deletion, nil)
+	storage.EXPECT().CancelDeletion(gomock.Any(), "1").Return(nil)
+	storage.EXPECT().CancelDeletion(gomock.Any(), "1").Return(users.ErrDeletionNotScheduled)
+	auditMock := audit.NewMockStorage(ctrl)
+	auditMock.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event models.AuditEvent) error {
+		if event.Type != models.AuditDeletionRequested || event.Payload["scheduled_at"] != "2024-03-31T12:00:00Z" {
+			t.Errorf("unexpected audit event %v", event)
+		}
+		return nil
+	})
+	auditMock.EXPECT().Add(gomock.Any(), models.AuditEvent{Type: models.AuditDeletionCancelled, ActorID: "1", UserID: "1"}).Return(nil)
+
+	s := &service{storage: storage, audit: auditMock, deletionGrace: 720 * time.Hour}
+
+	got, err := s.RequestDeletion(context.Background(), "1")
+	if err != nil || !reflect.DeepEqual(got, deletion) {
+		t.Errorf("RequestDeletion() got = %v, %v, want %v", got, err, deletion)
+	}
+	if err = s.CancelDeletion(context.Background(), "1"); err != nil {
+		t.Errorf("CancelDeletion() error = %v", err)
+	}
+	// повторная отмена не пишет событие
+	if err = s.CancelDeletion(context.Background(), "1"); !errors.Is(err, users.ErrDeletionNotScheduled) {
+		t.Errorf("CancelDeletion() error = %v, want %v", err, users.ErrDeletionNotScheduled)
+	}
+}
+
+func Test_service_CheckSession(t *testing.T) {
+	ctx := context.Background()
+	revokedAt := time.Date(2024, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+
+	tests := []struct {
+		name      string
+		revokedAt time.Time
+		storeErr  error
+		issuedAt  time.Time
+		wantErr   error
+	}{
+		{name: "never revoked", issuedAt: revokedAt},
+		{name: "issued before revocation", revokedAt: revokedAt, issuedAt: revokedAt.Add(-time.Hour), wantErr: ErrSessionRevoked},
+		// iat усечён до секунды, токен той же секунды не отличить от выпущенного до отзыва
+		{name: "issued in the same second", revokedAt: revokedAt, issuedAt: revokedAt.Truncate(time.Second), wantErr: ErrSessionRevoked},
+		{name: "issued after revocation", revokedAt: revokedAt, issuedAt: revokedAt.Add(time.Second).Truncate(time.Second)},
+		{name: "token without iat", revokedAt: revokedAt, wantErr: ErrSessionRevoked},
+		{name: "user gone", storeErr: users.ErrNotFound, issuedAt: revokedAt, wantErr: ErrSessionRevoked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			storage := users.NewMockStorage(ctrl)
+			storage.EXPECT().GetSessionsRevokedAt(gomock.Any(), "1").Return(tt.revokedAt, tt.storeErr)
+
+			s := &service{storage: storage}
+			err := s.CheckSession(ctx, "1", tt.issuedAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckSession() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
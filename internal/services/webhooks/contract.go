@@ -0,0 +1,24 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=webhooks
+
+var (
+	ErrInvalidURL    = errors.New("invalid webhook url")
+	ErrInvalidEvents = errors.New("invalid webhook events")
+	ErrInvalidSecret = errors.New("invalid webhook secret")
+)
+
+type Service interface {
+	// Create регистрирует вебхук. Пустой секрет генерируется; вернувшийся секрет показывается пользователю один раз.
+	Create(ctx context.Context, webhook models.Webhook) (*models.Webhook, error)
+	GetAllByUser(ctx context.Context, userID string) (*[]models.Webhook, error)
+	Delete(ctx context.Context, userID string, webhookID string) error
+	GetDeliveries(ctx context.Context, userID string, webhookID string, limit int, offset int) (*[]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, userID string, webhookID string, deliveryID string) error
+}
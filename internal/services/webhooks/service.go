@@ -0,0 +1,136 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/netguard"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/webhooks"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+	"net"
+	"net/url"
+	"strings"
+)
+
+const (
+	secretBytes     = 32
+	minSecretLength = 16
+	maxURLLength    = 2048
+)
+
+type service struct {
+	log     *zap.Logger
+	storage webhooks.Storage
+}
+
+func New(log *zap.Logger, storage webhooks.Storage) Service {
+	return &service{log: log, storage: storage}
+}
+
+func (s *service) Create(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.Create")
+	defer span.End()
+
+	if !isValidURL(webhook.URL) {
+		return nil, ErrInvalidURL
+	}
+	events, ok := normalizeEvents(webhook.Events)
+	if !ok {
+		return nil, ErrInvalidEvents
+	}
+	webhook.Events = events
+
+	if webhook.Secret == "" {
+		secret := make([]byte, secretBytes)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+	if len(webhook.Secret) < minSecretLength {
+		return nil, ErrInvalidSecret
+	}
+
+	return s.storage.Add(ctx, webhook)
+}
+
+func (s *service) GetAllByUser(ctx context.Context, userID string) (*[]models.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.GetAllByUser")
+	defer span.End()
+
+	return s.storage.GetAllByUser(ctx, userID)
+}
+
+func (s *service) Delete(ctx context.Context, userID string, webhookID string) error {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.Delete")
+	defer span.End()
+
+	return s.storage.Delete(ctx, userID, webhookID)
+}
+
+func (s *service) GetDeliveries(ctx context.Context, userID string, webhookID string, limit int, offset int) (*[]models.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.GetDeliveries")
+	defer span.End()
+
+	return s.storage.GetDeliveries(ctx, userID, webhookID, limit, offset)
+}
+
+func (s *service) Redeliver(ctx context.Context, userID string, webhookID string, deliveryID string) error {
+	ctx, span := tracing.Start(ctx, "webhooks.Service.Redeliver")
+	defer span.End()
+
+	return s.storage.Redeliver(ctx, userID, webhookID, deliveryID)
+}
+
+// isValidURL пропускает только абсолютные http(s)-адреса без учётных данных в URL и не во внутреннюю сеть.
+// Имена здесь не разрешаются: адрес, в который они указывают, проверяется при каждой доставке (netguard.Control)
+func isValidURL(raw string) bool {
+	if raw == "" || len(raw) > maxURLLength {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil && !netguard.IsPublic(ip) {
+		return false
+	}
+	return true
+}
+
+// normalizeEvents убирает повторы; пустой список означает подписку на все события
+func normalizeEvents(events []string) ([]string, bool) {
+	if len(events) == 0 {
+		return models.WebhookEventTypes, true
+	}
+	seen := make(map[string]bool, len(events))
+	var result []string
+	for _, event := range events {
+		if !isKnownEvent(event) {
+			return nil, false
+		}
+		if !seen[event] {
+			seen[event] = true
+			result = append(result, event)
+		}
+	}
+	return result, true
+}
+
+func isKnownEvent(event string) bool {
+	for _, known := range models.WebhookEventTypes {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package adjustments
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=adjustments
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("data conflict")
+)
+
+type Storage interface {
+	Add(ctx context.Context, adjustment models.Adjustment) (*models.Adjustment, error)
+	Get(ctx context.Context, adjustmentID string) (*models.Adjustment, error)
+	GetAllByStatus(ctx context.Context, status string) (*[]models.Adjustment, error)
+	GetAllAppliedByUser(ctx context.Context, userID string) (*[]models.Adjustment, error)
+	Reject(ctx context.Context, adjustmentID string, deciderID string) error
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package adjustments is a generated GoMock package.
+package adjustments
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockStorage) Add(ctx context.Context, adjustment models.Adjustment) (*models.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, adjustment)
+	ret0, _ := ret[0].(*models.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockStorageMockRecorder) Add(ctx, adjustment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockStorage)(nil).Add), ctx, adjustment)
+}
+
+// Get mocks base method.
+func (m *MockStorage) Get(ctx context.Context, adjustmentID string) (*models.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, adjustmentID)
+	ret0, _ := ret[0].(*models.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStorageMockRecorder) Get(ctx, adjustmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStorage)(nil).Get), ctx, adjustmentID)
+}
+
+// GetAllAppliedByUser mocks base method.
+func (m *MockStorage) GetAllAppliedByUser(ctx context.Context, userID string) (*[]models.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllAppliedByUser", ctx, userID)
+	ret0, _ := ret[0].(*[]models.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllAppliedByUser indicates an expected call of GetAllAppliedByUser.
+func (mr *MockStorageMockRecorder) GetAllAppliedByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllAppliedByUser", reflect.TypeOf((*MockStorage)(nil).GetAllAppliedByUser), ctx, userID)
+}
+
+// GetAllByStatus mocks base method.
+func (m *MockStorage) GetAllByStatus(ctx context.Context, status string) (*[]models.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByStatus", ctx, status)
+	ret0, _ := ret[0].(*[]models.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByStatus indicates an expected call of GetAllByStatus.
+func (mr *MockStorageMockRecorder) GetAllByStatus(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByStatus", reflect.TypeOf((*MockStorage)(nil).GetAllByStatus), ctx, status)
+}
+
+// Reject mocks base method.
+func (m *MockStorage) Reject(ctx context.Context, adjustmentID, deciderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reject", ctx, adjustmentID, deciderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reject indicates an expected call of Reject.
+func (mr *MockStorageMockRecorder) Reject(ctx, adjustmentID, deciderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reject", reflect.TypeOf((*MockStorage)(nil).Reject), ctx, adjustmentID, deciderID)
+}
@@ -0,0 +1,141 @@
+package adjustments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"time"
+)
+
+const timeOut = 500 * time.Millisecond
+
+const selectAdjustment = `SELECT id, user_id, type, amount, reason, note, status, created_by, decided_by, created_at, decided_at FROM adjustments`
+
+type storage struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) Storage {
+	return &storage{db: db}
+}
+
+func (s *storage) Add(ctx context.Context, adjustment models.Adjustment) (*models.Adjustment, error) {
+	ctx, span := tracing.Start(ctx, "adjustments.Storage.Add")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO adjustments(user_id, type, amount, reason, note, created_by) VALUES ($1, $2, $3, $4, $5, $6) returning id, status, created_at`,
+		adjustment.UserID, adjustment.Type, adjustment.Amount, adjustment.Reason, adjustment.Note, adjustment.CreatedBy)
+
+	err := row.Scan(&adjustment.ID, &adjustment.Status, &adjustment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+func (s *storage) Get(ctx context.Context, adjustmentID string) (*models.Adjustment, error) {
+	ctx, span := tracing.Start(ctx, "adjustments.Storage.Get")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	row := s.db.QueryRowContext(ctx, selectAdjustment+` WHERE id=$1`, adjustmentID)
+	adjustment, err := scan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+func (s *storage) GetAllByStatus(ctx context.Context, status string) (*[]models.Adjustment, error) {
+	ctx, span := tracing.Start(ctx, "adjustments.Storage.GetAllByStatus")
+	defer span.End()
+
+	return s.getAll(ctx, selectAdjustment+` WHERE status=$1 order by created_at`, status)
+}
+
+func (s *storage) GetAllAppliedByUser(ctx context.Context, userID string) (*[]models.Adjustment, error) {
+	ctx, span := tracing.Start(ctx, "adjustments.Storage.GetAllAppliedByUser")
+	defer span.End()
+
+	return s.getAll(ctx, selectAdjustment+` WHERE user_id=$1 AND status='APPLIED' order by decided_at`, userID)
+}
+
+func (s *storage) Reject(ctx context.Context, adjustmentID string, deciderID string) error {
+	ctx, span := tracing.Start(ctx, "adjustments.Storage.Reject")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE adjustments SET status='REJECTED', decided_by=$1, decided_at=CURRENT_TIMESTAMP WHERE id=$2 AND status='PENDING'`, deciderID, adjustmentID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *storage) getAll(ctx context.Context, query string, args ...any) (*[]models.Adjustment, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var adjustments []models.Adjustment
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		adjustment, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, *adjustment)
+	}
+	if len(adjustments) == 0 {
+		return nil, ErrNotFound
+	}
+	return &adjustments, err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scan(row scanner) (*models.Adjustment, error) {
+	var adjustment models.Adjustment
+	var decidedBy sql.NullString
+	var decidedAt sql.NullTime
+
+	err := row.Scan(&adjustment.ID, &adjustment.UserID, &adjustment.Type, &adjustment.Amount, &adjustment.Reason,
+		&adjustment.Note, &adjustment.Status, &adjustment.CreatedBy, &decidedBy, &adjustment.CreatedAt, &decidedAt)
+	if err != nil {
+		return nil, err
+	}
+	adjustment.DecidedBy = decidedBy.String
+	adjustment.DecidedAt = decidedAt.Time
+	return &adjustment, nil
+}
@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=audit
+
+var ErrNotFound = errors.New("not found")
+
+type Storage interface {
+	// Add пишет событие отдельно от других изменений — для событий без изменения бизнес-данных
+	Add(ctx context.Context, event models.AuditEvent) error
+	// Append пишет событие в транзакции вызывающего, чтобы оно зафиксировалось вместе с бизнес-изменением
+	Append(ctx context.Context, tx *sql.Tx, event models.AuditEvent) error
+	// Chain включает записанные события в цепочку хешей; до этого Find и GetBatch их не видят
+	Chain(ctx context.Context, limit int) (int, error)
+	Find(ctx context.Context, filter models.AuditFilter) (*[]models.AuditEvent, error)
+	GetBatch(ctx context.Context, afterID string, limit int) (*[]models.AuditEvent, error)
+}
@@ -0,0 +1,240 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	timeOut = 500 * time.Millisecond
+	// chainTimeOut — на перенос пачки событий в цепочку, там по вставке на событие
+	chainTimeOut = 5 * time.Second
+)
+
+// chainLockKey — ключ advisory-блокировки, которую держит только тот, кто сейчас ведёт цепочку
+const chainLockKey = 7290851
+
+const selectEvent = `SELECT id, type, actor_id, user_id, payload, created_at, prev_hash, hash FROM audit_events`
+
+type storage struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) Storage {
+	return &storage{db: db}
+}
+
+func (s *storage) Add(ctx context.Context, event models.AuditEvent) error {
+	ctx, span := tracing.Start(ctx, "audit.Storage.Add")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, insertOutbox, outboxArgs(event)...)
+	return err
+}
+
+// Append не берёт блокировок: транзакции с деньгами не ждут друг друга из-за аудита.
+// Хеш событию назначит Chain после коммита.
+func (s *storage) Append(ctx context.Context, tx *sql.Tx, event models.AuditEvent) error {
+	ctx, span := tracing.Start(ctx, "audit.Storage.Append")
+	defer span.End()
+
+	_, err := tx.ExecContext(ctx, insertOutbox, outboxArgs(event)...)
+	return err
+}
+
+const insertOutbox = `INSERT INTO audit_outbox(type, actor_id, user_id, payload, created_at) VALUES ($1, $2, $3, $4, $5)`
+
+func outboxArgs(event models.AuditEvent) []any {
+	// Postgres хранит микросекунды, обрезаем заранее, чтобы хеш сошёлся при проверке
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+	return []any{event.Type, nullString(event.ActorID), nullString(event.UserID), event.MarshalPayload(), createdAt}
+}
+
+// Chain переносит до limit событий из audit_outbox в цепочку audit_events в порядке записи и возвращает,
+// сколько перенесено. Если цепочку сейчас ведёт другой экземпляр, ничего не делает.
+func (s *storage) Chain(ctx context.Context, limit int) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "audit.Storage.Chain")
+	defer span.End()
+	defer func() { logTxError(ctx, "Chain", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, chainTimeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	err = tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, chainLockKey).Scan(&locked)
+	if err != nil || !locked {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, type, actor_id, user_id, payload, created_at FROM audit_outbox ORDER BY id LIMIT $1`, limit)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	var events []models.AuditEvent
+	for rows.Next() {
+		var id int64
+		var event models.AuditEvent
+		var actorID, userID sql.NullString
+		var payload string
+		err = rows.Scan(&id, &event.Type, &actorID, &userID, &payload, &event.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		event.ActorID = actorID.String
+		event.UserID = userID.String
+		err = json.Unmarshal([]byte(payload), &event.Payload)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+		events = append(events, event)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	prevHash := models.AuditGenesisHash
+	err = tx.QueryRowContext(ctx, `SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	for _, event := range events {
+		event.PrevHash = prevHash
+		event.Hash = event.ComputeHash()
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO audit_events(type, actor_id, user_id, payload, created_at, prev_hash, hash) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			event.Type, nullString(event.ActorID), nullString(event.UserID), event.MarshalPayload(), event.CreatedAt, event.PrevHash, event.Hash)
+		if err != nil {
+			return 0, err
+		}
+		prevHash = event.Hash
+	}
+
+	// удаляем ровно прочитанные строки: событие с меньшим id могло закоммититься уже после чтения
+	_, err = tx.ExecContext(ctx, `DELETE FROM audit_outbox WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return 0, err
+	}
+	return len(events), tx.Commit()
+}
+
+func (s *storage) Find(ctx context.Context, filter models.AuditFilter) (*[]models.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "audit.Storage.Find")
+	defer span.End()
+
+	var conditions []string
+	var args []any
+
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, "user_id=$"+strconv.Itoa(len(args)))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, "type=$"+strconv.Itoa(len(args)))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, "created_at>=$"+strconv.Itoa(len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, "created_at<$"+strconv.Itoa(len(args)))
+	}
+
+	query := selectEvent
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += " order by id LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+
+	return s.getAll(ctx, query, args...)
+}
+
+func (s *storage) GetBatch(ctx context.Context, afterID string, limit int) (*[]models.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "audit.Storage.GetBatch")
+	defer span.End()
+
+	if afterID == "" {
+		afterID = "0"
+	}
+	return s.getAll(ctx, selectEvent+` WHERE id>$1 order by id LIMIT $2`, afterID, limit)
+}
+
+func (s *storage) getAll(ctx context.Context, query string, args ...any) (*[]models.AuditEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var events []models.AuditEvent
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+
+		var event models.AuditEvent
+		var actorID, userID sql.NullString
+		var payload string
+
+		err = rows.Scan(&event.ID, &event.Type, &actorID, &userID, &payload, &event.CreatedAt, &event.PrevHash, &event.Hash)
+		if err != nil {
+			return nil, err
+		}
+		event.ActorID = actorID.String
+		event.UserID = userID.String
+		err = json.Unmarshal([]byte(payload), &event.Payload)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return nil, ErrNotFound
+	}
+	return &events, err
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// logTxError пишет в лог запроса сбой транзакции; ожидаемые бизнес-ошибки сюда не попадают
+func logTxError(ctx context.Context, op string, err error) {
+	if err == nil {
+		return
+	}
+	logger.FromContext(ctx).Error("Audit storage transaction failed", zap.String("op", op), zap.Error(err))
+}
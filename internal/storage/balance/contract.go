@@ -4,11 +4,23 @@ import (
 	"context"
 	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
 )
 
 //go:generate mockgen -source=contract.go -destination=contract_mock.go -package=balance
 
-var ErrNotFound = errors.New("not found")
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("data conflict")
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrRecipientNotFound  = errors.New("recipient not found")
+	ErrSelfTransfer       = errors.New("transfer to yourself")
+	ErrTransferLimit      = errors.New("daily transfer limit exceeded")
+	ErrHoldNotFound       = errors.New("hold not found")
+	ErrHoldNotActive      = errors.New("hold is not active")
+	ErrWithdrawalNotFound = errors.New("withdrawal not found")
+	ErrReversalExceeded   = errors.New("reversal exceeds withdrawn sum")
+)
 
 // TODO Для баланса и списаний отдельные сторейдж? потому что работает с таблицей balances и withdrawals
 type Storage interface {
@@ -17,4 +29,20 @@ type Storage interface {
 	AddWithdraw(ctx context.Context, withdraw models.Withdrawal, userID string) error
 	SetBalance(ctx context.Context, sum float64, userID string) error
 	GetAllWithdrawByUser(ctx context.Context, userID string) (*[]models.Withdrawal, error)
+	StreamWithdrawByUser(ctx context.Context, userID string, fn func(models.Withdrawal) error) error
+	ApplyAdjustment(ctx context.Context, adjustment models.Adjustment, deciderID string) error
+	Accrue(ctx context.Context, order models.Order, bonuses []models.CampaignBonus) (models.AccrualResult, bool, error)
+	GetNextExpiry(ctx context.Context, userID string) (*models.Expiry, error)
+	GetUsersWithLapsedLots(ctx context.Context, limit int) ([]string, error)
+	ExpireLots(ctx context.Context, userID string) (float64, error)
+	Transfer(ctx context.Context, fromUserID string, toLogin string, sum float64, dailyLimit float64) (*models.Transfer, error)
+	GetTransfers(ctx context.Context, userID string) (*[]models.Transfer, error)
+	Hold(ctx context.Context, hold models.Hold, ttl time.Duration) (*models.Hold, error)
+	CaptureHold(ctx context.Context, userID string, holdID string) (*models.Hold, error)
+	VoidHold(ctx context.Context, userID string, holdID string) (*models.Hold, error)
+	ExpireHolds(ctx context.Context, limit int) ([]models.Hold, error)
+	GetHeld(ctx context.Context, userID string) (float64, error)
+	ReverseWithdrawal(ctx context.Context, reversal models.Reversal) (*models.Reversal, error)
+	GetStatement(ctx context.Context, userID string, filter models.StatementFilter) (*[]models.StatementEntry, error)
+	GetBalanceAt(ctx context.Context, userID string, at time.Time) (float64, error)
 }
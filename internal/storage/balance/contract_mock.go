@@ -7,6 +7,7 @@ package balance
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,22 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// Accrue mocks base method.
+func (m *MockStorage) Accrue(ctx context.Context, order models.Order, bonuses []models.CampaignBonus) (models.AccrualResult, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accrue", ctx, order, bonuses)
+	ret0, _ := ret[0].(models.AccrualResult)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Accrue indicates an expected call of Accrue.
+func (mr *MockStorageMockRecorder) Accrue(ctx, order, bonuses interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accrue", reflect.TypeOf((*MockStorage)(nil).Accrue), ctx, order, bonuses)
+}
+
 // AddWithdraw mocks base method.
 func (m *MockStorage) AddWithdraw(ctx context.Context, withdraw models.Withdrawal, userID string) error {
 	m.ctrl.T.Helper()
@@ -49,6 +66,65 @@ func (mr *MockStorageMockRecorder) AddWithdraw(ctx, withdraw, userID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithdraw", reflect.TypeOf((*MockStorage)(nil).AddWithdraw), ctx, withdraw, userID)
 }
 
+// ApplyAdjustment mocks base method.
+func (m *MockStorage) ApplyAdjustment(ctx context.Context, adjustment models.Adjustment, deciderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyAdjustment", ctx, adjustment, deciderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyAdjustment indicates an expected call of ApplyAdjustment.
+func (mr *MockStorageMockRecorder) ApplyAdjustment(ctx, adjustment, deciderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyAdjustment", reflect.TypeOf((*MockStorage)(nil).ApplyAdjustment), ctx, adjustment, deciderID)
+}
+
+// CaptureHold mocks base method.
+func (m *MockStorage) CaptureHold(ctx context.Context, userID, holdID string) (*models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(*models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockStorageMockRecorder) CaptureHold(ctx, userID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockStorage)(nil).CaptureHold), ctx, userID, holdID)
+}
+
+// ExpireHolds mocks base method.
+func (m *MockStorage) ExpireHolds(ctx context.Context, limit int) ([]models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds", ctx, limit)
+	ret0, _ := ret[0].([]models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds.
+func (mr *MockStorageMockRecorder) ExpireHolds(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockStorage)(nil).ExpireHolds), ctx, limit)
+}
+
+// ExpireLots mocks base method.
+func (m *MockStorage) ExpireLots(ctx context.Context, userID string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireLots", ctx, userID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireLots indicates an expected call of ExpireLots.
+func (mr *MockStorageMockRecorder) ExpireLots(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireLots", reflect.TypeOf((*MockStorage)(nil).ExpireLots), ctx, userID)
+}
+
 // GetAllWithdrawByUser mocks base method.
 func (m *MockStorage) GetAllWithdrawByUser(ctx context.Context, userID string) (*[]models.Withdrawal, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +155,66 @@ func (mr *MockStorageMockRecorder) GetBalance(ctx, userID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockStorage)(nil).GetBalance), ctx, userID)
 }
 
+// GetBalanceAt mocks base method.
+func (m *MockStorage) GetBalanceAt(ctx context.Context, userID string, at time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", ctx, userID, at)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockStorageMockRecorder) GetBalanceAt(ctx, userID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockStorage)(nil).GetBalanceAt), ctx, userID, at)
+}
+
+// GetHeld mocks base method.
+func (m *MockStorage) GetHeld(ctx context.Context, userID string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeld", ctx, userID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeld indicates an expected call of GetHeld.
+func (mr *MockStorageMockRecorder) GetHeld(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeld", reflect.TypeOf((*MockStorage)(nil).GetHeld), ctx, userID)
+}
+
+// GetNextExpiry mocks base method.
+func (m *MockStorage) GetNextExpiry(ctx context.Context, userID string) (*models.Expiry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextExpiry", ctx, userID)
+	ret0, _ := ret[0].(*models.Expiry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextExpiry indicates an expected call of GetNextExpiry.
+func (mr *MockStorageMockRecorder) GetNextExpiry(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextExpiry", reflect.TypeOf((*MockStorage)(nil).GetNextExpiry), ctx, userID)
+}
+
+// GetStatement mocks base method.
+func (m *MockStorage) GetStatement(ctx context.Context, userID string, filter models.StatementFilter) (*[]models.StatementEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", ctx, userID, filter)
+	ret0, _ := ret[0].(*[]models.StatementEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockStorageMockRecorder) GetStatement(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockStorage)(nil).GetStatement), ctx, userID, filter)
+}
+
 // GetSumWithdrawal mocks base method.
 func (m *MockStorage) GetSumWithdrawal(ctx context.Context, userID string) (float64, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +230,66 @@ func (mr *MockStorageMockRecorder) GetSumWithdrawal(ctx, userID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSumWithdrawal", reflect.TypeOf((*MockStorage)(nil).GetSumWithdrawal), ctx, userID)
 }
 
+// GetTransfers mocks base method.
+func (m *MockStorage) GetTransfers(ctx context.Context, userID string) (*[]models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfers", ctx, userID)
+	ret0, _ := ret[0].(*[]models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfers indicates an expected call of GetTransfers.
+func (mr *MockStorageMockRecorder) GetTransfers(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfers", reflect.TypeOf((*MockStorage)(nil).GetTransfers), ctx, userID)
+}
+
+// GetUsersWithLapsedLots mocks base method.
+func (m *MockStorage) GetUsersWithLapsedLots(ctx context.Context, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersWithLapsedLots", ctx, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersWithLapsedLots indicates an expected call of GetUsersWithLapsedLots.
+func (mr *MockStorageMockRecorder) GetUsersWithLapsedLots(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersWithLapsedLots", reflect.TypeOf((*MockStorage)(nil).GetUsersWithLapsedLots), ctx, limit)
+}
+
+// Hold mocks base method.
+func (m *MockStorage) Hold(ctx context.Context, hold models.Hold, ttl time.Duration) (*models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hold", ctx, hold, ttl)
+	ret0, _ := ret[0].(*models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hold indicates an expected call of Hold.
+func (mr *MockStorageMockRecorder) Hold(ctx, hold, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hold", reflect.TypeOf((*MockStorage)(nil).Hold), ctx, hold, ttl)
+}
+
+// ReverseWithdrawal mocks base method.
+func (m *MockStorage) ReverseWithdrawal(ctx context.Context, reversal models.Reversal) (*models.Reversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseWithdrawal", ctx, reversal)
+	ret0, _ := ret[0].(*models.Reversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseWithdrawal indicates an expected call of ReverseWithdrawal.
+func (mr *MockStorageMockRecorder) ReverseWithdrawal(ctx, reversal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseWithdrawal", reflect.TypeOf((*MockStorage)(nil).ReverseWithdrawal), ctx, reversal)
+}
+
 // SetBalance mocks base method.
 func (m *MockStorage) SetBalance(ctx context.Context, sum float64, userID string) error {
 	m.ctrl.T.Helper()
@@ -107,3 +303,47 @@ func (mr *MockStorageMockRecorder) SetBalance(ctx, sum, userID interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBalance", reflect.TypeOf((*MockStorage)(nil).SetBalance), ctx, sum, userID)
 }
+
+// StreamWithdrawByUser mocks base method.
+func (m *MockStorage) StreamWithdrawByUser(ctx context.Context, userID string, fn func(models.Withdrawal) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamWithdrawByUser", ctx, userID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamWithdrawByUser indicates an expected call of StreamWithdrawByUser.
+func (mr *MockStorageMockRecorder) StreamWithdrawByUser(ctx, userID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamWithdrawByUser", reflect.TypeOf((*MockStorage)(nil).StreamWithdrawByUser), ctx, userID, fn)
+}
+
+// Transfer mocks base method.
+func (m *MockStorage) Transfer(ctx context.Context, fromUserID, toLogin string, sum, dailyLimit float64) (*models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, fromUserID, toLogin, sum, dailyLimit)
+	ret0, _ := ret[0].(*models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockStorageMockRecorder) Transfer(ctx, fromUserID, toLogin, sum, dailyLimit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockStorage)(nil).Transfer), ctx, fromUserID, toLogin, sum, dailyLimit)
+}
+
+// VoidHold mocks base method.
+func (m *MockStorage) VoidHold(ctx context.Context, userID, holdID string) (*models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(*models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidHold indicates an expected call of VoidHold.
+func (mr *MockStorageMockRecorder) VoidHold(ctx, userID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHold", reflect.TypeOf((*MockStorage)(nil).VoidHold), ctx, userID, holdID)
+}
@@ -0,0 +1,227 @@
+package balance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"time"
+)
+
+// heldSumQuery — сколько баллов пользователя ($1) сейчас под блокировками. Просроченные блокировки
+// не считаются, даже если сборщик их ещё не закрыл.
+const heldSumQuery = `SELECT coalesce(sum(sum), 0) FROM withdrawal_holds WHERE user_id=$1 AND status='HELD' AND expires_at > CURRENT_TIMESTAMP`
+
+// heldByBalanceQuery — то же самое для подзапроса внутри UPDATE balances: пользователь берётся из обновляемой строки
+const heldByBalanceQuery = `SELECT coalesce(sum(h.sum), 0) FROM withdrawal_holds h
+	WHERE h.user_id=balances.user_id AND h.status='HELD' AND h.expires_at > CURRENT_TIMESTAMP`
+
+const holdColumns = `id, user_id, number, sum, status, created_at, expires_at, resolved_at`
+
+// Hold откладывает баллы под заказ. Баланс не меняется — блокировка лишь уменьшает доступную сумму,
+// поэтому партии и их сроки не трогаем до capture.
+func (s *storage) Hold(ctx context.Context, hold models.Hold, ttl time.Duration) (_ *models.Hold, err error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.Hold")
+	defer span.End()
+	defer func() { logTxError(ctx, "Hold", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var balance sql.NullFloat64
+	err = tx.QueryRowContext(ctx, `SELECT sum FROM balances WHERE user_id=$1 FOR UPDATE`, hold.UserID).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInsufficientFunds
+	}
+	if err != nil {
+		return nil, err
+	}
+	var held float64
+	err = tx.QueryRowContext(ctx, heldSumQuery, hold.UserID).Scan(&held)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Float64-held < hold.Sum {
+		return nil, ErrInsufficientFunds
+	}
+
+	row := tx.QueryRowContext(ctx,
+		`INSERT INTO withdrawal_holds(user_id, number, sum, expires_at) VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(secs => $4))
+		RETURNING `+holdColumns, hold.UserID, hold.Number, hold.Sum, ttl.Seconds())
+	created, err := scanHold(row)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return created, tx.Commit()
+}
+
+// CaptureHold превращает блокировку в обычное списание
+func (s *storage) CaptureHold(ctx context.Context, userID string, holdID string) (_ *models.Hold, err error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.CaptureHold")
+	defer span.End()
+	defer func() { logTxError(ctx, "CaptureHold", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// строка balances блокируется первой — как в любом списании
+	_, err = tx.ExecContext(ctx, `SELECT sum FROM balances WHERE user_id=$1 FOR UPDATE`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := s.resolveHold(ctx, tx, userID, holdID, models.HoldCaptured)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawalID, err := s.withdraw(ctx, tx, models.Withdrawal{OrderNumber: hold.Number, Sum: hold.Sum}, userID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE withdrawal_holds SET withdrawal_id=$1 WHERE id=$2`, withdrawalID, hold.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, tx.Commit()
+}
+
+// VoidHold отпускает блокировку; баланс не меняется
+func (s *storage) VoidHold(ctx context.Context, userID string, holdID string) (_ *models.Hold, err error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.VoidHold")
+	defer span.End()
+	defer func() { logTxError(ctx, "VoidHold", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, err := s.resolveHold(ctx, tx, userID, holdID, models.HoldVoided)
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, tx.Commit()
+}
+
+// resolveHold закрывает активную блокировку пользователя. Просроченную закрыть нельзя — её отпустит сборщик.
+func (s *storage) resolveHold(ctx context.Context, tx *sql.Tx, userID string, holdID string, status string) (*models.Hold, error) {
+	row := tx.QueryRowContext(ctx,
+		`UPDATE withdrawal_holds SET status=$3, resolved_at=CURRENT_TIMESTAMP
+		WHERE id=$1 AND user_id=$2 AND status='HELD' AND expires_at > CURRENT_TIMESTAMP RETURNING `+holdColumns,
+		holdID, userID, status)
+	hold, err := scanHold(row)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return hold, err
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM withdrawal_holds WHERE id=$1 AND user_id=$2)`, holdID, userID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrHoldNotFound
+	}
+	return nil, ErrHoldNotActive
+}
+
+// voidUnfundedHolds отпускает блокировки, которые баланс больше не покрывает (например, после сгорания партий).
+// Старые блокировки сохраняются, отпускаются самые новые — те, на которых нарастающая сумма превышает баланс.
+func (s *storage) voidUnfundedHolds(ctx context.Context, tx *sql.Tx, userID string) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE withdrawal_holds SET status='VOIDED', resolved_at=CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, sum(sum) OVER (ORDER BY created_at, id) AS running FROM withdrawal_holds
+				WHERE user_id=$1 AND status='HELD' AND expires_at > CURRENT_TIMESTAMP
+			) active
+			WHERE running > (SELECT coalesce(sum, 0) FROM balances WHERE user_id=$1)
+		)`, userID)
+	return err
+}
+
+// ExpireHolds закрывает просроченные блокировки и возвращает их
+func (s *storage) ExpireHolds(ctx context.Context, limit int) ([]models.Hold, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.ExpireHolds")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx,
+		`UPDATE withdrawal_holds SET status='EXPIRED', resolved_at=CURRENT_TIMESTAMP
+		WHERE id IN (SELECT id FROM withdrawal_holds WHERE status='HELD' AND expires_at <= CURRENT_TIMESTAMP
+			ORDER BY expires_at LIMIT $1 FOR UPDATE SKIP LOCKED)
+		RETURNING `+holdColumns, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []models.Hold
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, *hold)
+	}
+	return holds, rows.Err()
+}
+
+func (s *storage) GetHeld(ctx context.Context, userID string) (float64, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.GetHeld")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var held float64
+	err := s.db.QueryRowContext(ctx, heldSumQuery, userID).Scan(&held)
+	return held, err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanHold(row scanner) (*models.Hold, error) {
+	var hold models.Hold
+	var resolvedAt sql.NullTime
+	err := row.Scan(&hold.ID, &hold.UserID, &hold.Number, &hold.Sum, &hold.Status, &hold.CreatedAt, &hold.ExpiresAt, &resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	if resolvedAt.Valid {
+		hold.ResolvedAt = &resolvedAt.Time
+	}
+	return &hold, nil
+}
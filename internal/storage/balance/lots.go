@@ -0,0 +1,198 @@
+package balance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"strconv"
+)
+
+// Баллы хранятся партиями (point_lots): balances.sum всегда равен сумме remaining по партиям пользователя.
+// Блокировки берутся в одном порядке — сначала строка balances, потом партии, — иначе списание и сгорание
+// могут взаимно заблокироваться.
+
+// addLot заводит партию на начисление. Срок считаем в базе, чтобы он шёл от времени транзакции.
+func (s *storage) addLot(ctx context.Context, tx *sql.Tx, userID, source, sourceID string, amount float64) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO point_lots(user_id, source, source_id, amount, remaining, expires_at)
+		VALUES ($1, $2, $3, $4, $4, CASE WHEN $5 > 0 THEN CURRENT_TIMESTAMP + make_interval(months => $5) END)`,
+		userID, source, sourceID, amount, s.policy.Months)
+	return err
+}
+
+// consumeLots списывает amount с партий в порядке политики. Достаточность средств уже проверена на balances.
+func (s *storage) consumeLots(ctx context.Context, tx *sql.Tx, userID string, amount float64) error {
+	order := `accrued_at, id`
+	if s.policy.Consumption == models.ConsumeExpiringFirst {
+		order = `expires_at NULLS LAST, accrued_at, id`
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, remaining FROM point_lots WHERE user_id=$1 AND remaining > 0 ORDER BY `+order+` FOR UPDATE`, userID)
+	if err != nil {
+		return err
+	}
+
+	type lot struct {
+		id        string
+		remaining float64
+	}
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		err = rows.Scan(&l.id, &l.remaining)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, l := range lots {
+		if amount <= 0 {
+			break
+		}
+		take := l.remaining
+		if take > amount {
+			take = amount
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE point_lots SET remaining = remaining - $1 WHERE id=$2`, take, l.id)
+		if err != nil {
+			return err
+		}
+		amount -= take
+	}
+	return nil
+}
+
+// GetNextExpiry — ближайшая дата сгорания и сколько сгорит в течение суток от неё
+func (s *storage) GetNextExpiry(ctx context.Context, userID string) (*models.Expiry, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.GetNextExpiry")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	expiry := &models.Expiry{}
+	err := s.db.QueryRowContext(ctx,
+		`WITH next AS (SELECT min(expires_at) AS at FROM point_lots WHERE user_id=$1 AND remaining > 0 AND expires_at IS NOT NULL)
+		SELECT next.at, sum(l.remaining) FROM point_lots l, next
+		WHERE l.user_id=$1 AND l.remaining > 0 AND l.expires_at < next.at + interval '1 day'
+		GROUP BY next.at`, userID).Scan(&expiry.ExpiresAt, &expiry.Sum)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return expiry, nil
+}
+
+// GetUsersWithLapsedLots — пользователи, у которых есть сгоревшие, но ещё не списанные партии
+func (s *storage) GetUsersWithLapsedLots(ctx context.Context, limit int) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.GetUsersWithLapsedLots")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT DISTINCT user_id FROM point_lots WHERE remaining > 0 AND expires_at <= CURRENT_TIMESTAMP LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []string
+	for rows.Next() {
+		var userID string
+		err = rows.Scan(&userID)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, userID)
+	}
+	return users, rows.Err()
+}
+
+// ExpireLots списывает с баланса все сгоревшие партии пользователя и пишет каждую в аудит, а блокировки,
+// которые после этого не покрыты балансом, отпускает. Возвращает сколько сгорело.
+func (s *storage) ExpireLots(ctx context.Context, userID string) (_ float64, err error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.ExpireLots")
+	defer span.End()
+	defer func() { logTxError(ctx, "ExpireLots", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `SELECT 1 FROM balances WHERE user_id=$1 FOR UPDATE`, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`UPDATE point_lots SET expired = remaining, remaining = 0, expired_at = CURRENT_TIMESTAMP
+		WHERE user_id=$1 AND remaining > 0 AND expires_at <= CURRENT_TIMESTAMP
+		RETURNING id, source, source_id, expired`, userID)
+	if err != nil {
+		return 0, err
+	}
+	var events []models.AuditEvent
+	var total float64
+	for rows.Next() {
+		var id, source, sourceID string
+		var expired float64
+		err = rows.Scan(&id, &source, &sourceID, &expired)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		total += expired
+		events = append(events, models.AuditEvent{
+			Type:   models.AuditPointsExpired,
+			UserID: userID,
+			Payload: map[string]string{
+				"lot_id":    id,
+				"source":    source,
+				"source_id": sourceID,
+				"amount":    strconv.FormatFloat(expired, 'f', -1, 64),
+			},
+		})
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE balances SET sum = GREATEST(sum - $1, 0) WHERE user_id=$2`, total, userID)
+	if err != nil {
+		return 0, err
+	}
+	// сгоревшие баллы могли быть под блокировками — такие блокировки больше нечем покрыть
+	err = s.voidUnfundedHolds(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range events {
+		err = s.audit.Append(ctx, tx, event)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return total, tx.Commit()
+}
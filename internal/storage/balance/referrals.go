@@ -0,0 +1,113 @@
+package balance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"strconv"
+)
+
+// rewardReferral начисляет награды обеим сторонам приглашения на первом начисленном заказе приглашённого.
+// Приглашение в PENDING бывает только до первой награды, поэтому повторный заказ ничего не начислит.
+// Строки balances обеих сторон к этому моменту должны быть заблокированы через lockBalances (см. Accrue):
+// иначе встречный перевод между ними заблокирует их в другом порядке.
+// Возвращает, чьи балансы изменились, чтобы вызывающий мог о них сообщить.
+func (s *storage) rewardReferral(ctx context.Context, tx *sql.Tx, refereeID, orderNumber string) (refereeRewarded bool, referrerID string, err error) {
+	if s.referral.ReferrerBonus <= 0 && s.referral.RefereeBonus <= 0 {
+		return false, "", nil
+	}
+
+	var referralID string
+	err = tx.QueryRowContext(ctx,
+		`SELECT id, referrer_id FROM referrals WHERE referee_id=$1 AND status='PENDING' FOR UPDATE`, refereeID).Scan(&referralID, &referrerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	if s.referral.Cap > 0 {
+		// блокируем пригласившего, чтобы параллельные награды его приглашённым не превысили лимит
+		_, err = tx.ExecContext(ctx, `SELECT id FROM users WHERE id=$1 FOR NO KEY UPDATE`, referrerID)
+		if err != nil {
+			return false, "", err
+		}
+		var rewarded int
+		err = tx.QueryRowContext(ctx,
+			`SELECT count(*) FROM referrals WHERE referrer_id=$1 AND status='REWARDED'`, referrerID).Scan(&rewarded)
+		if err != nil {
+			return false, "", err
+		}
+		if rewarded >= s.referral.Cap {
+			return false, "", s.rejectReferral(ctx, tx, referralID, orderNumber, models.ReferralReasonCap)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE referrals SET status='REWARDED', order_number=$2, referrer_reward=$3, referee_reward=$4, rewarded_at=CURRENT_TIMESTAMP WHERE id=$1`,
+		referralID, orderNumber, s.referral.ReferrerBonus, s.referral.RefereeBonus)
+	if err != nil {
+		return false, "", err
+	}
+
+	err = s.creditReferral(ctx, tx, refereeID, referralID, orderNumber, "referee", s.referral.RefereeBonus)
+	if err != nil {
+		return false, "", err
+	}
+	err = s.creditReferral(ctx, tx, referrerID, referralID, orderNumber, "referrer", s.referral.ReferrerBonus)
+	if err != nil {
+		return false, "", err
+	}
+	if s.referral.ReferrerBonus <= 0 {
+		referrerID = ""
+	}
+	return s.referral.RefereeBonus > 0, referrerID, nil
+}
+
+// pendingReferrer — кто пригласил пользователя, если награда за приглашение ещё не начислена.
+// Пустая строка — наград не будет: приглашения нет, оно уже закрыто или награды выключены
+func (s *storage) pendingReferrer(ctx context.Context, tx *sql.Tx, refereeID string) (string, error) {
+	if s.referral.ReferrerBonus <= 0 && s.referral.RefereeBonus <= 0 {
+		return "", nil
+	}
+	var referrerID string
+	err := tx.QueryRowContext(ctx,
+		`SELECT referrer_id FROM referrals WHERE referee_id=$1 AND status='PENDING'`, refereeID).Scan(&referrerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return referrerID, err
+}
+
+func (s *storage) rejectReferral(ctx context.Context, tx *sql.Tx, referralID, orderNumber, reason string) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE referrals SET status='REJECTED', reason=$2, order_number=$3 WHERE id=$1`, referralID, reason, orderNumber)
+	return err
+}
+
+func (s *storage) creditReferral(ctx context.Context, tx *sql.Tx, userID, referralID, orderNumber, side string, amount float64) error {
+	if amount <= 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT into balances (sum, user_id) values ($1,$2) on conflict (user_id) DO UPDATE set sum = balances.sum + $1`, amount, userID)
+	if err != nil {
+		return err
+	}
+	err = s.addLot(ctx, tx, userID, models.LotReferral, referralID, amount)
+	if err != nil {
+		return err
+	}
+	return s.audit.Append(ctx, tx, models.AuditEvent{
+		Type:   models.AuditReferralReward,
+		UserID: userID,
+		Payload: map[string]string{
+			"referral_id": referralID,
+			"order":       orderNumber,
+			"side":        side,
+			"amount":      strconv.FormatFloat(amount, 'f', -1, 64),
+		},
+	})
+}
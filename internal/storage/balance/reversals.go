@@ -0,0 +1,95 @@
+package balance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"math"
+	"strconv"
+)
+
+// ReverseWithdrawal возвращает на баланс часть списания или, если reversal.Sum == 0, весь ещё не возвращённый остаток.
+// Сначала блокируется строка balances пользователя, потом само списание — тот же порядок, что при списании.
+func (s *storage) ReverseWithdrawal(ctx context.Context, reversal models.Reversal) (_ *models.Reversal, err error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.ReverseWithdrawal")
+	defer span.End()
+	defer func() { logTxError(ctx, "ReverseWithdrawal", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `SELECT user_id FROM withdrawals WHERE id=$1`, reversal.WithdrawalID).Scan(&reversal.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWithdrawalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `SELECT sum FROM balances WHERE user_id=$1 FOR UPDATE`, reversal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var number string
+	var sum, reversed float64
+	err = tx.QueryRowContext(ctx,
+		`SELECT number, sum, reversed FROM withdrawals WHERE id=$1 FOR UPDATE`, reversal.WithdrawalID).Scan(&number, &sum, &reversed)
+	if err != nil {
+		return nil, err
+	}
+	remaining := math.Round((sum-reversed)*100) / 100
+	if reversal.Sum == 0 {
+		reversal.Sum = remaining
+	}
+	if reversal.Sum <= 0 || reversal.Sum > remaining {
+		return nil, ErrReversalExceeded
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE withdrawals SET reversed = reversed + $1 WHERE id=$2`, reversal.Sum, reversal.WithdrawalID)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO withdrawal_reversals(withdrawal_id, user_id, sum, reason, created_by) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		reversal.WithdrawalID, reversal.UserID, reversal.Sum, reversal.Reason, reversal.CreatedBy).Scan(&reversal.ID, &reversal.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT into balances (sum, user_id) values ($1,$2) on conflict (user_id) DO UPDATE set sum = balances.sum + $1`, reversal.Sum, reversal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.addLot(ctx, tx, reversal.UserID, models.LotReversal, reversal.ID, reversal.Sum)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit.Append(ctx, tx, models.AuditEvent{
+		Type:    models.AuditWithdrawalReversed,
+		ActorID: reversal.CreatedBy,
+		UserID:  reversal.UserID,
+		Payload: map[string]string{
+			"withdrawal_id": reversal.WithdrawalID,
+			"reversal_id":   reversal.ID,
+			"order":         number,
+			"sum":           strconv.FormatFloat(reversal.Sum, 'f', -1, 64),
+			"reason":        reversal.Reason,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reversal, tx.Commit()
+}
@@ -0,0 +1,110 @@
+package balance
+
+import (
+	"context"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ledgerQuery — все изменения баланса пользователя ($1) с нарастающим остатком.
+// Зачисления берём из партий: там уже сумма с учётом множителя уровня, а остаток до появления партий
+// перенесён одной партией LEGACY. Поэтому списания раньше первой партии пользователя уже учтены в ней и пропускаются.
+const ledgerQuery = `WITH first_lot AS (
+	SELECT coalesce(min(accrued_at), 'infinity'::timestamptz) AS at FROM point_lots WHERE user_id=$1
+), entries AS (
+	SELECT 'L' || id AS id, CASE WHEN source = 'LEGACY' THEN 'OPENING' ELSE source::text END AS type,
+		source_id AS reference, amount, accrued_at AS created_at
+	FROM point_lots WHERE user_id=$1
+	UNION ALL
+	SELECT 'E' || id, 'EXPIRY', source_id, -expired, expired_at FROM point_lots WHERE user_id=$1 AND expired > 0
+	UNION ALL
+	SELECT 'W' || w.id, 'WITHDRAWAL', w.number, -w.sum, w.processed_at
+	FROM withdrawals w, first_lot f WHERE w.user_id=$1 AND w.processed_at >= f.at
+	UNION ALL
+	SELECT 'T' || id, 'TRANSFER', id::text, -sum, created_at FROM transfers WHERE from_user_id=$1
+	UNION ALL
+	SELECT 'A' || a.id, 'ADJUSTMENT', a.id::text, -a.amount, a.decided_at
+	FROM adjustments a, first_lot f WHERE a.user_id=$1 AND a.type='DEBIT' AND a.status='APPLIED' AND a.decided_at >= f.at
+), ledger AS (
+	SELECT id, type, reference, amount, created_at,
+		sum(amount) OVER (ORDER BY created_at, id ROWS UNBOUNDED PRECEDING) AS balance
+	FROM entries
+)`
+
+// GetStatement возвращает записи выписки по возрастанию времени. Остаток считается по всей истории,
+// фильтры применяются уже к результату. Limit == 0 — без ограничения.
+func (s *storage) GetStatement(ctx context.Context, userID string, filter models.StatementFilter) (*[]models.StatementEntry, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.GetStatement")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	args := []any{userID}
+	var conditions []string
+
+	if len(filter.Types) > 0 {
+		args = append(args, filter.Types)
+		conditions = append(conditions, "type = ANY($"+strconv.Itoa(len(args))+")")
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, "created_at>=$"+strconv.Itoa(len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, "created_at<$"+strconv.Itoa(len(args)))
+	}
+
+	query := ledgerQuery + ` SELECT id, type, reference, amount, balance, created_at FROM ledger`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at, id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += " LIMIT $" + strconv.Itoa(len(args))
+	}
+	args = append(args, filter.Offset)
+	query += " OFFSET $" + strconv.Itoa(len(args))
+
+	var entries []models.StatementEntry
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry models.StatementEntry
+		err = rows.Scan(&entry.ID, &entry.Type, &entry.Reference, &entry.Amount, &entry.Balance, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrNotFound
+	}
+	return &entries, nil
+}
+
+// GetBalanceAt — остаток по выписке на момент at, не включая записи в сам момент at
+func (s *storage) GetBalanceAt(ctx context.Context, userID string, at time.Time) (float64, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.GetBalanceAt")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var balance float64
+	err := s.db.QueryRowContext(ctx,
+		ledgerQuery+` SELECT coalesce(sum(amount), 0) FROM ledger WHERE created_at<$2`, userID, at).Scan(&balance)
+	return balance, err
+}
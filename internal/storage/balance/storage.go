@@ -3,21 +3,43 @@ package balance
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/webhooks"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
 	"time"
 )
 
-const timeOut = 500 * time.Millisecond
+const (
+	timeOut = 500 * time.Millisecond
+	// streamTimeOut — на выгрузку всей истории пользователя
+	streamTimeOut = 5 * time.Minute
+)
+
+// selectWithdrawalsByUser — списания пользователя для списка и для выгрузки
+const selectWithdrawalsByUser = `SELECT id, number, sum, reversed, processed_at FROM withdrawals where user_id=$1 order by processed_at`
 
 type storage struct {
-	db *sql.DB
+	db       *sql.DB
+	audit    audit.Storage
+	webhooks webhooks.Storage
+	policy   models.ExpiryPolicy
+	referral models.ReferralPolicy
 }
 
-func New(db *sql.DB) Storage {
-	return &storage{db: db}
+func New(db *sql.DB, audit audit.Storage, webhooks webhooks.Storage, policy models.ExpiryPolicy, referral models.ReferralPolicy) Storage {
+	return &storage{db: db, audit: audit, webhooks: webhooks, policy: policy, referral: referral}
 }
 
 func (s *storage) GetBalance(ctx context.Context, userID string) (float64, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.GetBalance")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 	var balance float64
@@ -35,6 +57,9 @@ func (s *storage) GetBalance(ctx context.Context, userID string) (float64, error
 }
 
 func (s *storage) SetBalance(ctx context.Context, sum float64, userID string) error {
+	ctx, span := tracing.Start(ctx, "balance.Storage.SetBalance")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
@@ -45,11 +70,14 @@ func (s *storage) SetBalance(ctx context.Context, sum float64, userID string) er
 }
 
 func (s *storage) GetSumWithdrawal(ctx context.Context, userID string) (float64, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.GetSumWithdrawal")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 	var withdraw float64
 
-	row := s.db.QueryRowContext(ctx, `SELECT SUM(sum) FROM withdrawals WHERE user_id=$1`, userID)
+	row := s.db.QueryRowContext(ctx, `SELECT SUM(sum - reversed) FROM withdrawals WHERE user_id=$1`, userID)
 	var nullWithdraw sql.NullFloat64
 
 	err := row.Scan(&nullWithdraw)
@@ -61,22 +89,240 @@ func (s *storage) GetSumWithdrawal(ctx context.Context, userID string) (float64,
 	return withdraw, nil
 }
 
-func (s *storage) AddWithdraw(ctx context.Context, withdraw models.Withdrawal, userID string) error {
+// AddWithdraw в одной транзакции записывает списание, уменьшает баланс и пишет событие аудита
+func (s *storage) AddWithdraw(ctx context.Context, withdraw models.Withdrawal, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.AddWithdraw")
+	defer span.End()
+	defer func() { logTxError(ctx, "AddWithdraw", err) }()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO withdrawals(number, sum, user_id) VALUES ($1, $2, $3)`, withdraw.OrderNumber, withdraw.Sum, userID)
-	return err
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = s.withdraw(ctx, tx, withdraw, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withdraw списывает баллы внутри транзакции и возвращает id записи в withdrawals.
+// Баллы под активными блокировками тратить нельзя, поэтому баланс не должен опуститься ниже их суммы.
+func (s *storage) withdraw(ctx context.Context, tx *sql.Tx, withdraw models.Withdrawal, userID string) (string, error) {
+	res, err := tx.ExecContext(ctx,
+		`UPDATE balances SET sum = sum - $1 WHERE user_id=$2 AND sum - $1 >= (`+heldByBalanceQuery+`)`, withdraw.Sum, userID)
+	if err != nil {
+		return "", err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+	if affected == 0 {
+		return "", ErrInsufficientFunds
+	}
+
+	err = s.consumeLots(ctx, tx, userID, withdraw.Sum)
+	if err != nil {
+		return "", err
+	}
+
+	var withdrawalID string
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO withdrawals(number, sum, user_id) VALUES ($1, $2, $3) RETURNING id`, withdraw.OrderNumber, withdraw.Sum, userID).Scan(&withdrawalID)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.audit.Append(ctx, tx, models.AuditEvent{
+		Type:    models.AuditWithdrawal,
+		ActorID: userID,
+		UserID:  userID,
+		Payload: map[string]string{
+			"order": withdraw.OrderNumber,
+			"sum":   strconv.FormatFloat(withdraw.Sum, 'f', -1, 64),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	event, err := webhooks.NewEvent(models.WebhookWithdrawalSucceeded, models.WithdrawalSucceededData{Order: withdraw.OrderNumber, Sum: withdraw.Sum})
+	if err != nil {
+		return "", err
+	}
+	err = s.webhooks.Enqueue(ctx, tx, userID, event)
+	if err != nil {
+		return "", err
+	}
+
+	return withdrawalID, nil
+}
+
+// Accrue применяет ответ accrual к заказу и, если он начислен, пополняет баланс — всё в одной транзакции.
+// Заказ в терминальном статусе не трогаем, поэтому повторный вызов с тем же результатом ничего не меняет.
+// Бонусы кампаний зачисляются той же транзакцией, каждый отдельной записью campaign_bonuses и своей партией.
+// Возвращает, что изменилось в балансах, и был ли заказ обновлён.
+func (s *storage) Accrue(ctx context.Context, order models.Order, bonuses []models.CampaignBonus) (_ models.AccrualResult, _ bool, err error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.Accrue")
+	defer span.End()
+	defer func() { logTxError(ctx, "Accrue", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.AccrualResult{}, false, err
+	}
+	defer tx.Rollback()
+
+	var userID string
+	var result models.AccrualResult
+	err = tx.QueryRowContext(ctx,
+		`UPDATE orders SET accrual=$1, status=$2 WHERE number=$3 AND status not in ('INVALID','PROCESSED') returning user_id`,
+		order.Accrual, order.Status, order.Number).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.AccrualResult{}, false, nil
+	}
+	if err != nil {
+		return models.AccrualResult{}, false, err
+	}
+
+	if order.Status == "PROCESSED" {
+		// до первого зачисления блокируем и пригласившего: награда за приглашение меняет оба баланса
+		parties := []string{userID}
+		var referrerID string
+		referrerID, err = s.pendingReferrer(ctx, tx, userID)
+		if err != nil {
+			return models.AccrualResult{}, false, err
+		}
+		if referrerID != "" {
+			parties = append(parties, referrerID)
+		}
+		err = lockBalances(ctx, tx, parties...)
+		if err != nil {
+			return models.AccrualResult{}, false, err
+		}
+	}
+
+	if order.Status == "PROCESSED" && order.Accrual > 0 {
+		// множитель уровня лояльности; у пользователя без пересчитанного уровня он 1
+		multiplier := 1.0
+		err = tx.QueryRowContext(ctx,
+			`SELECT t.multiplier FROM user_tiers ut JOIN tiers t ON t.name = ut.tier WHERE ut.user_id=$1`, userID).Scan(&multiplier)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return models.AccrualResult{}, false, err
+		}
+		credited := math.Round(order.Accrual*multiplier*100) / 100
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT into balances (sum, user_id) values ($1,$2) on conflict (user_id) DO UPDATE set sum = balances.sum + $1`, credited, userID)
+		if err != nil {
+			return models.AccrualResult{}, false, err
+		}
+
+		err = s.addLot(ctx, tx, userID, models.LotAccrual, order.Number, credited)
+		if err != nil {
+			return models.AccrualResult{}, false, err
+		}
+
+		err = s.audit.Append(ctx, tx, models.AuditEvent{
+			Type:   models.AuditAccrualCredited,
+			UserID: userID,
+			Payload: map[string]string{
+				"order":      order.Number,
+				"accrual":    strconv.FormatFloat(order.Accrual, 'f', -1, 64),
+				"multiplier": strconv.FormatFloat(multiplier, 'f', -1, 64),
+				"credited":   strconv.FormatFloat(credited, 'f', -1, 64),
+			},
+		})
+		if err != nil {
+			return models.AccrualResult{}, false, err
+		}
+		result.Credited = credited
+	}
+
+	if order.Status == "PROCESSED" {
+		for _, bonus := range bonuses {
+			err = s.creditBonus(ctx, tx, userID, order.Number, bonus)
+			if err != nil {
+				return models.AccrualResult{}, false, err
+			}
+		}
+
+		result.RefereeRewarded, result.ReferrerID, err = s.rewardReferral(ctx, tx, userID, order.Number)
+		if err != nil {
+			return models.AccrualResult{}, false, err
+		}
+	}
+
+	if order.Status == "PROCESSED" || order.Status == "INVALID" {
+		event, err := webhooks.NewEvent(models.WebhookOrderFinalized, models.OrderFinalizedData{Number: order.Number, Status: order.Status, Accrual: result.Credited})
+		if err != nil {
+			return models.AccrualResult{}, false, err
+		}
+		err = s.webhooks.Enqueue(ctx, tx, userID, event)
+		if err != nil {
+			return models.AccrualResult{}, false, err
+		}
+	}
+
+	return result, true, tx.Commit()
+}
+
+// creditBonus зачисляет бонус кампании. Уникальные индексы campaign_bonuses не дают начислить бонус дважды
+// за один заказ и бонус за первый заказ — дважды одному пользователю; такой бонус молча пропускаем.
+func (s *storage) creditBonus(ctx context.Context, tx *sql.Tx, userID, orderNumber string, bonus models.CampaignBonus) error {
+	var bonusID string
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO campaign_bonuses(campaign_id, kind, user_id, order_number, amount) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING RETURNING id`,
+		bonus.CampaignID, bonus.Kind, userID, orderNumber, bonus.Amount).Scan(&bonusID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT into balances (sum, user_id) values ($1,$2) on conflict (user_id) DO UPDATE set sum = balances.sum + $1`, bonus.Amount, userID)
+	if err != nil {
+		return err
+	}
+	err = s.addLot(ctx, tx, userID, models.LotCampaign, bonusID, bonus.Amount)
+	if err != nil {
+		return err
+	}
+	return s.audit.Append(ctx, tx, models.AuditEvent{
+		Type:   models.AuditCampaignBonus,
+		UserID: userID,
+		Payload: map[string]string{
+			"campaign_id": bonus.CampaignID,
+			"bonus_id":    bonusID,
+			"order":       orderNumber,
+			"amount":      strconv.FormatFloat(bonus.Amount, 'f', -1, 64),
+		},
+	})
 }
 
 func (s *storage) GetAllWithdrawByUser(ctx context.Context, userID string) (*[]models.Withdrawal, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.GetAllWithdrawByUser")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
 	var withdrawals []models.Withdrawal
 
-	rows, err := s.db.QueryContext(ctx, `SELECT number, sum, processed_at FROM withdrawals where user_id=$1 order by processed_at`, userID)
+	rows, err := s.db.QueryContext(ctx, selectWithdrawalsByUser, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -86,20 +332,10 @@ func (s *storage) GetAllWithdrawByUser(ctx context.Context, userID string) (*[]m
 	}
 	defer rows.Close()
 	for rows.Next() {
-
-		var number string
-		var sum sql.NullFloat64
-		var processedAt time.Time
-
-		err = rows.Scan(&number, &sum, &processedAt)
+		withdrawal, err := scanWithdrawal(rows)
 		if err != nil {
 			return nil, err
 		}
-		withdrawal := models.Withdrawal{
-			OrderNumber: number,
-			Sum:         sum.Float64,
-			ProcessedAt: processedAt,
-		}
 		withdrawals = append(withdrawals, withdrawal)
 	}
 	if len(withdrawals) == 0 {
@@ -107,3 +343,132 @@ func (s *storage) GetAllWithdrawByUser(ctx context.Context, userID string) (*[]m
 	}
 	return &withdrawals, err
 }
+
+// StreamWithdrawByUser отдаёт списания пользователя в fn по одному прямо из курсора.
+// Выборка та же, что у GetAllWithdrawByUser. Ошибка fn прерывает чтение и возвращается как есть.
+func (s *storage) StreamWithdrawByUser(ctx context.Context, userID string, fn func(models.Withdrawal) error) error {
+	ctx, span := tracing.Start(ctx, "balance.Storage.StreamWithdrawByUser")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, streamTimeOut)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, selectWithdrawalsByUser, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		withdrawal, err := scanWithdrawal(rows)
+		if err != nil {
+			return err
+		}
+		if err = fn(withdrawal); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanWithdrawal(rows *sql.Rows) (models.Withdrawal, error) {
+	var id, number string
+	var sum sql.NullFloat64
+	var reversed float64
+	var processedAt time.Time
+
+	err := rows.Scan(&id, &number, &sum, &reversed, &processedAt)
+	if err != nil {
+		return models.Withdrawal{}, err
+	}
+	return models.Withdrawal{
+		ID:          id,
+		OrderNumber: number,
+		Sum:         sum.Float64,
+		Reversed:    reversed,
+		ProcessedAt: processedAt,
+	}, nil
+}
+
+// ApplyAdjustment в одной транзакции переводит корректировку в APPLIED и меняет баланс.
+// Списание не может увести баланс ниже суммы активных блокировок.
+func (s *storage) ApplyAdjustment(ctx context.Context, adjustment models.Adjustment, deciderID string) (err error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.ApplyAdjustment")
+	defer span.End()
+	defer func() { logTxError(ctx, "ApplyAdjustment", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE adjustments SET status='APPLIED', decided_by=$1, decided_at=CURRENT_TIMESTAMP WHERE id=$2 AND status='PENDING'`, deciderID, adjustment.ID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrConflict
+	}
+
+	sum := adjustment.SignedAmount()
+	if sum >= 0 {
+		res, err = tx.ExecContext(ctx,
+			`INSERT into balances (sum, user_id) values ($1,$2) on conflict (user_id) DO UPDATE set sum = balances.sum + $1`, sum, adjustment.UserID)
+	} else {
+		res, err = tx.ExecContext(ctx,
+			`UPDATE balances SET sum = sum + $1 WHERE user_id=$2 AND sum + $1 >= (`+heldByBalanceQuery+`)`, sum, adjustment.UserID)
+	}
+	if err != nil {
+		return err
+	}
+	affected, err = res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInsufficientFunds
+	}
+
+	if sum >= 0 {
+		err = s.addLot(ctx, tx, adjustment.UserID, models.LotAdjustment, adjustment.ID, sum)
+	} else {
+		err = s.consumeLots(ctx, tx, adjustment.UserID, -sum)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.audit.Append(ctx, tx, models.AuditEvent{
+		Type:    models.AuditAdjustmentApplied,
+		ActorID: deciderID,
+		UserID:  adjustment.UserID,
+		Payload: map[string]string{
+			"adjustment_id": adjustment.ID,
+			"type":          adjustment.Type,
+			"amount":        strconv.FormatFloat(adjustment.Amount, 'f', -1, 64),
+			"reason":        adjustment.Reason,
+			"created_by":    adjustment.CreatedBy,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// logTxError пишет в лог запроса сбой транзакции; ожидаемые бизнес-ошибки сюда не попадают
+func logTxError(ctx context.Context, op string, err error) {
+	if err == nil || errors.Is(err, ErrInsufficientFunds) || errors.Is(err, ErrConflict) {
+		return
+	}
+	logger.FromContext(ctx).Error("Balance storage transaction failed", zap.String("op", op), zap.Error(err))
+}
@@ -0,0 +1,183 @@
+package balance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"sort"
+	"strconv"
+)
+
+// Transfer переводит баллы другому пользователю по логину. Строки balances обеих сторон блокируются
+// в порядке user_id (lockBalances): встречные переводы A→B и B→A ждут друг друга, а не взаимоблокируются.
+// Дневной лимит считается под той же блокировкой, поэтому параллельные переводы его не обойдут.
+func (s *storage) Transfer(ctx context.Context, fromUserID string, toLogin string, sum float64, dailyLimit float64) (_ *models.Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.Transfer")
+	defer span.End()
+	defer func() { logTxError(ctx, "Transfer", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	transfer := models.Transfer{FromUserID: fromUserID, ToLogin: toLogin, Sum: sum}
+	err = tx.QueryRowContext(ctx, `SELECT id FROM users WHERE login=$1`, toLogin).Scan(&transfer.ToUserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRecipientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ToUserID == fromUserID {
+		return nil, ErrSelfTransfer
+	}
+	err = tx.QueryRowContext(ctx, `SELECT login FROM users WHERE id=$1`, fromUserID).Scan(&transfer.FromLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	err = lockBalances(ctx, tx, fromUserID, transfer.ToUserID)
+	if err != nil {
+		return nil, err
+	}
+	var available sql.NullFloat64
+	err = tx.QueryRowContext(ctx, `SELECT sum FROM balances WHERE user_id=$1`, fromUserID).Scan(&available)
+	if err != nil {
+		return nil, err
+	}
+	var held float64
+	err = tx.QueryRowContext(ctx, heldSumQuery, fromUserID).Scan(&held)
+	if err != nil {
+		return nil, err
+	}
+	if available.Float64-held < sum {
+		return nil, ErrInsufficientFunds
+	}
+
+	if dailyLimit > 0 {
+		var sent float64
+		err = tx.QueryRowContext(ctx,
+			`SELECT coalesce(sum(sum), 0) FROM transfers WHERE from_user_id=$1 AND created_at >= date_trunc('day', CURRENT_TIMESTAMP)`,
+			fromUserID).Scan(&sent)
+		if err != nil {
+			return nil, err
+		}
+		if sent+sum > dailyLimit {
+			return nil, ErrTransferLimit
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE balances SET sum = sum - $1 WHERE user_id=$2`, sum, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.consumeLots(ctx, tx, fromUserID, sum)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE balances SET sum = sum + $1 WHERE user_id=$2`, sum, transfer.ToUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO transfers(from_user_id, to_user_id, sum) VALUES ($1, $2, $3) RETURNING id, created_at`,
+		fromUserID, transfer.ToUserID, sum).Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	err = s.addLot(ctx, tx, transfer.ToUserID, models.LotTransfer, transfer.ID, sum)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, side := range []struct{ userID, direction, counterparty string }{
+		{fromUserID, models.TransferOut, transfer.ToUserID},
+		{transfer.ToUserID, models.TransferIn, fromUserID},
+	} {
+		err = s.audit.Append(ctx, tx, models.AuditEvent{
+			Type:    models.AuditTransfer,
+			ActorID: fromUserID,
+			UserID:  side.userID,
+			Payload: map[string]string{
+				"transfer_id":  transfer.ID,
+				"direction":    side.direction,
+				"counterparty": side.counterparty,
+				"sum":          strconv.FormatFloat(sum, 'f', -1, 64),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &transfer, tx.Commit()
+}
+
+func (s *storage) GetTransfers(ctx context.Context, userID string) (*[]models.Transfer, error) {
+	ctx, span := tracing.Start(ctx, "balance.Storage.GetTransfers")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var transfers []models.Transfer
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT t.id, t.from_user_id, f.login, t.to_user_id, r.login, t.sum, t.created_at
+		FROM transfers t JOIN users f ON f.id = t.from_user_id JOIN users r ON r.id = t.to_user_id
+		WHERE t.from_user_id=$1 OR t.to_user_id=$1 ORDER BY t.created_at DESC, t.id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var transfer models.Transfer
+		err = rows.Scan(&transfer.ID, &transfer.FromUserID, &transfer.FromLogin, &transfer.ToUserID, &transfer.ToLogin, &transfer.Sum, &transfer.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		return nil, ErrNotFound
+	}
+	return &transfers, nil
+}
+
+// lockBalances блокирует строки balances пользователей в порядке user_id. Так делает каждая транзакция,
+// которая меняет баланс нескольких пользователей (перевод, начисление с наградой за приглашение),
+// поэтому они ждут друг друга, а не взаимоблокируются. Строки может ещё не быть, а заблокировать
+// можно только существующую — недостающие создаются с нулевым балансом.
+func lockBalances(ctx context.Context, tx *sql.Tx, userIDs ...string) error {
+	sorted := append([]string(nil), userIDs...)
+	// id — bigint без ведущих нулей: сравниваем сначала по длине, потом как строки
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) < len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	for _, userID := range sorted {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO balances (sum, user_id) VALUES (0, $1) ON CONFLICT (user_id) DO NOTHING`, userID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `SELECT sum FROM balances WHERE user_id=$1 FOR UPDATE`, userID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
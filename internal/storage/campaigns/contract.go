@@ -0,0 +1,22 @@
+package campaigns
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=campaigns
+
+var ErrNotFound = errors.New("not found")
+
+type Storage interface {
+	Add(ctx context.Context, campaign models.Campaign) (*models.Campaign, error)
+	Get(ctx context.Context, campaignID string) (*models.Campaign, error)
+	GetAll(ctx context.Context) (*[]models.Campaign, error)
+	Update(ctx context.Context, campaign models.Campaign) (*models.Campaign, error)
+	Delete(ctx context.Context, campaignID string) error
+	// GetActive — кампании, в окно которых попадает момент at; пустой список — не ошибка
+	GetActive(ctx context.Context, at time.Time) (*[]models.Campaign, error)
+}
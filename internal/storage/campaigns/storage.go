@@ -0,0 +1,162 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"time"
+)
+
+const timeOut = 500 * time.Millisecond
+
+// selectCampaign сразу считает расход: сумму и число начисленных бонусов
+const selectCampaign = `SELECT c.id, c.name, c.kind, c.value, c.starts_at, c.ends_at, c.created_by, c.created_at,
+	COALESCE((SELECT sum(amount) FROM campaign_bonuses b WHERE b.campaign_id = c.id), 0),
+	(SELECT count(*) FROM campaign_bonuses b WHERE b.campaign_id = c.id)
+	FROM campaigns c WHERE c.deleted_at IS NULL`
+
+type storage struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) Storage {
+	return &storage{db: db}
+}
+
+func (s *storage) Add(ctx context.Context, campaign models.Campaign) (*models.Campaign, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Storage.Add")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO campaigns(name, kind, value, starts_at, ends_at, created_by) VALUES ($1, $2, $3, $4, $5, $6) returning id, created_at`,
+		campaign.Name, campaign.Kind, campaign.Value, campaign.StartsAt, campaign.EndsAt, campaign.CreatedBy).
+		Scan(&campaign.ID, &campaign.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (s *storage) Get(ctx context.Context, campaignID string) (*models.Campaign, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Storage.Get")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	campaign, err := scan(s.db.QueryRowContext(ctx, selectCampaign+` AND c.id=$1`, campaignID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return campaign, err
+}
+
+func (s *storage) GetAll(ctx context.Context) (*[]models.Campaign, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Storage.GetAll")
+	defer span.End()
+
+	campaigns, err := s.getAll(ctx, selectCampaign+` ORDER BY c.starts_at DESC, c.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	if len(*campaigns) == 0 {
+		return nil, ErrNotFound
+	}
+	return campaigns, nil
+}
+
+// Update меняет правило кампании; уже начисленные бонусы остаются как были
+func (s *storage) Update(ctx context.Context, campaign models.Campaign) (*models.Campaign, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Storage.Update")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE campaigns SET name=$1, kind=$2, value=$3, starts_at=$4, ends_at=$5 WHERE id=$6 AND deleted_at IS NULL`,
+		campaign.Name, campaign.Kind, campaign.Value, campaign.StartsAt, campaign.EndsAt, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, ErrNotFound
+	}
+	return s.Get(ctx, campaign.ID)
+}
+
+// Delete — мягкое удаление: на кампанию ссылаются начисленные бонусы
+func (s *storage) Delete(ctx context.Context, campaignID string) error {
+	ctx, span := tracing.Start(ctx, "campaigns.Storage.Delete")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `UPDATE campaigns SET deleted_at=CURRENT_TIMESTAMP WHERE id=$1 AND deleted_at IS NULL`, campaignID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *storage) GetActive(ctx context.Context, at time.Time) (*[]models.Campaign, error) {
+	ctx, span := tracing.Start(ctx, "campaigns.Storage.GetActive")
+	defer span.End()
+
+	return s.getAll(ctx, selectCampaign+` AND c.starts_at <= $1 AND c.ends_at > $1 ORDER BY c.id`, at)
+}
+
+func (s *storage) getAll(ctx context.Context, query string, args ...any) (*[]models.Campaign, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaigns := []models.Campaign{}
+	for rows.Next() {
+		campaign, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, *campaign)
+	}
+	return &campaigns, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scan(row scanner) (*models.Campaign, error) {
+	var campaign models.Campaign
+	var createdBy sql.NullString
+
+	err := row.Scan(&campaign.ID, &campaign.Name, &campaign.Kind, &campaign.Value, &campaign.StartsAt, &campaign.EndsAt,
+		&createdBy, &campaign.CreatedAt, &campaign.Spent, &campaign.Bonuses)
+	if err != nil {
+		return nil, err
+	}
+	campaign.CreatedBy = createdBy.String
+	return &campaign, nil
+}
@@ -0,0 +1,21 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=idempotency
+
+var ErrNotFound = errors.New("not found")
+
+type Storage interface {
+	// Reserve занимает ключ. Если ключ уже занят и не протух — возвращает false.
+	Reserve(ctx context.Context, record models.IdempotencyRecord, ttl time.Duration) (bool, error)
+	Get(ctx context.Context, userID string, key string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record models.IdempotencyRecord) error
+	Delete(ctx context.Context, userID string, key string) error
+	DeleteExpired(ctx context.Context, ttl time.Duration) error
+}
@@ -0,0 +1,106 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"time"
+)
+
+const timeOut = 500 * time.Millisecond
+
+type storage struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) Storage {
+	return &storage{db: db}
+}
+
+func (s *storage) Reserve(ctx context.Context, record models.IdempotencyRecord, ttl time.Duration) (bool, error) {
+	ctx, span := tracing.Start(ctx, "idempotency.Storage.Reserve")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	// Протухшую запись перезаписываем, как будто ключа не было
+	var reserved bool
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO idempotency_keys(user_id, key, fingerprint) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, key) DO UPDATE SET fingerprint=EXCLUDED.fingerprint, status_code=NULL, content_type=NULL, body=NULL, created_at=CURRENT_TIMESTAMP
+		WHERE idempotency_keys.created_at < CURRENT_TIMESTAMP - make_interval(secs => $4)
+		returning true`,
+		record.UserID, record.Key, record.Fingerprint, ttl.Seconds()).Scan(&reserved)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return reserved, nil
+}
+
+func (s *storage) Get(ctx context.Context, userID string, key string) (*models.IdempotencyRecord, error) {
+	ctx, span := tracing.Start(ctx, "idempotency.Storage.Get")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	row := s.db.QueryRowContext(ctx,
+		`SELECT fingerprint, status_code, content_type, body, created_at FROM idempotency_keys WHERE user_id=$1 AND key=$2`, userID, key)
+
+	record := &models.IdempotencyRecord{UserID: userID, Key: key}
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+
+	err := row.Scan(&record.Fingerprint, &statusCode, &contentType, &record.Body, &record.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	record.StatusCode = int(statusCode.Int64)
+	record.ContentType = contentType.String
+	return record, nil
+}
+
+func (s *storage) Complete(ctx context.Context, record models.IdempotencyRecord) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Storage.Complete")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE idempotency_keys SET status_code=$1, content_type=$2, body=$3 WHERE user_id=$4 AND key=$5`,
+		record.StatusCode, record.ContentType, record.Body, record.UserID, record.Key)
+	return err
+}
+
+func (s *storage) Delete(ctx context.Context, userID string, key string) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Storage.Delete")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id=$1 AND key=$2`, userID, key)
+	return err
+}
+
+func (s *storage) DeleteExpired(ctx context.Context, ttl time.Duration) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Storage.DeleteExpired")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE created_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`, ttl.Seconds())
+	return err
+}
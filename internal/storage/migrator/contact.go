@@ -1,5 +1,11 @@
 package migrator
 
+import "context"
+
 type Migrator interface {
 	Run() error
+	// Version — применённая версия схемы и признак незавершённой миграции
+	Version(ctx context.Context) (version uint, dirty bool, err error)
+	// Latest — последняя версия среди встроенных в бинарь миграций
+	Latest() (uint, error)
 }
@@ -1,6 +1,7 @@
 package migrator
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -9,8 +10,12 @@ import (
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"io/fs"
+	"time"
 )
 
+const timeOut = 500 * time.Millisecond
+
 type migrator struct {
 	db *sql.DB
 }
@@ -46,3 +51,46 @@ func (m *migrator) Run() error {
 
 	return nil
 }
+
+func (m *migrator) Version(ctx context.Context) (uint, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	// таблицу ведёт golang-migrate, в ней всегда одна строка
+	row := m.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`)
+	var version int64
+	var dirty bool
+	err := row.Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+func (m *migrator) Latest() (uint, error) {
+	driver, err := iofs.New(db.MigrationsFS, "migrations")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = driver.Close()
+	}()
+
+	version, err := driver.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := driver.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
@@ -16,7 +16,10 @@ var (
 type Storage interface {
 	Add(ctx context.Context, orderID string, userID string) (*models.Order, error)
 	GetAllByUser(ctx context.Context, userID string) (*[]models.Order, error)
+	StreamByUser(ctx context.Context, userID string, fn func(models.Order) error) error
 	GetAllNotTerminated(ctx context.Context) (*[]models.Order, error)
 	Set(ctx context.Context, order models.Order) error
 	Get(ctx context.Context, orderID string) (*models.Order, error)
+	Repoll(ctx context.Context, orderID string) error
+	CountProcessed(ctx context.Context, userID string) (int, error)
 }
@@ -50,6 +50,21 @@ func (mr *MockStorageMockRecorder) Add(ctx, orderID, userID interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockStorage)(nil).Add), ctx, orderID, userID)
 }
 
+// CountProcessed mocks base method.
+func (m *MockStorage) CountProcessed(ctx context.Context, userID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountProcessed", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountProcessed indicates an expected call of CountProcessed.
+func (mr *MockStorageMockRecorder) CountProcessed(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountProcessed", reflect.TypeOf((*MockStorage)(nil).CountProcessed), ctx, userID)
+}
+
 // Get mocks base method.
 func (m *MockStorage) Get(ctx context.Context, orderID string) (*models.Order, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +110,20 @@ func (mr *MockStorageMockRecorder) GetAllNotTerminated(ctx interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllNotTerminated", reflect.TypeOf((*MockStorage)(nil).GetAllNotTerminated), ctx)
 }
 
+// Repoll mocks base method.
+func (m *MockStorage) Repoll(ctx context.Context, orderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Repoll", ctx, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Repoll indicates an expected call of Repoll.
+func (mr *MockStorageMockRecorder) Repoll(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Repoll", reflect.TypeOf((*MockStorage)(nil).Repoll), ctx, orderID)
+}
+
 // Set mocks base method.
 func (m *MockStorage) Set(ctx context.Context, order models.Order) error {
 	m.ctrl.T.Helper()
@@ -108,3 +137,17 @@ func (mr *MockStorageMockRecorder) Set(ctx, order interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockStorage)(nil).Set), ctx, order)
 }
+
+// StreamByUser mocks base method.
+func (m *MockStorage) StreamByUser(ctx context.Context, userID string, fn func(models.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamByUser", ctx, userID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamByUser indicates an expected call of StreamByUser.
+func (mr *MockStorageMockRecorder) StreamByUser(ctx, userID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamByUser", reflect.TypeOf((*MockStorage)(nil).StreamByUser), ctx, userID, fn)
+}
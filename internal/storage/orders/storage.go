@@ -5,12 +5,20 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
 	"time"
 )
 
-const timeOut = 500 * time.Millisecond
+const (
+	timeOut = 500 * time.Millisecond
+	// streamTimeOut — на выгрузку всей истории пользователя
+	streamTimeOut = 5 * time.Minute
+)
+
+// selectByUser — заказы пользователя для списка и для выгрузки
+const selectByUser = `SELECT number, status, accrual, user_id, uploaded_at FROM orders WHERE user_id=$1 order by uploaded_at`
 
 type storage struct {
 	db *sql.DB
@@ -21,6 +29,9 @@ func New(db *sql.DB) Storage {
 }
 
 func (s *storage) Add(ctx context.Context, orderID string, userID string) (*models.Order, error) {
+	ctx, span := tracing.Start(ctx, "orders.Storage.Add")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
@@ -42,12 +53,15 @@ func (s *storage) Add(ctx context.Context, orderID string, userID string) (*mode
 }
 
 func (s *storage) GetAllByUser(ctx context.Context, userID string) (*[]models.Order, error) {
+	ctx, span := tracing.Start(ctx, "orders.Storage.GetAllByUser")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
 	var orders []models.Order
 
-	rows, err := s.db.QueryContext(ctx, `SELECT number, status, accrual, user_id, uploaded_at FROM orders WHERE user_id=$1 order by uploaded_at`, userID)
+	rows, err := s.db.QueryContext(ctx, selectByUser, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -57,22 +71,10 @@ func (s *storage) GetAllByUser(ctx context.Context, userID string) (*[]models.Or
 	}
 	defer rows.Close()
 	for rows.Next() {
-
-		var number, status, userID string
-		var accrual sql.NullFloat64
-		var uploadedAt time.Time
-
-		err = rows.Scan(&number, &status, &accrual, &userID, &uploadedAt)
+		order, err := scanOrder(rows)
 		if err != nil {
 			return nil, err
 		}
-		order := models.Order{
-			Number:     number,
-			Status:     status,
-			Accrual:    accrual.Float64,
-			UserID:     userID,
-			UploadedAt: uploadedAt,
-		}
 		orders = append(orders, order)
 	}
 	if len(orders) == 0 {
@@ -81,7 +83,54 @@ func (s *storage) GetAllByUser(ctx context.Context, userID string) (*[]models.Or
 	return &orders, err
 }
 
+// StreamByUser отдаёт заказы пользователя в fn по одному прямо из курсора, не собирая их в память.
+// Выборка та же, что у GetAllByUser. Ошибка fn прерывает чтение и возвращается как есть.
+func (s *storage) StreamByUser(ctx context.Context, userID string, fn func(models.Order) error) error {
+	ctx, span := tracing.Start(ctx, "orders.Storage.StreamByUser")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, streamTimeOut)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, selectByUser, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return err
+		}
+		if err = fn(order); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanOrder(rows *sql.Rows) (models.Order, error) {
+	var number, status, userID string
+	var accrual sql.NullFloat64
+	var uploadedAt time.Time
+
+	err := rows.Scan(&number, &status, &accrual, &userID, &uploadedAt)
+	if err != nil {
+		return models.Order{}, err
+	}
+	return models.Order{
+		Number:     number,
+		Status:     status,
+		Accrual:    accrual.Float64,
+		UserID:     userID,
+		UploadedAt: uploadedAt,
+	}, nil
+}
+
 func (s *storage) GetAllNotTerminated(ctx context.Context) (*[]models.Order, error) {
+	ctx, span := tracing.Start(ctx, "orders.Storage.GetAllNotTerminated")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
@@ -119,6 +168,9 @@ func (s *storage) GetAllNotTerminated(ctx context.Context) (*[]models.Order, err
 }
 
 func (s *storage) Set(ctx context.Context, order models.Order) error {
+	ctx, span := tracing.Start(ctx, "orders.Storage.Set")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
@@ -128,6 +180,9 @@ func (s *storage) Set(ctx context.Context, order models.Order) error {
 }
 
 func (s *storage) Get(ctx context.Context, orderID string) (*models.Order, error) {
+	ctx, span := tracing.Start(ctx, "orders.Storage.Get")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
@@ -140,6 +195,9 @@ func (s *storage) Get(ctx context.Context, orderID string) (*models.Order, error
 	var uploadedAt time.Time
 
 	err := rows.Scan(&number, &status, &accrual, &userID, &uploadedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -153,3 +211,38 @@ func (s *storage) Get(ctx context.Context, orderID string) (*models.Order, error
 
 	return order, err
 }
+
+// Repoll возвращает заказ в очередь опроса accrual. Начисленные заказы не трогаем, иначе баллы зачислятся повторно
+func (s *storage) Repoll(ctx context.Context, orderID string) error {
+	ctx, span := tracing.Start(ctx, "orders.Storage.Repoll")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE orders SET status='NEW', accrual=NULL WHERE number=$1 AND status <> 'PROCESSED'`, orderID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *storage) CountProcessed(ctx context.Context, userID string) (int, error) {
+	ctx, span := tracing.Start(ctx, "orders.Storage.CountProcessed")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM orders WHERE user_id=$1 AND status='PROCESSED'`, userID).Scan(&count)
+	return count, err
+}
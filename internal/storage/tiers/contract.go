@@ -0,0 +1,23 @@
+package tiers
+
+import (
+	"context"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=tiers
+
+var ErrNotFound = errors.New("not found")
+
+type Storage interface {
+	// GetAll — уровни по возрастанию порога
+	GetAll(ctx context.Context) (*[]models.Tier, error)
+	// GetUserTier — уровень на момент последнего пересчёта, ErrNotFound если пересчёта ещё не было
+	GetUserTier(ctx context.Context, userID string) (string, error)
+	// GetSpend — сколько баллов accrual начислил пользователю за последние windowDays дней, без множителей
+	GetSpend(ctx context.Context, userID string, windowDays int) (float64, error)
+	// Recalculate пересчитывает уровни всех пользователей и возвращает изменения
+	Recalculate(ctx context.Context, windowDays int) (*[]models.TierChange, error)
+	GetHistory(ctx context.Context, userID string) (*[]models.TierChange, error)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package tiers is a generated GoMock package.
+package tiers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method.
+func (m *MockStorage) GetAll(ctx context.Context) (*[]models.Tier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].(*[]models.Tier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockStorageMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockStorage)(nil).GetAll), ctx)
+}
+
+// GetHistory mocks base method.
+func (m *MockStorage) GetHistory(ctx context.Context, userID string) (*[]models.TierChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, userID)
+	ret0, _ := ret[0].(*[]models.TierChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockStorageMockRecorder) GetHistory(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockStorage)(nil).GetHistory), ctx, userID)
+}
+
+// GetSpend mocks base method.
+func (m *MockStorage) GetSpend(ctx context.Context, userID string, windowDays int) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSpend", ctx, userID, windowDays)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpend indicates an expected call of GetSpend.
+func (mr *MockStorageMockRecorder) GetSpend(ctx, userID, windowDays interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpend", reflect.TypeOf((*MockStorage)(nil).GetSpend), ctx, userID, windowDays)
+}
+
+// GetUserTier mocks base method.
+func (m *MockStorage) GetUserTier(ctx context.Context, userID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTier", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTier indicates an expected call of GetUserTier.
+func (mr *MockStorageMockRecorder) GetUserTier(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTier", reflect.TypeOf((*MockStorage)(nil).GetUserTier), ctx, userID)
+}
+
+// Recalculate mocks base method.
+func (m *MockStorage) Recalculate(ctx context.Context, windowDays int) (*[]models.TierChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recalculate", ctx, windowDays)
+	ret0, _ := ret[0].(*[]models.TierChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recalculate indicates an expected call of Recalculate.
+func (mr *MockStorageMockRecorder) Recalculate(ctx, windowDays interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recalculate", reflect.TypeOf((*MockStorage)(nil).Recalculate), ctx, windowDays)
+}
@@ -0,0 +1,200 @@
+package tiers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	timeOut = 500 * time.Millisecond
+	// пересчёт идёт по всем пользователям разом, ему нужно больше времени
+	recalculateTimeOut = 5 * time.Minute
+)
+
+// recalculateLockKey — ключ advisory-блокировки: пересчёт ведёт один экземпляр, иначе история уровней задвоится
+const recalculateLockKey = 7290852
+
+// spendQuery — базовые начисления accrual (orders.accrual, без множителя уровня) по партиям, зачисленным в окне.
+// LEGACY-партия — остаток, перенесённый до появления партий; он набран начислениями, поэтому тоже считается
+// тратой, по сумме партии. Бонусы, корректировки и переводы в трату не входят.
+const spendQuery = `SELECT l.user_id, COALESCE(sum(CASE WHEN l.source = 'LEGACY' THEN l.amount ELSE o.accrual END), 0) AS spend
+	FROM point_lots l LEFT JOIN orders o ON l.source = 'ACCRUAL' AND o.number = l.source_id
+	WHERE l.source IN ('ACCRUAL', 'LEGACY') AND l.accrued_at >= CURRENT_TIMESTAMP - make_interval(days => $1)`
+
+type storage struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) Storage {
+	return &storage{db: db}
+}
+
+func (s *storage) GetAll(ctx context.Context) (*[]models.Tier, error) {
+	ctx, span := tracing.Start(ctx, "tiers.Storage.GetAll")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT name, threshold, multiplier FROM tiers ORDER BY threshold`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tiers []models.Tier
+	for rows.Next() {
+		var tier models.Tier
+		err = rows.Scan(&tier.Name, &tier.Threshold, &tier.Multiplier)
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, tier)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(tiers) == 0 {
+		return nil, ErrNotFound
+	}
+	return &tiers, nil
+}
+
+func (s *storage) GetUserTier(ctx context.Context, userID string) (string, error) {
+	ctx, span := tracing.Start(ctx, "tiers.Storage.GetUserTier")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var tier string
+	err := s.db.QueryRowContext(ctx, `SELECT tier FROM user_tiers WHERE user_id=$1`, userID).Scan(&tier)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return tier, err
+}
+
+func (s *storage) GetSpend(ctx context.Context, userID string, windowDays int) (float64, error) {
+	ctx, span := tracing.Start(ctx, "tiers.Storage.GetSpend")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var spend float64
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(sum(spend), 0) FROM (`+spendQuery+` AND l.user_id=$2 GROUP BY l.user_id) s`,
+		windowDays, userID).Scan(&spend)
+	return spend, err
+}
+
+// Recalculate одним запросом: пишет историю для тех, у кого уровень поменялся, и обновляет user_tiers.
+// Оба CTE видят user_tiers до обновления, поэтому from_tier — прежний уровень.
+// Пользователь без записи считается на нижнем уровне, чтобы первый пересчёт не засорял историю.
+// Если пересчёт сейчас ведёт другой экземпляр, ничего не делает и возвращает пустой список.
+func (s *storage) Recalculate(ctx context.Context, windowDays int) (_ *[]models.TierChange, err error) {
+	ctx, span := tracing.Start(ctx, "tiers.Storage.Recalculate")
+	defer span.End()
+	defer func() { logTxError(ctx, "Recalculate", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, recalculateTimeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	changes := []models.TierChange{}
+	var locked bool
+	err = tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, recalculateLockKey).Scan(&locked)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return &changes, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `WITH spend AS (`+spendQuery+` GROUP BY l.user_id),
+		base AS (SELECT name FROM tiers ORDER BY threshold LIMIT 1),
+		target AS (
+			SELECT u.id AS user_id, COALESCE(sp.spend, 0) AS spend,
+				(SELECT name FROM tiers WHERE threshold <= COALESCE(sp.spend, 0) ORDER BY threshold DESC LIMIT 1) AS tier,
+				COALESCE(ut.tier, (SELECT name FROM base)) AS current
+			FROM users u
+			LEFT JOIN spend sp ON sp.user_id = u.id
+			LEFT JOIN user_tiers ut ON ut.user_id = u.id),
+		history AS (
+			INSERT INTO tier_history(user_id, from_tier, to_tier, spend)
+			SELECT user_id, current, tier, spend FROM target WHERE tier <> current
+			RETURNING user_id, from_tier, to_tier, spend, changed_at),
+		upsert AS (
+			INSERT INTO user_tiers(user_id, tier, spend, updated_at)
+			SELECT user_id, tier, spend, CURRENT_TIMESTAMP FROM target
+			ON CONFLICT (user_id) DO UPDATE SET tier = EXCLUDED.tier, spend = EXCLUDED.spend, updated_at = EXCLUDED.updated_at)
+		SELECT user_id, from_tier, to_tier, spend, changed_at FROM history`, windowDays)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var change models.TierChange
+		err = rows.Scan(&change.UserID, &change.From, &change.To, &change.Spend, &change.ChangedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return &changes, tx.Commit()
+}
+
+func (s *storage) GetHistory(ctx context.Context, userID string) (*[]models.TierChange, error) {
+	ctx, span := tracing.Start(ctx, "tiers.Storage.GetHistory")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT user_id, from_tier, to_tier, spend, changed_at FROM tier_history WHERE user_id=$1 ORDER BY changed_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []models.TierChange
+	for rows.Next() {
+		var change models.TierChange
+		err = rows.Scan(&change.UserID, &change.From, &change.To, &change.Spend, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, ErrNotFound
+	}
+	return &changes, nil
+}
+
+// logTxError пишет в лог запроса сбой транзакции
+func logTxError(ctx context.Context, op string, err error) {
+	if err == nil {
+		return
+	}
+	logger.FromContext(ctx).Error("Tiers storage transaction failed", zap.String("op", op), zap.Error(err))
+}
@@ -4,15 +4,36 @@ import (
 	"context"
 	"errors"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
 )
 
 //go:generate mockgen -source=contract.go -destination=contract_mock.go -package=users
 
 var (
 	ErrConflict = errors.New("data conflict")
+	ErrNotFound = errors.New("not found")
+	// ErrUnknownReferral — при регистрации указан несуществующий код приглашения
+	ErrUnknownReferral = errors.New("unknown referral code")
+	// ErrDeletionNotScheduled — отменять нечего: удаление не запрошено или уже выполнено
+	ErrDeletionNotScheduled = errors.New("account deletion is not scheduled")
 )
 
 type Storage interface {
 	Register(ctx context.Context, userIn models.User) (*models.User, error)
 	Login(ctx context.Context, login string) (*models.User, error)
+	Get(ctx context.Context, userID string) (*models.User, error)
+	List(ctx context.Context, query string, limit int, offset int) (*[]models.User, error)
+	SetRole(ctx context.Context, userID string, role string) error
+	SetTOTPSecret(ctx context.Context, userID string, secret string) error
+	EnableTOTP(ctx context.Context, userID string, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, userID string) error
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error)
+	TakeSecondFactorAttempt(ctx context.Context, userID string, maxFailures int, lockout time.Duration) (bool, error)
+	ResetSecondFactorFailures(ctx context.Context, userID string) error
+	GetReferrals(ctx context.Context, referrerID string) (*[]models.Referral, error)
+	RequestDeletion(ctx context.Context, userID string, grace time.Duration) (*models.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, userID string) error
+	GetSessionsRevokedAt(ctx context.Context, userID string) (time.Time, error)
+	AnonymizeDue(ctx context.Context, limit int) ([]string, error)
 }
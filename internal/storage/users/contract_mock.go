@@ -7,6 +7,7 @@ package users
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,123 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// AnonymizeDue mocks base method.
+func (m *MockStorage) AnonymizeDue(ctx context.Context, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeDue", ctx, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeDue indicates an expected call of AnonymizeDue.
+func (mr *MockStorageMockRecorder) AnonymizeDue(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeDue", reflect.TypeOf((*MockStorage)(nil).AnonymizeDue), ctx, limit)
+}
+
+// CancelDeletion mocks base method.
+func (m *MockStorage) CancelDeletion(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockStorageMockRecorder) CancelDeletion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockStorage)(nil).CancelDeletion), ctx, userID)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockStorage) DisableTOTP(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockStorageMockRecorder) DisableTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockStorage)(nil).DisableTOTP), ctx, userID)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockStorage) EnableTOTP(ctx context.Context, userID string, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, userID, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockStorageMockRecorder) EnableTOTP(ctx, userID, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockStorage)(nil).EnableTOTP), ctx, userID, recoveryCodeHashes)
+}
+
+// Get mocks base method.
+func (m *MockStorage) Get(ctx context.Context, userID string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStorageMockRecorder) Get(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStorage)(nil).Get), ctx, userID)
+}
+
+// GetReferrals mocks base method.
+func (m *MockStorage) GetReferrals(ctx context.Context, referrerID string) (*[]models.Referral, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferrals", ctx, referrerID)
+	ret0, _ := ret[0].(*[]models.Referral)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferrals indicates an expected call of GetReferrals.
+func (mr *MockStorageMockRecorder) GetReferrals(ctx, referrerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferrals", reflect.TypeOf((*MockStorage)(nil).GetReferrals), ctx, referrerID)
+}
+
+// GetSessionsRevokedAt mocks base method.
+func (m *MockStorage) GetSessionsRevokedAt(ctx context.Context, userID string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsRevokedAt", ctx, userID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsRevokedAt indicates an expected call of GetSessionsRevokedAt.
+func (mr *MockStorageMockRecorder) GetSessionsRevokedAt(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsRevokedAt", reflect.TypeOf((*MockStorage)(nil).GetSessionsRevokedAt), ctx, userID)
+}
+
+// List mocks base method.
+func (m *MockStorage) List(ctx context.Context, query string, limit, offset int) (*[]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query, limit, offset)
+	ret0, _ := ret[0].(*[]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockStorageMockRecorder) List(ctx, query, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStorage)(nil).List), ctx, query, limit, offset)
+}
+
 // Login mocks base method.
 func (m *MockStorage) Login(ctx context.Context, login string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -64,3 +182,105 @@ func (mr *MockStorageMockRecorder) Register(ctx, userIn interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockStorage)(nil).Register), ctx, userIn)
 }
+
+// RequestDeletion mocks base method.
+func (m *MockStorage) RequestDeletion(ctx context.Context, userID string, grace time.Duration) (*models.AccountDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestDeletion", ctx, userID, grace)
+	ret0, _ := ret[0].(*models.AccountDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestDeletion indicates an expected call of RequestDeletion.
+func (mr *MockStorageMockRecorder) RequestDeletion(ctx, userID, grace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestDeletion", reflect.TypeOf((*MockStorage)(nil).RequestDeletion), ctx, userID, grace)
+}
+
+// ResetSecondFactorFailures mocks base method.
+func (m *MockStorage) ResetSecondFactorFailures(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetSecondFactorFailures", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetSecondFactorFailures indicates an expected call of ResetSecondFactorFailures.
+func (mr *MockStorageMockRecorder) ResetSecondFactorFailures(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetSecondFactorFailures", reflect.TypeOf((*MockStorage)(nil).ResetSecondFactorFailures), ctx, userID)
+}
+
+// SetRole mocks base method.
+func (m *MockStorage) SetRole(ctx context.Context, userID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *MockStorageMockRecorder) SetRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*MockStorage)(nil).SetRole), ctx, userID, role)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockStorage) SetTOTPSecret(ctx context.Context, userID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockStorageMockRecorder) SetTOTPSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockStorage)(nil).SetTOTPSecret), ctx, userID, secret)
+}
+
+// TakeSecondFactorAttempt mocks base method.
+func (m *MockStorage) TakeSecondFactorAttempt(ctx context.Context, userID string, maxFailures int, lockout time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeSecondFactorAttempt", ctx, userID, maxFailures, lockout)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeSecondFactorAttempt indicates an expected call of TakeSecondFactorAttempt.
+func (mr *MockStorageMockRecorder) TakeSecondFactorAttempt(ctx, userID, maxFailures, lockout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeSecondFactorAttempt", reflect.TypeOf((*MockStorage)(nil).TakeSecondFactorAttempt), ctx, userID, maxFailures, lockout)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStorage) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStorageMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStorage)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockStorage) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockStorageMockRecorder) UseTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockStorage)(nil).UseTOTPStep), ctx, userID, step)
+}
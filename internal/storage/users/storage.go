@@ -4,46 +4,508 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/logger"
 	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/storage/audit"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
 	"time"
 )
 
 const timeOut = 500 * time.Millisecond
 
 type storage struct {
-	db *sql.DB
+	db    *sql.DB
+	audit audit.Storage
 }
 
-func New(db *sql.DB) Storage {
-	return &storage{db: db}
+func New(db *sql.DB, audit audit.Storage) Storage {
+	return &storage{db: db, audit: audit}
 }
 
-func (s *storage) Register(ctx context.Context, userIn models.User) (*models.User, error) {
+func (s *storage) Register(ctx context.Context, userIn models.User) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.Register")
+	defer span.End()
+	defer func() { logTxError(ctx, "Register", err) }()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
-	var userID, login, password string
-	row := s.db.QueryRowContext(ctx, `INSERT INTO users(login,password) VALUES ($1, $2) returning id, login, password`, userIn.Login, userIn.Password).Scan(&userID, &login, &password)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var referrerID string
+	if userIn.InviteCode != "" {
+		err = tx.QueryRowContext(ctx, `SELECT id FROM users WHERE referral_code=$1`, userIn.InviteCode).Scan(&referrerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUnknownReferral
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var userID, login, password, role, referralCode string
+	err = tx.QueryRowContext(ctx, `INSERT INTO users(login,password,referral_code) VALUES ($1, $2, $3) returning id, login, password, role, referral_code`,
+		userIn.Login, userIn.Password, userIn.ReferralCode).Scan(&userID, &login, &password, &role, &referralCode)
 	var pgErr *pgconn.PgError
-	if errors.As(row, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
 		return nil, ErrConflict
 	}
-	user := &models.User{UserID: userID, Login: login, Password: password}
-	return user, nil
+	if err != nil {
+		return nil, err
+	}
+
+	if referrerID != "" {
+		_, err = tx.ExecContext(ctx, `INSERT INTO referrals(referrer_id, referee_id) VALUES ($1, $2)`, referrerID, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	user := &models.User{UserID: userID, Login: login, Password: password, Role: role, ReferralCode: referralCode}
+	return user, tx.Commit()
 }
 
 func (s *storage) Login(ctx context.Context, login string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.Login")
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
 	defer cancel()
 
-	row := s.db.QueryRowContext(ctx, `SELECT id, password FROM users WHERE login=$1`, login)
-	var id, password string
-	err := row.Scan(&id, &password)
+	row := s.db.QueryRowContext(ctx, `SELECT id, password, role, totp_enabled FROM users WHERE login=$1`, login)
+	var id, password, role string
+	var totpEnabled bool
+	err := row.Scan(&id, &password, &role, &totpEnabled)
 	if err != nil {
 		return nil, err
 	}
-	user := &models.User{UserID: id, Login: login, Password: password}
+	user := &models.User{UserID: id, Login: login, Password: password, Role: role, TOTPEnabled: totpEnabled}
 	return user, nil
 }
+
+func (s *storage) Get(ctx context.Context, userID string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.Get")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, login, role, totp_secret, totp_enabled, referral_code, deletion_requested_at, deletion_scheduled_at FROM users WHERE id=$1`, userID)
+	var id, login, role, referralCode string
+	var totpSecret sql.NullString
+	var totpEnabled bool
+	var deletionRequestedAt, deletionScheduledAt sql.NullTime
+	err := row.Scan(&id, &login, &role, &totpSecret, &totpEnabled, &referralCode, &deletionRequestedAt, &deletionScheduledAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{UserID: id, Login: login, Role: role, TOTPSecret: totpSecret.String, TOTPEnabled: totpEnabled, ReferralCode: referralCode}
+	if deletionScheduledAt.Valid {
+		user.Deletion = &models.AccountDeletion{RequestedAt: deletionRequestedAt.Time, ScheduledAt: deletionScheduledAt.Time}
+	}
+	return user, nil
+}
+
+func (s *storage) List(ctx context.Context, query string, limit int, offset int) (*[]models.User, error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.List")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var users []models.User
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, login, role, totp_enabled FROM users WHERE login ILIKE '%' || $1 || '%' order by id LIMIT $2 OFFSET $3`, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+
+		var id, login, role string
+		var totpEnabled bool
+
+		err = rows.Scan(&id, &login, &role, &totpEnabled)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, models.User{UserID: id, Login: login, Role: role, TOTPEnabled: totpEnabled})
+	}
+	if len(users) == 0 {
+		return nil, ErrNotFound
+	}
+	return &users, err
+}
+
+// SetRole меняет роль и при смене отзывает сессии: роль зашита в JWT, и без отзыва
+// разжалованный админ сохранил бы доступ до истечения токена
+func (s *storage) SetRole(ctx context.Context, userID string, role string) error {
+	ctx, span := tracing.Start(ctx, "users.Storage.SetRole")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE users SET role=$1,
+			sessions_revoked_at = CASE WHEN role <> $1 THEN CURRENT_TIMESTAMP ELSE sessions_revoked_at END
+		WHERE id=$2`, role, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *storage) SetTOTPSecret(ctx context.Context, userID string, secret string) error {
+	ctx, span := tracing.Start(ctx, "users.Storage.SetTOTPSecret")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE users SET totp_secret=$1, totp_last_step=NULL WHERE id=$2 AND totp_enabled=false`, secret, userID)
+	return err
+}
+
+func (s *storage) EnableTOTP(ctx context.Context, userID string, recoveryCodeHashes []string) (err error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.EnableTOTP")
+	defer span.End()
+	defer func() { logTxError(ctx, "EnableTOTP", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET totp_enabled=true WHERE id=$1`, userID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id=$1`, userID)
+	if err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		_, err = tx.ExecContext(ctx, `INSERT INTO recovery_codes(user_id, code_hash) VALUES ($1, $2)`, userID, hash)
+		if err != nil {
+			return err
+		}
+	}
+	err = s.audit.Append(ctx, tx, models.AuditEvent{Type: models.AuditTOTPEnabled, ActorID: userID, UserID: userID})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *storage) DisableTOTP(ctx context.Context, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.DisableTOTP")
+	defer span.End()
+	defer func() { logTxError(ctx, "DisableTOTP", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE users SET totp_enabled=false, totp_secret=NULL, totp_last_step=NULL WHERE id=$1`, userID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id=$1`, userID)
+	if err != nil {
+		return err
+	}
+	err = s.audit.Append(ctx, tx, models.AuditEvent{Type: models.AuditTOTPDisabled, ActorID: userID, UserID: userID})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UseTOTPStep запоминает использованный шаг, чтобы один и тот же код нельзя было предъявить повторно
+func (s *storage) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.UseTOTPStep")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE users SET totp_last_step=$1 WHERE id=$2 AND (totp_last_step IS NULL OR totp_last_step < $1)`, step, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// TakeSecondFactorAttempt засчитывает попытку ввода кода ещё до его проверки. Проверка блокировки и счётчик —
+// один UPDATE, поэтому параллельные попытки не проскочат её разом: заблокированному попытка не достаётся (false),
+// а maxFailures-я подряд попытка ставит блокировку на lockout. Верный код сбрасывает счётчик через ResetSecondFactorFailures.
+func (s *storage) TakeSecondFactorAttempt(ctx context.Context, userID string, maxFailures int, lockout time.Duration) (bool, error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.TakeSecondFactorAttempt")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE users SET
+			second_factor_failures = CASE WHEN second_factor_failures + 1 >= $2 THEN 0 ELSE second_factor_failures + 1 END,
+			second_factor_locked_until = CASE WHEN second_factor_failures + 1 >= $2
+				THEN CURRENT_TIMESTAMP + make_interval(secs => $3) ELSE NULL END
+		WHERE id=$1 AND (second_factor_locked_until IS NULL OR second_factor_locked_until <= CURRENT_TIMESTAMP)`,
+		userID, maxFailures, lockout.Seconds())
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// ResetSecondFactorFailures сбрасывает счётчик и блокировку после верного кода
+func (s *storage) ResetSecondFactorFailures(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "users.Storage.ResetSecondFactorFailures")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE users SET second_factor_failures=0, second_factor_locked_until=NULL
+		WHERE id=$1 AND (second_factor_failures > 0 OR second_factor_locked_until IS NOT NULL)`, userID)
+	return err
+}
+
+func (s *storage) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.UseRecoveryCode")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE recovery_codes SET used_at=CURRENT_TIMESTAMP WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// RequestDeletion назначает удаление аккаунта через grace. Повторный запрос не сдвигает срок.
+func (s *storage) RequestDeletion(ctx context.Context, userID string, grace time.Duration) (*models.AccountDeletion, error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.RequestDeletion")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var deletion models.AccountDeletion
+	err := s.db.QueryRowContext(ctx,
+		`UPDATE users SET deletion_requested_at = coalesce(deletion_requested_at, CURRENT_TIMESTAMP),
+			deletion_scheduled_at = coalesce(deletion_scheduled_at, CURRENT_TIMESTAMP + make_interval(secs => $2))
+		WHERE id=$1 AND deleted_at IS NULL RETURNING deletion_requested_at, deletion_scheduled_at`,
+		userID, grace.Seconds()).Scan(&deletion.RequestedAt, &deletion.ScheduledAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+func (s *storage) CancelDeletion(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "users.Storage.CancelDeletion")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE users SET deletion_requested_at = NULL, deletion_scheduled_at = NULL
+		WHERE id=$1 AND deletion_scheduled_at IS NOT NULL AND deleted_at IS NULL`, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDeletionNotScheduled
+	}
+	return nil
+}
+
+// GetSessionsRevokedAt — с какого момента токены пользователя недействительны; нулевое время — не отзывались
+func (s *storage) GetSessionsRevokedAt(ctx context.Context, userID string) (time.Time, error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.GetSessionsRevokedAt")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var revokedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `SELECT sessions_revoked_at FROM users WHERE id=$1`, userID).Scan(&revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, ErrNotFound
+	}
+	return revokedAt.Time, err
+}
+
+// AnonymizeDue обезличивает аккаунты, у которых истёк срок на отмену удаления, и возвращает их id.
+// Строка users остаётся: на её id ссылаются заказы, списания, партии и прочие финансовые записи,
+// без логина это уже псевдоним. Журнал аудита не трогаем — он сцеплен хешами, поэтому персональных данных
+// в нём нет изначально: только id, а логины — ключевым хешем (см. users.Service.Login).
+func (s *storage) AnonymizeDue(ctx context.Context, limit int) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.AnonymizeDue")
+	defer span.End()
+	defer func() { logTxError(ctx, "AnonymizeDue", err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id FROM users WHERE deletion_scheduled_at <= CURRENT_TIMESTAMP AND deleted_at IS NULL
+		ORDER BY deletion_scheduled_at LIMIT $1 FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err = rows.Scan(&userID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	for _, userID := range userIDs {
+		for _, query := range []string{
+			`DELETE FROM recovery_codes WHERE user_id=$1`,
+			`DELETE FROM webhooks WHERE user_id=$1`,
+			`DELETE FROM idempotency_keys WHERE user_id=$1`,
+			// код приглашения при регистрации приводится к верхнему регистру, поэтому "deleted-…" никто не введёт
+			`UPDATE users SET login = 'deleted-' || md5(random()::text || id), referral_code = 'deleted-' || md5(random()::text || id),
+				password = '', totp_secret = NULL, totp_enabled = false, totp_last_step = NULL,
+				deleted_at = CURRENT_TIMESTAMP, sessions_revoked_at = CURRENT_TIMESTAMP
+			WHERE id=$1`,
+		} {
+			_, err = tx.ExecContext(ctx, query, userID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = s.audit.Append(ctx, tx, models.AuditEvent{Type: models.AuditAccountAnonymized, UserID: userID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return userIDs, tx.Commit()
+}
+
+func (s *storage) GetReferrals(ctx context.Context, referrerID string) (*[]models.Referral, error) {
+	ctx, span := tracing.Start(ctx, "users.Storage.GetReferrals")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var referrals []models.Referral
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT u.login, r.status, coalesce(r.reason, ''), r.referrer_reward, r.created_at, r.rewarded_at
+		FROM referrals r JOIN users u ON u.id = r.referee_id WHERE r.referrer_id=$1 ORDER BY r.created_at DESC, r.id DESC`, referrerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var referral models.Referral
+		var rewardedAt sql.NullTime
+		err = rows.Scan(&referral.RefereeLogin, &referral.Status, &referral.Reason, &referral.ReferrerReward, &referral.CreatedAt, &rewardedAt)
+		if err != nil {
+			return nil, err
+		}
+		if rewardedAt.Valid {
+			referral.RewardedAt = &rewardedAt.Time
+		}
+		referrals = append(referrals, referral)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(referrals) == 0 {
+		return nil, ErrNotFound
+	}
+	return &referrals, nil
+}
+
+// logTxError пишет в лог запроса сбой транзакции; ожидаемые бизнес-ошибки сюда не попадают
+func logTxError(ctx context.Context, op string, err error) {
+	if err == nil {
+		return
+	}
+	logger.FromContext(ctx).Error("Users storage transaction failed", zap.String("op", op), zap.Error(err))
+}
@@ -0,0 +1,30 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=contract.go -destination=contract_mock.go -package=webhooks
+
+var ErrNotFound = errors.New("not found")
+
+type Storage interface {
+	Add(ctx context.Context, webhook models.Webhook) (*models.Webhook, error)
+	GetAllByUser(ctx context.Context, userID string) (*[]models.Webhook, error)
+	Delete(ctx context.Context, userID string, webhookID string) error
+	// Enqueue кладёт событие в outbox для всех подписанных на него вебхуков пользователя.
+	// Пишет в транзакции вызывающего, чтобы событие не потерялось и не ушло без бизнес-изменения.
+	Enqueue(ctx context.Context, tx *sql.Tx, userID string, event models.WebhookEvent) error
+	GetDeliveries(ctx context.Context, userID string, webhookID string, limit int, offset int) (*[]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, userID string, webhookID string, deliveryID string) error
+	// ClaimDue забирает в работу доставки, которым пора уходить, и откладывает их на lease,
+	// чтобы параллельный диспетчер не отправил то же самое
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) (*[]models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, deliveryID string, statusCode int) error
+	// MarkFailed записывает неудачную попытку. nextAttemptAt == nil — попытки кончились.
+	MarkFailed(ctx context.Context, deliveryID string, statusCode int, lastError string, nextAttemptAt *time.Time) error
+}
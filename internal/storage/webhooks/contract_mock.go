@@ -0,0 +1,168 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package webhooks is a generated GoMock package.
+package webhooks
+
+import (
+	context "context"
+	sql "database/sql"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockStorage) Add(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, webhook)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockStorageMockRecorder) Add(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockStorage)(nil).Add), ctx, webhook)
+}
+
+// ClaimDue mocks base method.
+func (m *MockStorage) ClaimDue(ctx context.Context, limit int, lease time.Duration) (*[]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDue", ctx, limit, lease)
+	ret0, _ := ret[0].(*[]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDue indicates an expected call of ClaimDue.
+func (mr *MockStorageMockRecorder) ClaimDue(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDue", reflect.TypeOf((*MockStorage)(nil).ClaimDue), ctx, limit, lease)
+}
+
+// Delete mocks base method.
+func (m *MockStorage) Delete(ctx context.Context, userID, webhookID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockStorageMockRecorder) Delete(ctx, userID, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStorage)(nil).Delete), ctx, userID, webhookID)
+}
+
+// Enqueue mocks base method.
+func (m *MockStorage) Enqueue(ctx context.Context, tx *sql.Tx, userID string, event models.WebhookEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, tx, userID, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockStorageMockRecorder) Enqueue(ctx, tx, userID, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockStorage)(nil).Enqueue), ctx, tx, userID, event)
+}
+
+// GetAllByUser mocks base method.
+func (m *MockStorage) GetAllByUser(ctx context.Context, userID string) (*[]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
+	ret0, _ := ret[0].(*[]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUser indicates an expected call of GetAllByUser.
+func (mr *MockStorageMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockStorage)(nil).GetAllByUser), ctx, userID)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockStorage) GetDeliveries(ctx context.Context, userID, webhookID string, limit, offset int) (*[]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", ctx, userID, webhookID, limit, offset)
+	ret0, _ := ret[0].(*[]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockStorageMockRecorder) GetDeliveries(ctx, userID, webhookID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockStorage)(nil).GetDeliveries), ctx, userID, webhookID, limit, offset)
+}
+
+// MarkDelivered mocks base method.
+func (m *MockStorage) MarkDelivered(ctx context.Context, deliveryID string, statusCode int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDelivered", ctx, deliveryID, statusCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDelivered indicates an expected call of MarkDelivered.
+func (mr *MockStorageMockRecorder) MarkDelivered(ctx, deliveryID, statusCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDelivered", reflect.TypeOf((*MockStorage)(nil).MarkDelivered), ctx, deliveryID, statusCode)
+}
+
+// MarkFailed mocks base method.
+func (m *MockStorage) MarkFailed(ctx context.Context, deliveryID string, statusCode int, lastError string, nextAttemptAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, deliveryID, statusCode, lastError, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockStorageMockRecorder) MarkFailed(ctx, deliveryID, statusCode, lastError, nextAttemptAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockStorage)(nil).MarkFailed), ctx, deliveryID, statusCode, lastError, nextAttemptAt)
+}
+
+// Redeliver mocks base method.
+func (m *MockStorage) Redeliver(ctx context.Context, userID, webhookID, deliveryID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, userID, webhookID, deliveryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockStorageMockRecorder) Redeliver(ctx, userID, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockStorage)(nil).Redeliver), ctx, userID, webhookID, deliveryID)
+}
@@ -0,0 +1,291 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/models"
+	"github.com/egor-zakharov/go-musthave-diploma-tpl/internal/tracing"
+	"strings"
+	"time"
+)
+
+const timeOut = 500 * time.Millisecond
+
+// список событий храним строкой через запятую: так его проще сравнивать в SQL и сканировать через database/sql
+const eventsSeparator = ","
+
+const selectDelivery = `SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+	d.last_status_code, d.last_error, d.created_at, d.delivered_at FROM webhook_deliveries d`
+
+// NewEvent собирает событие для Enqueue; id случайный, общий для всех доставок события
+func NewEvent(eventType string, data any) (models.WebhookEvent, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return models.WebhookEvent{}, err
+	}
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return models.WebhookEvent{}, err
+	}
+	return models.WebhookEvent{ID: hex.EncodeToString(id), Type: eventType, CreatedAt: time.Now().UTC(), Data: raw}, nil
+}
+
+type storage struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) Storage {
+	return &storage{db: db}
+}
+
+func (s *storage) Add(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Storage.Add")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO webhooks(user_id, url, secret, events) VALUES ($1, $2, $3, $4) returning id, created_at`,
+		webhook.UserID, webhook.URL, webhook.Secret, strings.Join(webhook.Events, eventsSeparator)).
+		Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (s *storage) GetAllByUser(ctx context.Context, userID string) (*[]models.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Storage.GetAllByUser")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var webhooks []models.Webhook
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, url, secret, events, created_at FROM webhooks WHERE user_id=$1 order by id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		webhook := models.Webhook{UserID: userID}
+		var events string
+		err = rows.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Events = strings.Split(events, eventsSeparator)
+		webhooks = append(webhooks, webhook)
+	}
+	if len(webhooks) == 0 {
+		return nil, ErrNotFound
+	}
+	return &webhooks, err
+}
+
+func (s *storage) Delete(ctx context.Context, userID string, webhookID string) error {
+	ctx, span := tracing.Start(ctx, "webhooks.Storage.Delete")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id=$1 AND user_id=$2`, webhookID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *storage) Enqueue(ctx context.Context, tx *sql.Tx, userID string, event models.WebhookEvent) error {
+	ctx, span := tracing.Start(ctx, "webhooks.Storage.Enqueue")
+	defer span.End()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries(webhook_id, event_id, event_type, payload)
+		SELECT id, $2, $3, $4 FROM webhooks WHERE user_id=$1 AND $3 = ANY(string_to_array(events, ','))`,
+		userID, event.ID, event.Type, payload)
+	return err
+}
+
+func (s *storage) GetDeliveries(ctx context.Context, userID string, webhookID string, limit int, offset int) (*[]models.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Storage.GetDeliveries")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	// сначала убеждаемся, что вебхук принадлежит пользователю: пустой лог и чужой вебхук — разные ответы
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT true FROM webhooks WHERE id=$1 AND user_id=$2`, webhookID, userID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+
+	rows, err := s.db.QueryContext(ctx, selectDelivery+` WHERE d.webhook_id=$1 order by d.id desc limit $2 offset $3`, webhookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return &deliveries, err
+}
+
+func (s *storage) Redeliver(ctx context.Context, userID string, webhookID string, deliveryID string) error {
+	ctx, span := tracing.Start(ctx, "webhooks.Storage.Redeliver")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	// счётчик попыток обнуляем, чтобы повторная доставка получила полный набор ретраев
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries d SET status='PENDING', attempts=0, next_attempt_at=CURRENT_TIMESTAMP
+		FROM webhooks w WHERE d.webhook_id=w.id AND d.id=$1 AND w.id=$2 AND w.user_id=$3`,
+		deliveryID, webhookID, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *storage) ClaimDue(ctx context.Context, limit int, lease time.Duration) (*[]models.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "webhooks.Storage.ClaimDue")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var deliveries []models.WebhookDelivery
+
+	rows, err := s.db.QueryContext(ctx,
+		`WITH due AS (
+			SELECT id FROM webhook_deliveries WHERE status='PENDING' AND next_attempt_at <= CURRENT_TIMESTAMP
+			order by next_attempt_at limit $1 FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM due, webhooks w WHERE d.id = due.id AND w.id = d.webhook_id
+		returning d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.attempts, w.url, w.secret`,
+		limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		delivery := models.WebhookDelivery{Status: models.DeliveryPending}
+		err = rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Payload,
+			&delivery.Attempts, &delivery.URL, &delivery.Secret)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return &deliveries, err
+}
+
+func (s *storage) MarkDelivered(ctx context.Context, deliveryID string, statusCode int) error {
+	ctx, span := tracing.Start(ctx, "webhooks.Storage.MarkDelivered")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET status='DELIVERED', attempts=attempts+1, last_status_code=$1, last_error=NULL, delivered_at=CURRENT_TIMESTAMP WHERE id=$2`,
+		statusCode, deliveryID)
+	return err
+}
+
+func (s *storage) MarkFailed(ctx context.Context, deliveryID string, statusCode int, lastError string, nextAttemptAt *time.Time) error {
+	ctx, span := tracing.Start(ctx, "webhooks.Storage.MarkFailed")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	defer cancel()
+
+	var code sql.NullInt64
+	if statusCode != 0 {
+		code = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+
+	var err error
+	if nextAttemptAt == nil {
+		_, err = s.db.ExecContext(ctx,
+			`UPDATE webhook_deliveries SET status='FAILED', attempts=attempts+1, last_status_code=$1, last_error=$2 WHERE id=$3`,
+			code, lastError, deliveryID)
+	} else {
+		_, err = s.db.ExecContext(ctx,
+			`UPDATE webhook_deliveries SET attempts=attempts+1, last_status_code=$1, last_error=$2, next_attempt_at=$3 WHERE id=$4`,
+			code, lastError, *nextAttemptAt, deliveryID)
+	}
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDelivery(row scanner) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var lastStatusCode sql.NullInt64
+	var lastError sql.NullString
+	var deliveredAt sql.NullTime
+
+	err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &lastStatusCode, &lastError, &delivery.CreatedAt, &deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	delivery.LastStatusCode = int(lastStatusCode.Int64)
+	delivery.LastError = lastError.String
+	delivery.DeliveredAt = deliveredAt.Time
+	return &delivery, nil
+}
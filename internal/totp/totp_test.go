@@ -0,0 +1,61 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+func TestCode(t *testing.T) {
+	// Тестовые векторы RFC 6238 (SHA1), последние 6 цифр
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		name string
+		unix int64
+		want string
+	}{
+		{name: "59", unix: 59, want: "287082"},
+		{name: "1111111109", unix: 1111111109, want: "081804"},
+		{name: "1111111111", unix: 1111111111, want: "050471"},
+		{name: "1234567890", unix: 1234567890, want: "005924"},
+		{name: "2000000000", unix: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Code(secret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	now := time.Now()
+	prev, _ := Code(secret, Step(now)-1)
+	old, _ := Code(secret, Step(now)-3)
+
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{name: "previous step", code: prev, want: true},
+		{name: "too old", code: old, want: false},
+		{name: "wrong length", code: "123", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := Validate(secret, tt.code, now); got != tt.want {
+				t.Errorf("Validate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func (c *Client) AdminListUsers(ctx context.Context, query UserQuery) ([]AdminUser, error) {
+	values := url.Values{}
+	if query.Q != "" {
+		values.Set("q", query.Q)
+	}
+	setPage(values, query.Limit, query.Offset)
+
+	var users []AdminUser
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/users", query: values}, &users)
+	return users, err
+}
+
+func (c *Client) AdminGetUser(ctx context.Context, userID string) (*AdminUser, error) {
+	user := &AdminUser{}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/users/" + url.PathEscape(userID)}, user)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (c *Client) AdminGetUserOrders(ctx context.Context, userID string) ([]Order, error) {
+	var orders []Order
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/users/" + url.PathEscape(userID) + "/orders"}, &orders)
+	return orders, err
+}
+
+func (c *Client) AdminGetUserWithdrawals(ctx context.Context, userID string) ([]Withdrawal, error) {
+	var withdrawals []Withdrawal
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/users/" + url.PathEscape(userID) + "/withdrawals"}, &withdrawals)
+	return withdrawals, err
+}
+
+func (c *Client) AdminGetUserBalance(ctx context.Context, userID string) (*Balance, error) {
+	balance := &Balance{}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/users/" + url.PathEscape(userID) + "/balance"}, balance)
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+func (c *Client) AdminSetRole(ctx context.Context, userID string, role string) error {
+	req, err := jsonRequest(http.MethodPut, "/api/admin/users/"+url.PathEscape(userID)+"/role", struct {
+		Role string `json:"role"`
+	}{Role: role})
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, req, nil)
+	return err
+}
+
+// AdminCreateAdjustment создаёт корректировку. Крупные корректировки остаются в статусе PENDING до одобрения.
+func (c *Client) AdminCreateAdjustment(ctx context.Context, userID string, adjustment CreateAdjustmentRequest) (*Adjustment, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/admin/users/"+url.PathEscape(userID)+"/adjustments", adjustment)
+	if err != nil {
+		return nil, err
+	}
+	created := &Adjustment{}
+	_, err = c.do(ctx, req, created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (c *Client) AdminListAdjustments(ctx context.Context, status string) ([]Adjustment, error) {
+	values := url.Values{}
+	if status != "" {
+		values.Set("status", status)
+	}
+	var adjustments []Adjustment
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/adjustments", query: values}, &adjustments)
+	return adjustments, err
+}
+
+func (c *Client) AdminApproveAdjustment(ctx context.Context, adjustmentID string) (*Adjustment, error) {
+	return c.decideAdjustment(ctx, adjustmentID, "approve")
+}
+
+func (c *Client) AdminRejectAdjustment(ctx context.Context, adjustmentID string) (*Adjustment, error) {
+	return c.decideAdjustment(ctx, adjustmentID, "reject")
+}
+
+func (c *Client) decideAdjustment(ctx context.Context, adjustmentID string, decision string) (*Adjustment, error) {
+	adjustment := &Adjustment{}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/api/admin/adjustments/" + url.PathEscape(adjustmentID) + "/" + decision}, adjustment)
+	if err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// AdminReverseWithdrawal возвращает на баланс всё списание или его часть
+func (c *Client) AdminReverseWithdrawal(ctx context.Context, withdrawalID string, reversal ReversalRequest) (*Reversal, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/admin/withdrawals/"+url.PathEscape(withdrawalID)+"/reverse", reversal)
+	if err != nil {
+		return nil, err
+	}
+	created := &Reversal{}
+	_, err = c.do(ctx, req, created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (c *Client) AdminRepollOrder(ctx context.Context, number string) error {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/api/admin/orders/" + url.PathEscape(number) + "/repoll"}, nil)
+	return err
+}
+
+func (c *Client) AdminCreateCampaign(ctx context.Context, campaign CampaignRequest) (*Campaign, error) {
+	return c.saveCampaign(ctx, http.MethodPost, "/api/admin/campaigns", campaign)
+}
+
+// AdminUpdateCampaign меняет правило кампании; уже начисленные бонусы не пересчитываются
+func (c *Client) AdminUpdateCampaign(ctx context.Context, campaignID string, campaign CampaignRequest) (*Campaign, error) {
+	return c.saveCampaign(ctx, http.MethodPut, "/api/admin/campaigns/"+url.PathEscape(campaignID), campaign)
+}
+
+func (c *Client) saveCampaign(ctx context.Context, method string, path string, campaign CampaignRequest) (*Campaign, error) {
+	req, err := jsonRequest(method, path, campaign)
+	if err != nil {
+		return nil, err
+	}
+	saved := &Campaign{}
+	_, err = c.do(ctx, req, saved)
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (c *Client) AdminListCampaigns(ctx context.Context) ([]Campaign, error) {
+	var campaigns []Campaign
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/campaigns"}, &campaigns)
+	return campaigns, err
+}
+
+func (c *Client) AdminGetCampaign(ctx context.Context, campaignID string) (*Campaign, error) {
+	campaign := &Campaign{}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/campaigns/" + url.PathEscape(campaignID)}, campaign)
+	if err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+func (c *Client) AdminDeleteCampaign(ctx context.Context, campaignID string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/api/admin/campaigns/" + url.PathEscape(campaignID)}, nil)
+	return err
+}
+
+func (c *Client) AdminListAudit(ctx context.Context, query AuditQuery) ([]AuditEvent, error) {
+	values := url.Values{}
+	if query.UserID != "" {
+		values.Set("user_id", query.UserID)
+	}
+	if query.Type != "" {
+		values.Set("type", query.Type)
+	}
+	if !query.From.IsZero() {
+		values.Set("from", query.From.Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		values.Set("to", query.To.Format(time.RFC3339))
+	}
+	setPage(values, query.Limit, query.Offset)
+
+	var events []AuditEvent
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/audit", query: values}, &events)
+	return events, err
+}
+
+func (c *Client) AdminVerifyAudit(ctx context.Context) (*AuditVerification, error) {
+	verification := &AuditVerification{}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/admin/audit/verify"}, verification)
+	if err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
+
+func setPage(values url.Values, limit, offset int) {
+	if limit > 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		values.Set("offset", strconv.Itoa(offset))
+	}
+}
@@ -0,0 +1,191 @@
+// Package client — типизированный клиент HTTP API gophermart, написанный по api/openapi.json.
+// Сессия хранится в cookie jar клиента: после Register или Login остальные вызовы авторизованы.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	requestIDHeader      = "X-Request-ID"
+	problemContentType   = "application/problem+json"
+)
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+type Option func(*Client)
+
+// WithHTTPClient подменяет HTTP-клиент. Если у него нет cookie jar, сессия между вызовами не сохранится.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func New(baseURL string, opts ...Option) (*Client, error) {
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, err
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Jar: jar},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+type contextKey int
+
+const (
+	idempotencyKey contextKey = iota
+	requestIDKey
+)
+
+// WithIdempotencyKey — изменяющий вызов с этим контекстом уйдёт с заголовком Idempotency-Key
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey, key)
+}
+
+// WithRequestID — вызов с этим контекстом уйдёт с заголовком X-Request-ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// Error — ответ сервера с ошибкой в формате problem+json
+type Error struct {
+	Type       string       `json:"type"`
+	Title      string       `json:"title"`
+	Status     int          `json:"status"`
+	Detail     string       `json:"detail"`
+	Instance   string       `json:"instance"`
+	Code       string       `json:"code"`
+	RequestID  string       `json:"request_id"`
+	Errors     []FieldError `json:"errors"`
+	StatusCode int          `json:"-"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("gophermart: %d %s", e.StatusCode, e.Detail)
+	}
+	return fmt.Sprintf("gophermart: %d %s: %s", e.StatusCode, e.Code, e.Detail)
+}
+
+// IsCode проверяет, что err — ошибка сервера с указанным кодом
+func IsCode(err error, code string) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        io.Reader
+	contentType string
+	// accept — код ошибки, тело которого всё равно разбирается как обычный ответ
+	accept int
+}
+
+func jsonRequest(method, path string, payload any) (request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return request{}, err
+	}
+	return request{method: method, path: path, body: bytes.NewReader(body), contentType: "application/json"}, nil
+}
+
+// do выполняет запрос и, если ответ успешный и out не nil, разбирает в него JSON. Возвращает код ответа.
+func (c *Client) do(ctx context.Context, req request, out any) (int, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != req.accept {
+		return resp.StatusCode, decodeError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return resp.StatusCode, nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("gophermart: decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// download выполняет запрос и отдаёт тело успешного ответа как есть; закрыть его должен вызывающий
+func (c *Client) download(ctx context.Context, req request) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, req.body)
+	if err != nil {
+		return nil, err
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if key, ok := ctx.Value(idempotencyKey).(string); ok && key != "" {
+		httpReq.Header.Set(idempotencyKeyHeader, key)
+	}
+	if requestID, ok := ctx.Value(requestIDKey).(string); ok && requestID != "" {
+		httpReq.Header.Set(requestIDHeader, requestID)
+	}
+	return c.httpClient.Do(httpReq)
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return apiErr
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == problemContentType && json.Unmarshal(body, apiErr) == nil {
+		apiErr.StatusCode = resp.StatusCode
+		return apiErr
+	}
+	apiErr.Detail = strings.TrimSpace(string(body))
+	return apiErr
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/user/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session_token", Value: "token", Path: "/"})
+	})
+	mux.HandleFunc("/api/user/balance", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session_token"); err != nil || c.Value != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"current":500.5,"withdrawn":42}`))
+	})
+	mux.HandleFunc("/api/user/orders", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Idempotency-Key") != "k1" || string(body) != "12345678903" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/api/user/balance/withdraw", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusPaymentRequired)
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": 402, "code": CodeInsufficientFunds, "detail": "Not enough money"})
+	})
+	mux.HandleFunc("/api/user/orders/export", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") != ExportCSV {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("number,status,accrual,uploaded_at\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err = c.Login(ctx, "gopher", "secret", ""); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	balance, err := c.GetBalance(ctx)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance.Current != 500.5 || balance.Withdrawn != 42 {
+		t.Errorf("GetBalance() = %+v", balance)
+	}
+
+	accepted, err := c.UploadOrder(WithIdempotencyKey(ctx, "k1"), "12345678903")
+	if err != nil || !accepted {
+		t.Errorf("UploadOrder() = %v, %v", accepted, err)
+	}
+
+	orders, err := c.ListOrders(ctx)
+	if err != nil || orders != nil {
+		t.Errorf("ListOrders() = %v, %v, want no orders", orders, err)
+	}
+
+	err = c.Withdraw(ctx, WithdrawalRequest{Order: "2377225624", Sum: 1000})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPaymentRequired || !IsCode(err, CodeInsufficientFunds) {
+		t.Errorf("Withdraw() error = %v, want %s", err, CodeInsufficientFunds)
+	}
+
+	export, err := c.ExportOrders(ctx, ExportCSV)
+	if err != nil {
+		t.Fatalf("ExportOrders() error = %v", err)
+	}
+	body, _ := io.ReadAll(export)
+	_ = export.Close()
+	if string(body) != "number,status,accrual,uploaded_at\n" {
+		t.Errorf("ExportOrders() body = %q", body)
+	}
+	if _, err = c.ExportOrders(ctx, ExportNDJSON); err == nil {
+		t.Errorf("ExportOrders() error = nil, want 400")
+	}
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+func (c *Client) Liveness(ctx context.Context) (*Health, error) {
+	health := &Health{}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/healthz"}, health)
+	if err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// Readiness возвращает отчёт и при 503: в нём видно, какая проверка не прошла
+func (c *Client) Readiness(ctx context.Context) (*Health, error) {
+	health := &Health{}
+	status, err := c.do(ctx, request{method: http.MethodGet, path: "/readyz", accept: http.StatusServiceUnavailable}, health)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusServiceUnavailable {
+		return health, &Error{StatusCode: status, Status: status, Detail: "service is not ready"}
+	}
+	return health, nil
+}
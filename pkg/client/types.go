@@ -0,0 +1,357 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Коды ошибок сервера, см. Error.Code
+const (
+	CodeInternal             = "internal_error"
+	CodeInvalidContentType   = "invalid_content_type"
+	CodeInvalidJSON          = "invalid_json"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeConflict             = "conflict"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeLoginTaken           = "login_taken"
+	CodeSecondFactorRequired = "second_factor_required"
+	CodeInvalidSecondFactor  = "invalid_second_factor"
+	CodeTOTPAlreadyEnabled   = "totp_already_enabled"
+	CodeTOTPNotEnrolled      = "totp_not_enrolled"
+	CodeTOTPNotEnabled       = "totp_not_enabled"
+	CodeInvalidOrderNumber   = "invalid_order_number"
+	CodeOrderAnotherUser     = "order_owned_by_another_user"
+	CodeOrderProcessed       = "order_already_processed"
+	CodeInsufficientFunds    = "insufficient_funds"
+	CodeSameApprover         = "same_approver"
+	CodeNotPending           = "adjustment_not_pending"
+	CodeIdempotencyReused    = "idempotency_key_reused"
+	CodeIdempotencyInFlight  = "idempotency_key_in_progress"
+)
+
+type Order struct {
+	Number     string    `json:"number"`
+	Status     string    `json:"status"`
+	Accrual    float64   `json:"accrual,omitempty"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+type Balance struct {
+	Current   float64   `json:"current"`
+	Held      float64   `json:"held"`
+	Available float64   `json:"available"`
+	Withdrawn float64   `json:"withdrawn"`
+	Expiring  *Expiring `json:"expiring,omitempty"`
+	Tier      *Tier     `json:"tier,omitempty"`
+}
+
+// Tier — уровень лояльности и прогресс до следующего
+type Tier struct {
+	Name       string    `json:"name"`
+	Multiplier float64   `json:"multiplier"`
+	Spend      float64   `json:"spend"`
+	Next       *NextTier `json:"next,omitempty"`
+}
+
+type NextTier struct {
+	Name      string  `json:"name"`
+	Threshold float64 `json:"threshold"`
+	Remaining float64 `json:"remaining"`
+}
+
+type TierChange struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Spend     float64   `json:"spend"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// Referrals — код приглашения пользователя и приглашённые им
+type Referrals struct {
+	Code      string     `json:"code"`
+	Invited   int        `json:"invited"`
+	Earned    float64    `json:"earned"`
+	Referrals []Referral `json:"referrals"`
+}
+
+type Referral struct {
+	Login      string     `json:"login"`
+	Status     string     `json:"status"`
+	Reason     string     `json:"reason,omitempty"`
+	Reward     float64    `json:"reward"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RewardedAt *time.Time `json:"rewarded_at,omitempty"`
+}
+
+// Expiring — ближайшее сгорание баллов
+type Expiring struct {
+	Sum       float64   `json:"sum"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type WithdrawalRequest struct {
+	Order string  `json:"order"`
+	Sum   float64 `json:"sum"`
+	Code  string  `json:"code,omitempty"`
+}
+
+type Withdrawal struct {
+	ID          string    `json:"id"`
+	Order       string    `json:"order"`
+	Sum         float64   `json:"sum"`
+	Reversed    float64   `json:"reversed"`
+	Status      string    `json:"status"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+type HoldRequest struct {
+	Order string  `json:"order"`
+	Sum   float64 `json:"sum"`
+	Code  string  `json:"code,omitempty"`
+}
+
+// Hold — блокировка баллов под заказ: HELD, CAPTURED, VOIDED или EXPIRED
+type Hold struct {
+	ID         string     `json:"id"`
+	Order      string     `json:"order"`
+	Sum        float64    `json:"sum"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type TransferRequest struct {
+	To   string  `json:"to"`
+	Sum  float64 `json:"sum"`
+	Code string  `json:"code,omitempty"`
+}
+
+// Transfer — перевод баллов; Direction "in" или "out", Login — вторая сторона
+type Transfer struct {
+	ID        string    `json:"id"`
+	Direction string    `json:"direction"`
+	Login     string    `json:"login"`
+	Sum       float64   `json:"sum"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type UserAdjustment struct {
+	Type        string    `json:"type"`
+	Amount      float64   `json:"amount"`
+	Reason      string    `json:"reason"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+type AdminUser struct {
+	ID          string `json:"id"`
+	Login       string `json:"login"`
+	Role        string `json:"role"`
+	TOTPEnabled bool   `json:"totp_enabled"`
+}
+
+type UserQuery struct {
+	Q      string
+	Limit  int
+	Offset int
+}
+
+type CreateAdjustmentRequest struct {
+	Type   string  `json:"type"`
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+	Note   string  `json:"note,omitempty"`
+}
+
+type Adjustment struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Type      string     `json:"type"`
+	Amount    float64    `json:"amount"`
+	Reason    string     `json:"reason"`
+	Note      string     `json:"note"`
+	Status    string     `json:"status"`
+	CreatedBy string     `json:"created_by"`
+	DecidedBy string     `json:"decided_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// ReversalRequest — при Sum == 0 возвращается весь ещё не возвращённый остаток списания
+type ReversalRequest struct {
+	Sum    float64 `json:"sum,omitempty"`
+	Reason string  `json:"reason"`
+}
+
+type Reversal struct {
+	ID           string    `json:"id"`
+	WithdrawalID string    `json:"withdrawal_id"`
+	UserID       string    `json:"user_id"`
+	Sum          float64   `json:"sum"`
+	Reason       string    `json:"reason"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Форматы выгрузки истории
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson"
+)
+
+type StatementQuery struct {
+	Types  []string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+type StatementEntry struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Reference string    `json:"reference,omitempty"`
+	Amount    float64   `json:"amount"`
+	Balance   float64   `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MonthlyStatement struct {
+	Month    string           `json:"month"`
+	Opening  float64          `json:"opening_balance"`
+	Closing  float64          `json:"closing_balance"`
+	Credited float64          `json:"credited"`
+	Debited  float64          `json:"debited"`
+	Entries  []StatementEntry `json:"entries"`
+}
+
+type AccountDeletion struct {
+	RequestedAt time.Time `json:"requested_at"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+type Profile struct {
+	ID           string           `json:"id"`
+	Login        string           `json:"login"`
+	Role         string           `json:"role"`
+	TOTPEnabled  bool             `json:"totp_enabled"`
+	ReferralCode string           `json:"referral_code"`
+	Deletion     *AccountDeletion `json:"deletion,omitempty"`
+}
+
+type DataExport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Profile     Profile          `json:"profile"`
+	Orders      []Order          `json:"orders"`
+	Withdrawals []Withdrawal     `json:"withdrawals"`
+	Statement   []StatementEntry `json:"statement"`
+}
+
+type AuditEvent struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	ActorID   string            `json:"actor_id,omitempty"`
+	UserID    string            `json:"user_id,omitempty"`
+	Payload   map[string]string `json:"payload"`
+	CreatedAt time.Time         `json:"created_at"`
+	PrevHash  string            `json:"prev_hash"`
+	Hash      string            `json:"hash"`
+}
+
+type AuditQuery struct {
+	UserID string
+	Type   string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+type AuditVerification struct {
+	Checked  int    `json:"checked"`
+	Valid    bool   `json:"valid"`
+	BrokenAt string `json:"broken_at,omitempty"`
+}
+
+type HealthCheck struct {
+	Status  string         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type Health struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks"`
+}
+
+// Типы событий вебхуков
+const (
+	WebhookOrderFinalized      = "order.finalized"
+	WebhookWithdrawalSucceeded = "withdrawal.succeeded"
+)
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+type Webhook struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret приходит только в ответе на создание
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+// Виды правил кампании
+const (
+	CampaignMultiplier = "MULTIPLIER"
+	CampaignFixed      = "FIXED"
+	CampaignFirstOrder = "FIRST_ORDER"
+)
+
+type CampaignRequest struct {
+	Name     string    `json:"name"`
+	Kind     string    `json:"kind"`
+	Value    float64   `json:"value"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type Campaign struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Value     float64   `json:"value"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Spent     float64   `json:"spent"`
+	Bonuses   int       `json:"bonuses"`
+}
@@ -0,0 +1,258 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type credentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	Code     string `json:"code,omitempty"`
+	Referral string `json:"referral_code,omitempty"`
+}
+
+// Register регистрирует пользователя и сразу открывает сессию
+func (c *Client) Register(ctx context.Context, login, password string) error {
+	return c.RegisterWithReferral(ctx, login, password, "")
+}
+
+// RegisterWithReferral регистрирует пользователя по коду приглашения
+func (c *Client) RegisterWithReferral(ctx context.Context, login, password, referralCode string) error {
+	req, err := jsonRequest(http.MethodPost, "/api/user/register", credentials{Login: login, Password: password, Referral: referralCode})
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, req, nil)
+	return err
+}
+
+// Login открывает сессию. code нужен, только если у пользователя включена двухфакторная аутентификация.
+func (c *Client) Login(ctx context.Context, login, password, code string) error {
+	req, err := jsonRequest(http.MethodPost, "/api/user/login", credentials{Login: login, Password: password, Code: code})
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, req, nil)
+	return err
+}
+
+// UploadOrder загружает номер заказа. accepted=false, если этот пользователь уже загружал такой заказ.
+func (c *Client) UploadOrder(ctx context.Context, number string) (accepted bool, err error) {
+	status, err := c.do(ctx, request{
+		method:      http.MethodPost,
+		path:        "/api/user/orders",
+		body:        strings.NewReader(number),
+		contentType: "text/plain",
+	}, nil)
+	return status == http.StatusAccepted, err
+}
+
+func (c *Client) ListOrders(ctx context.Context) ([]Order, error) {
+	var orders []Order
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/orders"}, &orders)
+	return orders, err
+}
+
+func (c *Client) GetBalance(ctx context.Context) (*Balance, error) {
+	balance := &Balance{}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/balance"}, balance)
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+func (c *Client) Withdraw(ctx context.Context, withdrawal WithdrawalRequest) error {
+	req, err := jsonRequest(http.MethodPost, "/api/user/balance/withdraw", withdrawal)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, req, nil)
+	return err
+}
+
+// CreateHold откладывает баллы под заказ до CaptureHold или VoidHold
+func (c *Client) CreateHold(ctx context.Context, hold HoldRequest) (*Hold, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/user/balance/holds", hold)
+	if err != nil {
+		return nil, err
+	}
+	result := &Hold{}
+	_, err = c.do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *Client) CaptureHold(ctx context.Context, holdID string) (*Hold, error) {
+	return c.resolveHold(ctx, holdID, "capture")
+}
+
+func (c *Client) VoidHold(ctx context.Context, holdID string) (*Hold, error) {
+	return c.resolveHold(ctx, holdID, "void")
+}
+
+func (c *Client) resolveHold(ctx context.Context, holdID string, action string) (*Hold, error) {
+	hold := &Hold{}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/api/user/balance/holds/" + url.PathEscape(holdID) + "/" + action}, hold)
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// Transfer переводит баллы пользователю с логином transfer.To
+func (c *Client) Transfer(ctx context.Context, transfer TransferRequest) (*Transfer, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/user/balance/transfer", transfer)
+	if err != nil {
+		return nil, err
+	}
+	result := &Transfer{}
+	_, err = c.do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *Client) ListTransfers(ctx context.Context) ([]Transfer, error) {
+	var transfers []Transfer
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/balance/transfers"}, &transfers)
+	return transfers, err
+}
+
+func (c *Client) ListWithdrawals(ctx context.Context) ([]Withdrawal, error) {
+	var withdrawals []Withdrawal
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/withdrawals"}, &withdrawals)
+	return withdrawals, err
+}
+
+// GetStatement возвращает записи выписки с нарастающим остатком, от старых к новым
+func (c *Client) GetStatement(ctx context.Context, query StatementQuery) ([]StatementEntry, error) {
+	values := url.Values{}
+	for _, entryType := range query.Types {
+		values.Add("type", entryType)
+	}
+	if !query.From.IsZero() {
+		values.Set("from", query.From.Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		values.Set("to", query.To.Format(time.RFC3339))
+	}
+	setPage(values, query.Limit, query.Offset)
+
+	var entries []StatementEntry
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/statement", query: values}, &entries)
+	return entries, err
+}
+
+func (c *Client) GetMonthlyStatement(ctx context.Context, month time.Time) (*MonthlyStatement, error) {
+	statement := &MonthlyStatement{}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/statement/" + month.Format("2006-01")}, statement)
+	if err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+// ExportOrders скачивает историю заказов; format — ExportCSV или ExportNDJSON. Тело нужно закрыть.
+func (c *Client) ExportOrders(ctx context.Context, format string) (io.ReadCloser, error) {
+	return c.download(ctx, request{method: http.MethodGet, path: "/api/user/orders/export", query: url.Values{"format": {format}}})
+}
+
+// ExportWithdrawals скачивает историю списаний; format — ExportCSV или ExportNDJSON. Тело нужно закрыть.
+func (c *Client) ExportWithdrawals(ctx context.Context, format string) (io.ReadCloser, error) {
+	return c.download(ctx, request{method: http.MethodGet, path: "/api/user/withdrawals/export", query: url.Values{"format": {format}}})
+}
+
+// DeleteAccount назначает удаление аккаунта; до ScheduledAt его можно отменить через CancelAccountDeletion
+func (c *Client) DeleteAccount(ctx context.Context) (*AccountDeletion, error) {
+	deletion := &AccountDeletion{}
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/api/user"}, deletion)
+	if err != nil {
+		return nil, err
+	}
+	return deletion, nil
+}
+
+func (c *Client) CancelAccountDeletion(ctx context.Context) error {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/api/user/deletion/cancel"}, nil)
+	return err
+}
+
+// ExportData возвращает все данные пользователя одним JSON
+func (c *Client) ExportData(ctx context.Context) (*DataExport, error) {
+	export := &DataExport{}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/export"}, export)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// ExportDataArchive скачивает те же данные ZIP-архивом с CSV-таблицами. Тело нужно закрыть.
+func (c *Client) ExportDataArchive(ctx context.Context) (io.ReadCloser, error) {
+	return c.download(ctx, request{method: http.MethodGet, path: "/api/user/export", query: url.Values{"format": {"zip"}}})
+}
+
+func (c *Client) ListAdjustments(ctx context.Context) ([]UserAdjustment, error) {
+	var adjustments []UserAdjustment
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/adjustments"}, &adjustments)
+	return adjustments, err
+}
+
+func (c *Client) GetTierHistory(ctx context.Context) ([]TierChange, error) {
+	var changes []TierChange
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/tier/history"}, &changes)
+	return changes, err
+}
+
+func (c *Client) GetReferrals(ctx context.Context) (*Referrals, error) {
+	referrals := &Referrals{}
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/referrals"}, referrals)
+	if err != nil {
+		return nil, err
+	}
+	return referrals, nil
+}
+
+func (c *Client) EnrollTOTP(ctx context.Context) (*TOTPEnrollment, error) {
+	enrollment := &TOTPEnrollment{}
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/api/user/2fa/enroll"}, enrollment)
+	if err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+type totpCode struct {
+	Code string `json:"code"`
+}
+
+// ConfirmTOTP включает двухфакторную аутентификацию и возвращает коды восстановления
+func (c *Client) ConfirmTOTP(ctx context.Context, code string) ([]string, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/user/2fa/verify", totpCode{Code: code})
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	_, err = c.do(ctx, req, &resp)
+	return resp.RecoveryCodes, err
+}
+
+func (c *Client) DisableTOTP(ctx context.Context, code string) error {
+	req, err := jsonRequest(http.MethodPost, "/api/user/2fa/disable", totpCode{Code: code})
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, req, nil)
+	return err
+}
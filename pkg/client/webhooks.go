@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// CreateWebhook регистрирует вебхук. Секрет для проверки подписи (см. pkg/webhook) приходит только здесь.
+func (c *Client) CreateWebhook(ctx context.Context, webhook CreateWebhookRequest) (*Webhook, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/user/webhooks", webhook)
+	if err != nil {
+		return nil, err
+	}
+	created := &Webhook{}
+	_, err = c.do(ctx, req, created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/user/webhooks"}, &webhooks)
+	return webhooks, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/api/user/webhooks/" + url.PathEscape(webhookID)}, nil)
+	return err
+}
+
+func (c *Client) ListWebhookDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]WebhookDelivery, error) {
+	values := url.Values{}
+	setPage(values, limit, offset)
+
+	var deliveries []WebhookDelivery
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/user/webhooks/" + url.PathEscape(webhookID) + "/deliveries",
+		query:  values,
+	}, &deliveries)
+	return deliveries, err
+}
+
+func (c *Client) RedeliverWebhook(ctx context.Context, webhookID, deliveryID string) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/user/webhooks/" + url.PathEscape(webhookID) + "/deliveries/" + url.PathEscape(deliveryID) + "/redeliver",
+	}, nil)
+	return err
+}
@@ -0,0 +1,86 @@
+// Package webhook — подпись исходящих вебхуков gophermart и её проверка на стороне получателя.
+//
+// Заголовок подписи: "X-Gophermart-Signature: t=<unix>,v1=<hex>", где hex — HMAC-SHA256 по секрету
+// вебхука от строки "<unix>.<тело запроса>". Время входит в подпись, чтобы перехваченный запрос
+// нельзя было переиграть позже допустимого окна.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Gophermart-Signature"
+	EventHeader     = "X-Gophermart-Event"
+	DeliveryHeader  = "X-Gophermart-Delivery"
+
+	// DefaultTolerance — насколько подпись может отставать от часов получателя
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrInvalidHeader    = errors.New("invalid signature header")
+	ErrSignatureExpired = errors.New("signature timestamp outside tolerance")
+	ErrSignatureInvalid = errors.New("signature mismatch")
+)
+
+// Sign возвращает значение заголовка подписи для тела body
+func Sign(secret string, body []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + compute(secret, timestamp, body)
+}
+
+// Verify проверяет заголовок подписи. tolerance <= 0 отключает проверку времени.
+func Verify(secret string, body []byte, header string, tolerance time.Duration, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidHeader
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidHeader
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidHeader
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	expected := compute(secret, timestamp, body)
+	// несколько v1 бывает при ротации секрета — достаточно совпадения одной
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrSignatureInvalid
+}
+
+func compute(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"1","type":"order.finalized"}`)
+	at := time.Unix(1700000000, 0)
+	header := Sign("secret", body, at)
+
+	assert.NoError(t, Verify("secret", body, header, DefaultTolerance, at.Add(time.Minute)))
+	assert.ErrorIs(t, Verify("other", body, header, DefaultTolerance, at), ErrSignatureInvalid)
+	assert.ErrorIs(t, Verify("secret", []byte(`{}`), header, DefaultTolerance, at), ErrSignatureInvalid)
+	assert.ErrorIs(t, Verify("secret", body, header, DefaultTolerance, at.Add(time.Hour)), ErrSignatureExpired)
+	assert.NoError(t, Verify("secret", body, header, 0, at.Add(time.Hour)))
+	assert.ErrorIs(t, Verify("secret", body, "garbage", DefaultTolerance, at), ErrInvalidHeader)
+	assert.ErrorIs(t, Verify("secret", body, "t=abc,v1=00", DefaultTolerance, at), ErrInvalidHeader)
+}